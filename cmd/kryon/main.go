@@ -0,0 +1,121 @@
+// cmd/kryon/main.go
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/kryonlabs/kryon-go-runtime/render/snapshot"
+)
+
+func usage() {
+	execName := filepath.Base(os.Args[0])
+	fmt.Fprintf(os.Stderr, "Usage: %s <command> [flags]\n\n", execName)
+	fmt.Fprintf(os.Stderr, "Commands:\n")
+	fmt.Fprintf(os.Stderr, "  snapshot   Render a KRB file offscreen to PNG, optionally comparing it with a golden image\n")
+	fmt.Fprintf(os.Stderr, "\nRun '%s <command> -h' for command flags.\n", execName)
+}
+
+func main() {
+	log.SetFlags(0)
+
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	switch os.Args[1] {
+	case "snapshot":
+		os.Exit(runSnapshot(os.Args[2:]))
+	case "-h", "-help", "--help", "help":
+		usage()
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown command '%s'.\n\n", os.Args[1])
+		usage()
+		os.Exit(2)
+	}
+}
+
+// runSnapshot implements 'kryon snapshot'. Exit codes: 0 success, 1 golden
+// mismatch, 2 usage or rendering error.
+func runSnapshot(args []string) int {
+	fs := flag.NewFlagSet("snapshot", flag.ContinueOnError)
+	krbFilePath := fs.String("file", "", "Path to the KRB file to render")
+	outPath := fs.String("out", "", "Write the rendered frame to this PNG file")
+	goldenPath := fs.String("golden", "", "Compare the frame with this PNG; writes <golden>.actual.png and <golden>.diff.png on mismatch")
+	width := fs.Int("width", 0, "Canvas width in pixels (default: window width from the KRB file)")
+	height := fs.Int("height", 0, "Canvas height in pixels (default: window height from the KRB file)")
+	scale := fs.Float64("scale", 0, "UI scale factor (default: scale factor from the KRB file)")
+	tolerance := fs.Uint("tolerance", 0, "Largest per-channel difference (0-255) at which pixels still match")
+	maxDiffPixels := fs.Int("max-diff-pixels", 0, "Number of differing pixels allowed before the comparison fails")
+	update := fs.Bool("update", false, "Overwrite the golden file with the rendered frame instead of comparing")
+	verbose := fs.Bool("v", false, "Show renderer log output")
+
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *krbFilePath == "" || (*outPath == "" && *goldenPath == "") {
+		fmt.Fprintln(os.Stderr, "Usage: kryon snapshot -file <krb_file_path> [-out <png>] [-golden <png>] [flags]")
+		fs.PrintDefaults()
+		return 2
+	}
+	if *tolerance > 255 {
+		fmt.Fprintln(os.Stderr, "ERROR: -tolerance must be between 0 and 255")
+		return 2
+	}
+
+	if !*verbose {
+		// The tree builder and renderer log every element; keep the command quiet.
+		log.SetOutput(io.Discard)
+	}
+
+	opts := snapshot.Options{
+		Width:         *width,
+		Height:        *height,
+		ScaleFactor:   float32(*scale),
+		Tolerance:     uint8(*tolerance),
+		MaxDiffPixels: *maxDiffPixels,
+	}
+
+	frame, err := snapshot.Render(*krbFilePath, opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: %v\n", err)
+		return 2
+	}
+
+	if *outPath != "" {
+		if err := snapshot.SavePNG(*outPath, frame); err != nil {
+			fmt.Fprintf(os.Stderr, "ERROR: %v\n", err)
+			return 2
+		}
+		fmt.Printf("Wrote %s (%dx%d)\n", *outPath, frame.Bounds().Dx(), frame.Bounds().Dy())
+	}
+
+	if *goldenPath != "" {
+		if *update {
+			if err := snapshot.SavePNG(*goldenPath, frame); err != nil {
+				fmt.Fprintf(os.Stderr, "ERROR: %v\n", err)
+				return 2
+			}
+			fmt.Printf("Updated %s\n", *goldenPath)
+			return 0
+		}
+		err := snapshot.MatchImage(frame, *goldenPath, opts)
+		var mismatch *snapshot.MismatchError
+		switch {
+		case errors.As(err, &mismatch):
+			fmt.Fprintf(os.Stderr, "FAIL: %v\n", err)
+			return 1
+		case err != nil:
+			fmt.Fprintf(os.Stderr, "ERROR: %v\n", err)
+			return 2
+		}
+		fmt.Printf("OK: %s matches %s\n", *krbFilePath, *goldenPath)
+	}
+	return 0
+}
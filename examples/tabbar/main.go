@@ -6,6 +6,7 @@ import (
 	"bytes"
 	_ "embed"
 	"log"
+	"strings"
 
 	"github.com/kryonlabs/kryon-go-runtime/krb"
 	"github.com/kryonlabs/kryon-go-runtime/render"
@@ -90,22 +91,13 @@ func updateTabStyles(activeTabID string) {
 	}
 }
 
-func showHomePage() {
-	log.Println("ACTION: Show Home Page")
-	updatePageVisibility("page_home")
-	updateTabStyles("tab_home")
-}
-
-func showSearchPage() {
-	log.Println("ACTION: Show Search Page")
-	updatePageVisibility("page_search")
-	updateTabStyles("tab_search")
-}
-
-func showProfilePage() {
-	log.Println("ACTION: Show Profile Page")
-	updatePageVisibility("page_profile")
-	updateTabStyles("tab_profile")
+// showPage is bound to every tab's click event. The tab that was clicked,
+// e.g. "tab_search", selects the page to show, "page_search".
+func showPage(ev *render.Event) {
+	tabID := render.ElementID(ev.Target)
+	log.Printf("ACTION: Show page for tab '%s'", tabID)
+	updatePageVisibility("page_" + strings.TrimPrefix(tabID, "tab_"))
+	updateTabStyles(tabID)
 }
 
 func main() {
@@ -140,9 +132,10 @@ func main() {
 		log.Fatalf("Error registering TabBarHandler: %v", err)
 	}
 
-	rendererImpl.RegisterEventHandler("showHomePage", showHomePage)
-	rendererImpl.RegisterEventHandler("showSearchPage", showSearchPage)
-	rendererImpl.RegisterEventHandler("showProfilePage", showProfilePage)
+	// The KRB binds each tab to its own callback name; one handler serves them all.
+	for _, handlerName := range []string{"showHomePage", "showSearchPage", "showProfilePage"} {
+		rendererImpl.RegisterEventFunc(handlerName, showPage)
+	}
 
 	var windowConfig render.WindowConfig
 	roots, windowConfig, err = rendererImpl.PrepareTree(doc, ".")
@@ -154,7 +147,8 @@ func main() {
 	}
 	allElements = rendererImpl.GetRenderTree() // This returns []*render.RenderElement
 
-	showHomePage()
+	updatePageVisibility("page_home")
+	updateTabStyles("tab_home")
 
 	err = rendererImpl.Init(windowConfig)
 	if err != nil {
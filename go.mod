@@ -2,10 +2,14 @@ module github.com/kryonlabs/kryon-go-runtime
 
 go 1.23.5
 
-require github.com/gen2brain/raylib-go/raylib v0.0.0-20250409052854-a4292f0f0412
+require (
+	github.com/gen2brain/raylib-go/raylib v0.0.0-20250409052854-a4292f0f0412
+	golang.org/x/image v0.30.0
+)
 
 require (
 	github.com/ebitengine/purego v0.8.2 // indirect
 	golang.org/x/exp v0.0.0-20250408133849-7e4ce0ab07d0 // indirect
 	golang.org/x/sys v0.32.0 // indirect
+	golang.org/x/text v0.28.0 // indirect
 )
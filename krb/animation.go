@@ -0,0 +1,282 @@
+// krb/animation.go
+
+package krb
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"sort"
+)
+
+// Animation table entry layout (KRB v0.4):
+//
+//	ID (1) | Type (1) | Duration ms (2) | Easing (1) | type-specific data
+//
+// Transition data: PropertyID (1) | ValueType (1) | Size (1) | From (Size) | To (Size)
+// Keyframe data:   KeyframeCount (1), then per keyframe:
+//
+//	Time (1) | PropertyCount (1) | properties (PropertyID, ValueType, Size, Value)
+const animationHeaderSize = 5
+
+// animationCursor reads little-endian values from an animation table while
+// tracking the section-relative offset for error messages.
+type animationCursor struct {
+	data []byte
+	pos  int
+}
+
+func (c *animationCursor) take(n int, what string) ([]byte, error) {
+	if n < 0 || c.pos+n > len(c.data) {
+		return nil, fmt.Errorf("%s at section offset %d needs %d bytes, only %d left", what, c.pos, n, len(c.data)-c.pos)
+	}
+	b := c.data[c.pos : c.pos+n]
+	c.pos += n
+	return b, nil
+}
+
+func (c *animationCursor) byte(what string) (uint8, error) {
+	b, err := c.take(1, what)
+	if err != nil {
+		return 0, err
+	}
+	return b[0], nil
+}
+
+func (c *animationCursor) property(what string) (Property, error) {
+	hdr, err := c.take(3, what+" header")
+	if err != nil {
+		return Property{}, err
+	}
+	prop := Property{ID: PropertyID(hdr[0]), ValueType: ValueType(hdr[1]), Size: hdr[2]}
+	value, err := c.take(int(prop.Size), what+" value")
+	if err != nil {
+		return Property{}, err
+	}
+	prop.Value = append([]byte(nil), value...)
+	return prop, nil
+}
+
+// parseAnimationTable decodes exactly count animations from data. Trailing bytes
+// after the last animation are returned as a count so the caller can warn.
+// Decoding stops early at an animation of unknown type; see Animation.Raw.
+func parseAnimationTable(data []byte, count uint16) ([]Animation, int, error) {
+	c := &animationCursor{data: data}
+	animations := make([]Animation, 0, count)
+	for i := 0; i < int(count); i++ {
+		hdr, err := c.take(animationHeaderSize, fmt.Sprintf("animation %d header", i))
+		if err != nil {
+			return nil, 0, fmt.Errorf("krb read: animation table has fewer entries than AnimationCount %d: %w", count, err)
+		}
+		anim := Animation{
+			ID:         hdr[0],
+			Type:       AnimationType(hdr[1]),
+			DurationMs: ReadU16LE(hdr[2:4]),
+			Easing:     EasingType(hdr[4]),
+		}
+
+		switch anim.Type {
+		case AnimTypeTransition:
+			thdr, err := c.take(3, fmt.Sprintf("animation %d transition header", i))
+			if err != nil {
+				return nil, 0, fmt.Errorf("krb read: %w", err)
+			}
+			anim.Transition = AnimationTransition{PropertyID: PropertyID(thdr[0]), ValueType: ValueType(thdr[1]), Size: thdr[2]}
+			from, err := c.take(int(anim.Transition.Size), fmt.Sprintf("animation %d transition 'from' value", i))
+			if err != nil {
+				return nil, 0, fmt.Errorf("krb read: %w", err)
+			}
+			to, err := c.take(int(anim.Transition.Size), fmt.Sprintf("animation %d transition 'to' value", i))
+			if err != nil {
+				return nil, 0, fmt.Errorf("krb read: %w", err)
+			}
+			anim.Transition.From = append([]byte(nil), from...)
+			anim.Transition.To = append([]byte(nil), to...)
+
+		case AnimTypeKeyframe:
+			if anim.KeyframeCount, err = c.byte(fmt.Sprintf("animation %d keyframe count", i)); err != nil {
+				return nil, 0, fmt.Errorf("krb read: %w", err)
+			}
+			anim.Keyframes = make([]Keyframe, anim.KeyframeCount)
+			for k := range anim.Keyframes {
+				khdr, err := c.take(2, fmt.Sprintf("animation %d keyframe %d header", i, k))
+				if err != nil {
+					return nil, 0, fmt.Errorf("krb read: %w", err)
+				}
+				kf := Keyframe{Time: khdr[0], PropertyCount: khdr[1]}
+				kf.Properties = make([]Property, kf.PropertyCount)
+				for p := range kf.Properties {
+					if kf.Properties[p], err = c.property(fmt.Sprintf("animation %d keyframe %d property %d", i, k, p)); err != nil {
+						return nil, 0, fmt.Errorf("krb read: %w", err)
+					}
+				}
+				anim.Keyframes[k] = kf
+			}
+
+		default:
+			// Without a known layout neither this entry nor the ones after it
+			// can be delimited, so the rest of the table is kept undecoded.
+			anim.Raw = append([]byte(nil), c.data[c.pos:]...)
+			log.Printf("Warning: KRB animation %d has unknown type 0x%02X; keeping the remaining %d bytes of the animation table undecoded.",
+				i, uint8(anim.Type), len(anim.Raw))
+			return append(animations, anim), 0, nil
+		}
+		animations = append(animations, anim)
+	}
+	return animations, len(data) - c.pos, nil
+}
+
+// animationEntryCount returns how many entries the animation table holds. This
+// is len(doc.Animations) unless decoding stopped at an animation of unknown
+// type, whose Raw bytes also carry the entries the header counts after it.
+func animationEntryCount(doc *Document) int {
+	n := len(doc.Animations)
+	if n > 0 && doc.Animations[n-1].Raw != nil && int(doc.Header.AnimationCount) > n {
+		return int(doc.Header.AnimationCount)
+	}
+	return n
+}
+
+// animationSize returns the encoded size of anim in bytes.
+func animationSize(anim *Animation) uint32 {
+	size := uint32(animationHeaderSize)
+	switch anim.Type {
+	case AnimTypeTransition:
+		size += 3 + uint32(len(anim.Transition.From)) + uint32(len(anim.Transition.To))
+	case AnimTypeKeyframe:
+		size++
+		for _, kf := range anim.Keyframes {
+			size += 2
+			for _, prop := range kf.Properties {
+				size += 3 + uint32(len(prop.Value))
+			}
+		}
+	default:
+		size += uint32(len(anim.Raw))
+	}
+	return size
+}
+
+// syncAnimation makes the count and size fields of anim agree with its slices.
+func syncAnimation(anim *Animation) error {
+	switch anim.Type {
+	case AnimTypeTransition:
+		tr := &anim.Transition
+		if len(tr.From) != len(tr.To) {
+			return fmt.Errorf("transition 'from' (%d bytes) and 'to' (%d bytes) differ in size", len(tr.From), len(tr.To))
+		}
+		if err := syncValueSize(&tr.Size, tr.From); err != nil {
+			return err
+		}
+	case AnimTypeKeyframe:
+		if len(anim.Keyframes) > 0xFF {
+			return fmt.Errorf("too many keyframes (%d)", len(anim.Keyframes))
+		}
+		anim.KeyframeCount = uint8(len(anim.Keyframes))
+		for k := range anim.Keyframes {
+			kf := &anim.Keyframes[k]
+			if len(kf.Properties) > 0xFF {
+				return fmt.Errorf("keyframe %d has too many properties (%d)", k, len(kf.Properties))
+			}
+			kf.PropertyCount = uint8(len(kf.Properties))
+			for p := range kf.Properties {
+				if err := syncValueSize(&kf.Properties[p].Size, kf.Properties[p].Value); err != nil {
+					return fmt.Errorf("keyframe %d, prop %d: %w", k, p, err)
+				}
+			}
+		}
+	default:
+		if anim.Raw == nil {
+			return fmt.Errorf("unknown animation type 0x%02X", uint8(anim.Type))
+		}
+	}
+	return nil
+}
+
+func writeAnimation(buf *bytes.Buffer, anim *Animation) {
+	buf.WriteByte(anim.ID)
+	buf.WriteByte(uint8(anim.Type))
+	writeU16LE(buf, anim.DurationMs)
+	buf.WriteByte(uint8(anim.Easing))
+	switch anim.Type {
+	case AnimTypeTransition:
+		buf.WriteByte(uint8(anim.Transition.PropertyID))
+		buf.WriteByte(uint8(anim.Transition.ValueType))
+		buf.WriteByte(anim.Transition.Size)
+		buf.Write(anim.Transition.From)
+		buf.Write(anim.Transition.To)
+	case AnimTypeKeyframe:
+		buf.WriteByte(anim.KeyframeCount)
+		for k := range anim.Keyframes {
+			kf := &anim.Keyframes[k]
+			buf.WriteByte(kf.Time)
+			buf.WriteByte(kf.PropertyCount)
+			for p := range kf.Properties {
+				writeProperty(buf, &kf.Properties[p])
+			}
+		}
+	default:
+		buf.Write(anim.Raw)
+	}
+}
+
+// Tracks returns the animation as one track per animated property, with keys
+// sorted by normalized time. A transition yields a single track with keys at
+// 0.0 and 1.0. The key values alias the animation's data.
+func (a *Animation) Tracks() []AnimationTrack {
+	switch a.Type {
+	case AnimTypeTransition:
+		return []AnimationTrack{{
+			PropertyID: a.Transition.PropertyID,
+			ValueType:  a.Transition.ValueType,
+			Keys: []TrackKey{
+				{T: 0, Value: a.Transition.From},
+				{T: 1, Value: a.Transition.To},
+			},
+		}}
+	case AnimTypeKeyframe:
+		var tracks []AnimationTrack
+		trackIndex := make(map[PropertyID]int)
+		for _, kf := range a.Keyframes {
+			t := float32(kf.Time) / 255.0
+			for _, prop := range kf.Properties {
+				idx, ok := trackIndex[prop.ID]
+				if !ok {
+					idx = len(tracks)
+					trackIndex[prop.ID] = idx
+					tracks = append(tracks, AnimationTrack{PropertyID: prop.ID, ValueType: prop.ValueType})
+				}
+				tracks[idx].Keys = append(tracks[idx].Keys, TrackKey{T: t, Value: prop.Value})
+			}
+		}
+		for i := range tracks {
+			sort.SliceStable(tracks[i].Keys, func(x, y int) bool { return tracks[i].Keys[x].T < tracks[i].Keys[y].T })
+		}
+		return tracks
+	default:
+		return nil
+	}
+}
+
+// Ease maps linear progress t (0.0-1.0) through the easing curve.
+func (e EasingType) Ease(t float32) float32 {
+	if t <= 0 {
+		return 0
+	}
+	if t >= 1 {
+		return 1
+	}
+	switch e {
+	case EasingEaseIn:
+		return t * t
+	case EasingEaseOut:
+		return t * (2 - t)
+	case EasingEaseInOut:
+		if t < 0.5 {
+			return 2 * t * t
+		}
+		return -1 + (4-2*t)*t
+	default:
+		return t
+	}
+}
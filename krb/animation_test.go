@@ -0,0 +1,101 @@
+package krb
+
+import (
+	"bytes"
+	"testing"
+)
+
+func buildAnimatedDocument(t *testing.T) *Document {
+	t.Helper()
+	b := NewBuilder()
+	fade := b.AddTransition(300, EasingEaseOut, ColorProperty(PropIDBgColor, 0, 0, 0, 255), ColorProperty(PropIDBgColor, 255, 0, 0, 255))
+	pulse := b.AddKeyframeAnimation(1000, EasingLinear,
+		Keyframe{Time: 255, Properties: []Property{ShortProperty(PropIDFontSize, 20), ColorProperty(PropIDFgColor, 1, 2, 3, 4)}},
+		Keyframe{Time: 0, Properties: []Property{ShortProperty(PropIDFontSize, 10)}},
+	)
+	b.AddElement(ElemTypeApp).Animation(fade, AnimTriggerHover).
+		AddChild(ElemTypeText).Text("hi").Animation(pulse, AnimTriggerLoad)
+	doc, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+func TestAnimationTableRoundTrip(t *testing.T) {
+	data := encode(t, buildAnimatedDocument(t))
+	doc := decode(t, data)
+	if n := len(doc.Animations); n != 2 {
+		t.Fatalf("read %d animations, want 2", n)
+	}
+	fade, pulse := &doc.Animations[0], &doc.Animations[1]
+	if fade.Type != AnimTypeTransition || fade.DurationMs != 300 || fade.Easing != EasingEaseOut {
+		t.Errorf("transition = %+v", fade)
+	}
+	if !bytes.Equal(fade.Transition.To, []byte{255, 0, 0, 255}) {
+		t.Errorf("transition 'to' = %v", fade.Transition.To)
+	}
+	if pulse.Type != AnimTypeKeyframe || pulse.KeyframeCount != 2 || len(pulse.Keyframes[0].Properties) != 2 {
+		t.Errorf("keyframe animation = %+v", pulse)
+	}
+	if r := Validate(doc); r.HasErrors() {
+		t.Errorf("document does not validate:\n%s", r)
+	}
+	if got := encode(t, doc); !bytes.Equal(got, data) {
+		t.Error("re-encoding the animation table changed it")
+	}
+}
+
+func TestAnimationTracks(t *testing.T) {
+	doc := buildAnimatedDocument(t)
+	tracks := doc.Animations[1].Tracks()
+	if len(tracks) != 2 {
+		t.Fatalf("got %d tracks, want 2", len(tracks))
+	}
+	size := tracks[0]
+	if size.PropertyID != PropIDFontSize {
+		t.Fatalf("first track animates %v, want the font size", size.PropertyID)
+	}
+	if len(size.Keys) != 2 || size.Keys[0].T != 0 || size.Keys[1].T != 1 {
+		t.Errorf("font size keys = %+v, want keys at 0 and 1 in time order", size.Keys)
+	}
+
+	transition := doc.Animations[0].Tracks()
+	if len(transition) != 1 || len(transition[0].Keys) != 2 {
+		t.Errorf("transition tracks = %+v, want one track with two keys", transition)
+	}
+}
+
+func TestAnimationTableTruncated(t *testing.T) {
+	data := encode(t, buildAnimatedDocument(t))
+	if _, err := ReadDocument(bytes.NewReader(data[:len(data)-1])); err == nil {
+		t.Error("ReadDocument accepted a truncated animation table")
+	}
+}
+
+func TestAnimationOfUnknownTypeIsKept(t *testing.T) {
+	doc := buildAnimatedDocument(t)
+	data := encode(t, doc)
+	data[doc.Header.AnimationOffset+1] = 0x7F // Type of the first animation
+
+	got, err := ReadDocument(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("ReadDocument: %v", err)
+	}
+	if len(got.Animations) != 1 || got.Animations[0].Type != 0x7F {
+		t.Fatalf("read animations %+v, want only the undecoded one", got.Animations)
+	}
+	if want := int(doc.Header.StringOffset-doc.Header.AnimationOffset) - animationHeaderSize; len(got.Animations[0].Raw) != want {
+		t.Errorf("kept %d raw bytes, want the remaining %d bytes of the table", len(got.Animations[0].Raw), want)
+	}
+	r := Validate(got)
+	if r.HasErrors() {
+		t.Errorf("document with an unknown animation type has errors:\n%s", r)
+	}
+	if _, ok := findDiagnostic(r, SectionAnimations, "unknown animation type"); !ok {
+		t.Errorf("missing warning for the unknown animation type:\n%s", r)
+	}
+	if reencoded := encode(t, got); !bytes.Equal(reencoded, data) {
+		t.Error("re-encoding did not preserve the undecoded animation bytes")
+	}
+}
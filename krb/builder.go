@@ -0,0 +1,482 @@
+// krb/builder.go
+
+package krb
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// Builder assembles a Document in memory without going through the KRY compiler.
+// Strings are interned into the string table automatically (index 0 is always
+// the empty string, matching compiler output), and Build lays out the element
+// blocks so that ChildRef offsets, ElementStartOffsets and the header describe
+// the document exactly as WriteDocument will encode it.
+//
+// Elements are stored in creation order. Children created through
+// ElementBuilder.AddChild are always placed after their parent, which KRB
+// requires because ChildRef offsets are unsigned.
+//
+// The first error encountered is remembered and returned by Build; the fluent
+// methods keep returning usable builders so call chains don't need checks.
+type Builder struct {
+	doc       Document
+	stringIdx map[string]uint8
+	parents   []int
+	children  [][]int
+	err       error
+	hasBuilt  bool
+}
+
+// ElementBuilder configures a single element created by a Builder.
+type ElementBuilder struct {
+	b     *Builder
+	index int
+}
+
+// NewBuilder returns an empty Builder targeting KRB v0.4.
+func NewBuilder() *Builder {
+	b := &Builder{
+		stringIdx: make(map[string]uint8),
+	}
+	b.doc.Header.Magic = MagicNumber
+	b.doc.Header.Version = ExpectedVersion
+	b.String("")
+	return b
+}
+
+func (b *Builder) fail(err error) {
+	if b.err == nil {
+		b.err = err
+	}
+}
+
+// Flags ORs extra header flags (e.g. FlagFixedPoint) into the document.
+// Section flags, FlagHasApp and FlagExtendedColor are derived during Build.
+func (b *Builder) Flags(flags uint16) *Builder {
+	b.doc.Header.Flags |= flags
+	return b
+}
+
+// String interns s into the string table and returns its index.
+func (b *Builder) String(s string) uint8 {
+	if idx, ok := b.stringIdx[s]; ok {
+		return idx
+	}
+	if len(s) > 0xFF {
+		b.fail(fmt.Errorf("krb builder: string %q is too long (%d bytes, max 255)", truncateForError(s), len(s)))
+		return 0
+	}
+	if len(b.doc.Strings) > 0xFF {
+		b.fail(fmt.Errorf("krb builder: string table is full (256 entries), cannot add %q", truncateForError(s)))
+		return 0
+	}
+	idx := uint8(len(b.doc.Strings))
+	b.doc.Strings = append(b.doc.Strings, s)
+	b.stringIdx[s] = idx
+	return idx
+}
+
+func truncateForError(s string) string {
+	if len(s) > 32 {
+		return s[:32] + "..."
+	}
+	return s
+}
+
+// StringProperty returns a ValTypeString property whose value is the interned index of s.
+func (b *Builder) StringProperty(id PropertyID, s string) Property {
+	return Property{ID: id, ValueType: ValTypeString, Size: 1, Value: []byte{b.String(s)}}
+}
+
+// AddStyle appends a named style and returns its 1-based style ID.
+func (b *Builder) AddStyle(name string, props ...Property) uint8 {
+	if len(b.doc.Styles) >= 0xFF {
+		b.fail(fmt.Errorf("krb builder: too many styles, cannot add %q", name))
+		return 0
+	}
+	id := uint8(len(b.doc.Styles) + 1)
+	b.doc.Styles = append(b.doc.Styles, Style{
+		ID:         id,
+		NameIndex:  b.String(name),
+		Properties: append([]Property(nil), props...),
+	})
+	return id
+}
+
+// StyleID returns the ID of a style previously added with AddStyle, or 0.
+func (b *Builder) StyleID(name string) uint8 {
+	nameIdx, ok := b.stringIdx[name]
+	if !ok {
+		return 0
+	}
+	for i := range b.doc.Styles {
+		if b.doc.Styles[i].NameIndex == nameIdx {
+			return b.doc.Styles[i].ID
+		}
+	}
+	return 0
+}
+
+// AddExternalResource adds a resource loaded from path (relative to the KRB file)
+// and returns its index in the resource table.
+func (b *Builder) AddExternalResource(resType ResourceType, path string) uint8 {
+	nameIdx := b.String(path)
+	return b.addResource(Resource{
+		Type:            resType,
+		NameIndex:       nameIdx,
+		Format:          ResFormatExternal,
+		DataStringIndex: nameIdx,
+	})
+}
+
+// AddInlineResource embeds data in the resource table and returns its index.
+func (b *Builder) AddInlineResource(resType ResourceType, name string, data []byte) uint8 {
+	if len(data) > 0xFFFF {
+		b.fail(fmt.Errorf("krb builder: inline resource %q is too large (%d bytes)", name, len(data)))
+		return 0
+	}
+	return b.addResource(Resource{
+		Type:           resType,
+		NameIndex:      b.String(name),
+		Format:         ResFormatInline,
+		InlineDataSize: uint16(len(data)),
+		InlineData:     data,
+	})
+}
+
+func (b *Builder) addResource(res Resource) uint8 {
+	if len(b.doc.Resources) > 0xFF {
+		b.fail(errors.New("krb builder: resource table is full (256 entries)"))
+		return 0
+	}
+	b.doc.Resources = append(b.doc.Resources, res)
+	return uint8(len(b.doc.Resources) - 1)
+}
+
+// AddTransition appends a transition animation of one property and returns its
+// index in the animation table. from and to must be Property values with the
+// same ID and ValueType.
+func (b *Builder) AddTransition(durationMs uint16, easing EasingType, from, to Property) uint8 {
+	if from.ID != to.ID || from.ValueType != to.ValueType {
+		b.fail(fmt.Errorf("krb builder: transition endpoints differ (prop 0x%02X/0x%02X, type 0x%02X/0x%02X)",
+			uint8(from.ID), uint8(to.ID), uint8(from.ValueType), uint8(to.ValueType)))
+		return 0
+	}
+	return b.addAnimation(Animation{
+		Type:       AnimTypeTransition,
+		DurationMs: durationMs,
+		Easing:     easing,
+		Transition: AnimationTransition{
+			PropertyID: from.ID,
+			ValueType:  from.ValueType,
+			From:       append([]byte(nil), from.Value...),
+			To:         append([]byte(nil), to.Value...),
+		},
+	})
+}
+
+// AddKeyframeAnimation appends a keyframe animation and returns its index in the
+// animation table.
+func (b *Builder) AddKeyframeAnimation(durationMs uint16, easing EasingType, keyframes ...Keyframe) uint8 {
+	return b.addAnimation(Animation{
+		Type:       AnimTypeKeyframe,
+		DurationMs: durationMs,
+		Easing:     easing,
+		Keyframes:  append([]Keyframe(nil), keyframes...),
+	})
+}
+
+func (b *Builder) addAnimation(anim Animation) uint8 {
+	if len(b.doc.Animations) >= 0x100 {
+		b.fail(errors.New("krb builder: too many animations"))
+		return 0
+	}
+	anim.ID = uint8(len(b.doc.Animations))
+	b.doc.Animations = append(b.doc.Animations, anim)
+	return anim.ID
+}
+
+// AddElement appends a new top-level element (one without a parent).
+func (b *Builder) AddElement(elemType ElementType) *ElementBuilder {
+	return b.newElement(elemType, -1)
+}
+
+func (b *Builder) newElement(elemType ElementType, parent int) *ElementBuilder {
+	index := len(b.doc.Elements)
+	if index >= 0xFFFF {
+		b.fail(errors.New("krb builder: too many elements"))
+	}
+	b.doc.Elements = append(b.doc.Elements, ElementHeader{Type: elemType})
+	b.doc.Properties = append(b.doc.Properties, nil)
+	b.doc.CustomProperties = append(b.doc.CustomProperties, nil)
+	b.doc.Events = append(b.doc.Events, nil)
+	b.doc.AnimationRefs = append(b.doc.AnimationRefs, nil)
+	b.doc.ChildRefs = append(b.doc.ChildRefs, nil)
+	b.parents = append(b.parents, parent)
+	b.children = append(b.children, nil)
+	if parent >= 0 {
+		b.children[parent] = append(b.children[parent], index)
+	}
+	return &ElementBuilder{b: b, index: index}
+}
+
+// Build finalizes the document: it syncs all counts, assigns element start
+// offsets and ChildRefs, and fills in the header. The Builder must not be
+// modified after Build.
+func (b *Builder) Build() (*Document, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	if b.hasBuilt {
+		return nil, errors.New("krb builder: Build called more than once")
+	}
+	b.hasBuilt = true
+	doc := &b.doc
+
+	// Size the ChildRef blocks so element sizes are known before offsets are assigned.
+	for i := range doc.Elements {
+		if n := len(b.children[i]); n > 0 {
+			doc.ChildRefs[i] = make([]ChildRef, n)
+		}
+	}
+	if err := syncDocumentCounts(doc); err != nil {
+		return nil, fmt.Errorf("krb builder: %w", err)
+	}
+	startOffsets := computeElementStartOffsets(doc)
+	if err := assignChildRefs(doc, b.children, startOffsets); err != nil {
+		return nil, fmt.Errorf("krb builder: %w", err)
+	}
+	doc.ElementStartOffsets = startOffsets
+
+	hdr := &doc.Header
+	hdr.Flags = setFlag(hdr.Flags, FlagHasApp, len(doc.Elements) > 0 && doc.Elements[0].Type == ElemTypeApp)
+	if documentUsesRGBAColors(doc) {
+		hdr.Flags |= FlagExtendedColor
+	}
+	computeSectionOffsets(doc)
+	return doc, nil
+}
+
+func documentUsesRGBAColors(doc *Document) bool {
+	isRGBA := func(props []Property) bool {
+		for _, prop := range props {
+			if prop.ValueType == ValTypeColor && len(prop.Value) == 4 {
+				return true
+			}
+		}
+		return false
+	}
+	for _, props := range doc.Properties {
+		if isRGBA(props) {
+			return true
+		}
+	}
+	for i := range doc.Styles {
+		if isRGBA(doc.Styles[i].Properties) {
+			return true
+		}
+	}
+	for i := range doc.Animations {
+		anim := &doc.Animations[i]
+		if anim.Type == AnimTypeTransition && anim.Transition.ValueType == ValTypeColor && len(anim.Transition.From) == 4 {
+			return true
+		}
+		for _, kf := range anim.Keyframes {
+			if isRGBA(kf.Properties) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// --- ElementBuilder ---
+
+// Index returns the element's index in Document.Elements.
+func (e *ElementBuilder) Index() int { return e.index }
+
+// Builder returns the Builder that owns this element.
+func (e *ElementBuilder) Builder() *Builder { return e.b }
+
+// Parent returns the builder for this element's parent, or nil for top-level elements.
+func (e *ElementBuilder) Parent() *ElementBuilder {
+	parent := e.b.parents[e.index]
+	if parent < 0 {
+		return nil
+	}
+	return &ElementBuilder{b: e.b, index: parent}
+}
+
+func (e *ElementBuilder) header() *ElementHeader { return &e.b.doc.Elements[e.index] }
+
+// ID sets the element's id string (KRY `id`).
+func (e *ElementBuilder) ID(name string) *ElementBuilder {
+	e.header().ID = e.b.String(name)
+	return e
+}
+
+// Pos sets the header PosX/PosY.
+func (e *ElementBuilder) Pos(x, y uint16) *ElementBuilder {
+	hdr := e.header()
+	hdr.PosX, hdr.PosY = x, y
+	return e
+}
+
+// Size sets the header Width/Height in unscaled pixels.
+func (e *ElementBuilder) Size(width, height uint16) *ElementBuilder {
+	hdr := e.header()
+	hdr.Width, hdr.Height = width, height
+	return e
+}
+
+// Layout sets the header layout byte (see LayoutDirectionMask and friends).
+func (e *ElementBuilder) Layout(layout uint8) *ElementBuilder {
+	e.header().Layout = layout
+	return e
+}
+
+// Style sets the element's 1-based StyleID.
+func (e *ElementBuilder) Style(styleID uint8) *ElementBuilder {
+	e.header().StyleID = styleID
+	return e
+}
+
+// StyleName sets the StyleID of a style previously added with AddStyle.
+func (e *ElementBuilder) StyleName(name string) *ElementBuilder {
+	styleID := e.b.StyleID(name)
+	if styleID == 0 {
+		e.b.fail(fmt.Errorf("krb builder: element %d references unknown style %q", e.index, name))
+	}
+	e.header().StyleID = styleID
+	return e
+}
+
+// Property appends a standard property.
+func (e *ElementBuilder) Property(props ...Property) *ElementBuilder {
+	e.b.doc.Properties[e.index] = append(e.b.doc.Properties[e.index], props...)
+	return e
+}
+
+// StringProperty appends a string-valued standard property, interning s.
+func (e *ElementBuilder) StringProperty(id PropertyID, s string) *ElementBuilder {
+	return e.Property(e.b.StringProperty(id, s))
+}
+
+// Text sets the element's text content.
+func (e *ElementBuilder) Text(s string) *ElementBuilder {
+	return e.StringProperty(PropIDTextContent, s)
+}
+
+// CustomProperty appends a custom property keyed by name.
+func (e *ElementBuilder) CustomProperty(key string, valueType ValueType, value []byte) *ElementBuilder {
+	e.b.doc.CustomProperties[e.index] = append(e.b.doc.CustomProperties[e.index], CustomProperty{
+		KeyIndex:  e.b.String(key),
+		ValueType: valueType,
+		Size:      uint8(len(value)),
+		Value:     value,
+	})
+	return e
+}
+
+// CustomString appends a string-valued custom property, interning both key and value.
+func (e *ElementBuilder) CustomString(key, value string) *ElementBuilder {
+	return e.CustomProperty(key, ValTypeString, []byte{e.b.String(value)})
+}
+
+// Component marks the element as an instance of the named component definition.
+func (e *ElementBuilder) Component(name string) *ElementBuilder {
+	return e.CustomString("_componentName", name)
+}
+
+// Event binds an event to a named callback.
+func (e *ElementBuilder) Event(eventType EventType, callback string) *ElementBuilder {
+	e.b.doc.Events[e.index] = append(e.b.doc.Events[e.index], EventFileEntry{
+		EventType:  eventType,
+		CallbackID: e.b.String(callback),
+	})
+	return e
+}
+
+// OnClick is shorthand for Event(EventTypeClick, callback).
+func (e *ElementBuilder) OnClick(callback string) *ElementBuilder {
+	return e.Event(EventTypeClick, callback)
+}
+
+// Animation attaches an animation reference, as returned by AddTransition or
+// AddKeyframeAnimation, that starts on trigger.
+func (e *ElementBuilder) Animation(animationIndex uint8, trigger AnimationTrigger) *ElementBuilder {
+	e.b.doc.AnimationRefs[e.index] = append(e.b.doc.AnimationRefs[e.index], AnimationRef{
+		AnimationIndex: animationIndex,
+		Trigger:        trigger,
+	})
+	return e
+}
+
+// AddChild creates a new element as the last child of e and returns it.
+func (e *ElementBuilder) AddChild(elemType ElementType) *ElementBuilder {
+	return e.b.newElement(elemType, e.index)
+}
+
+// Append attaches existing top-level elements as children of e. Each child must
+// have been created after e.
+func (e *ElementBuilder) Append(children ...*ElementBuilder) *ElementBuilder {
+	for _, child := range children {
+		switch {
+		case child == nil || child.b != e.b:
+			e.b.fail(fmt.Errorf("krb builder: element %d cannot adopt a child from another builder", e.index))
+		case e.b.parents[child.index] >= 0:
+			e.b.fail(fmt.Errorf("krb builder: element %d already has parent %d", child.index, e.b.parents[child.index]))
+		case child.index <= e.index:
+			e.b.fail(fmt.Errorf("krb builder: child element %d must be created after parent %d", child.index, e.index))
+		default:
+			e.b.parents[child.index] = e.index
+			e.b.children[e.index] = append(e.b.children[e.index], child.index)
+		}
+	}
+	return e
+}
+
+// --- Property constructors ---
+
+// ByteProperty returns a ValTypeByte property.
+func ByteProperty(id PropertyID, v uint8) Property {
+	return Property{ID: id, ValueType: ValTypeByte, Size: 1, Value: []byte{v}}
+}
+
+// EnumProperty returns a ValTypeEnum property.
+func EnumProperty(id PropertyID, v uint8) Property {
+	return Property{ID: id, ValueType: ValTypeEnum, Size: 1, Value: []byte{v}}
+}
+
+// ShortProperty returns a little-endian ValTypeShort property.
+func ShortProperty(id PropertyID, v uint16) Property {
+	value := make([]byte, 2)
+	binary.LittleEndian.PutUint16(value, v)
+	return Property{ID: id, ValueType: ValTypeShort, Size: 2, Value: value}
+}
+
+// PercentageProperty returns a ValTypePercentage property. Percentages are
+// 8.8 fixed point, so 100% is encoded as 256.
+func PercentageProperty(id PropertyID, percent float32) Property {
+	value := make([]byte, 2)
+	binary.LittleEndian.PutUint16(value, uint16(percent/100.0*256.0+0.5))
+	return Property{ID: id, ValueType: ValTypePercentage, Size: 2, Value: value}
+}
+
+// ColorProperty returns an RGBA ValTypeColor property. Build sets
+// FlagExtendedColor when any RGBA color is present.
+func ColorProperty(id PropertyID, r, g, b, a uint8) Property {
+	return Property{ID: id, ValueType: ValTypeColor, Size: 4, Value: []byte{r, g, b, a}}
+}
+
+// EdgeInsetsProperty returns a ValTypeEdgeInsets property (top, right, bottom, left).
+func EdgeInsetsProperty(id PropertyID, top, right, bottom, left uint8) Property {
+	return Property{ID: id, ValueType: ValTypeEdgeInsets, Size: 4, Value: []byte{top, right, bottom, left}}
+}
+
+// ResourceProperty returns a ValTypeResource property referencing the resource table.
+func ResourceProperty(id PropertyID, resourceIndex uint8) Property {
+	return Property{ID: id, ValueType: ValTypeResource, Size: 1, Value: []byte{resourceIndex}}
+}
@@ -0,0 +1,66 @@
+package krb
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestBuilderBuildsEncodableTree(t *testing.T) {
+	b := NewBuilder()
+	base := b.AddStyle("base", ColorProperty(PropIDBgColor, 25, 25, 25, 255), ByteProperty(PropIDBorderWidth, 1))
+	app := b.AddElement(ElemTypeApp).Style(base).Property(ShortProperty(PropIDWindowWidth, 600)).StringProperty(PropIDWindowTitle, "Hi")
+	box := app.AddChild(ElemTypeContainer).ID("box")
+	box.AddChild(ElemTypeText).Text("a")
+	app.AddChild(ElemTypeButton).Text("Click").OnClick("handleClick").Size(150, 50)
+	box.AddChild(ElemTypeText).Text("b")
+
+	doc, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.Header.Flags&FlagHasApp == 0 {
+		t.Error("FlagHasApp is not set")
+	}
+	if n := len(doc.Elements); n != 5 {
+		t.Fatalf("built %d elements, want 5", n)
+	}
+	// Elements keep their creation order; ChildRefs may point past siblings.
+	wantTypes := []ElementType{ElemTypeApp, ElemTypeContainer, ElemTypeText, ElemTypeButton, ElemTypeText}
+	for i, want := range wantTypes {
+		if got := doc.Elements[i].Type; got != want {
+			t.Errorf("element %d has type %v, want %v", i, got, want)
+		}
+	}
+	if got := doc.Elements[1].ChildCount; got != 2 {
+		t.Errorf("box has ChildCount %d, want 2", got)
+	}
+	if r := Validate(doc); r.HasErrors() {
+		t.Errorf("built document does not validate:\n%s", r)
+	}
+
+	data := encode(t, doc)
+	if got := encode(t, decode(t, data)); !bytes.Equal(got, data) {
+		t.Error("decoding and re-encoding the built document changed it")
+	}
+}
+
+func TestBuilderInternsStrings(t *testing.T) {
+	b := NewBuilder()
+	if b.String("x") != b.String("x") {
+		t.Error("the same string was interned twice")
+	}
+	if b.StyleID("missing") != 0 {
+		t.Error("StyleID of an unknown style is not 0")
+	}
+}
+
+func TestBuilderRejectsSecondBuild(t *testing.T) {
+	b := NewBuilder()
+	b.AddElement(ElemTypeApp)
+	if _, err := b.Build(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.Build(); err == nil {
+		t.Error("second Build succeeded")
+	}
+}
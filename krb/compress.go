@@ -0,0 +1,107 @@
+// krb/compress.go
+
+package krb
+
+import (
+	"bytes"
+	"compress/flate"
+	"fmt"
+	"io"
+)
+
+// A compressed KRB file keeps the 48-byte header uncompressed. Everything after
+// it (all sections, in their usual order) is stored as a single stream encoded
+// with the codec recorded in Header.Flags. Section offsets and TotalSize in the
+// header always describe the uncompressed image, so once the payload is
+// inflated the file is parsed exactly like an uncompressed one.
+
+// Codec returns the compression codec recorded in the header flags.
+func (h *Header) Codec() CompressionCodec {
+	return CompressionCodec((h.Flags & CompressionCodecMask) >> CompressionCodecShift)
+}
+
+// SetCompression marks the header as compressed with the given codec, or clears
+// compression when codec is CodecNone. WriteDocument honors this setting.
+func (h *Header) SetCompression(codec CompressionCodec) {
+	h.Flags &^= CompressionCodecMask
+	if codec == CodecNone {
+		h.Flags &^= FlagCompressed
+		return
+	}
+	h.Flags |= FlagCompressed | (uint16(codec)<<CompressionCodecShift)&CompressionCodecMask
+}
+
+func (c CompressionCodec) String() string {
+	switch c {
+	case CodecNone:
+		return "none"
+	case CodecDeflate:
+		return "deflate"
+	default:
+		return fmt.Sprintf("CompressionCodec(%d)", uint8(c))
+	}
+}
+
+// maxPreallocatedPayload bounds how much memory decompressSections reserves
+// before any data has been inflated.
+const maxPreallocatedPayload = 1 << 20
+
+// decompressSections reads the compressed payload following the header from r
+// and returns a reader over the full uncompressed file image (header included).
+// r must be positioned directly after the header.
+func decompressSections(r io.Reader, headerBuf []byte, hdr *Header) (io.ReadSeeker, error) {
+	if hdr.TotalSize < HeaderSize {
+		return nil, fmt.Errorf("krb read: compressed file has invalid TotalSize %d", hdr.TotalSize)
+	}
+	payloadSize := int64(hdr.TotalSize) - HeaderSize
+
+	var src io.ReadCloser
+	switch codec := hdr.Codec(); codec {
+	case CodecDeflate:
+		src = flate.NewReader(r)
+	default:
+		return nil, fmt.Errorf("krb read: unsupported compression codec %s", codec)
+	}
+	defer src.Close()
+
+	// TotalSize comes from the file and is not trusted until the payload has
+	// actually inflated to that size, so only a bounded amount is reserved up
+	// front and the buffer grows with the data.
+	image := bytes.NewBuffer(make([]byte, 0, HeaderSize+min(payloadSize, maxPreallocatedPayload)))
+	image.Write(headerBuf)
+	// Read one byte past the expected size so oversized payloads are detected
+	// without inflating an unbounded stream.
+	n, err := io.Copy(image, io.LimitReader(src, payloadSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("krb read: failed to decompress sections (%s): %w", hdr.Codec(), err)
+	}
+	if n != payloadSize {
+		return nil, fmt.Errorf("krb read: decompressed section data is %d bytes, header TotalSize implies %d", n, payloadSize)
+	}
+	return bytes.NewReader(image.Bytes()), nil
+}
+
+// compressSections encodes the section data of an uncompressed file image
+// (everything after the header) with the codec recorded in hdr and returns the
+// header followed by the compressed payload.
+func compressSections(image []byte, hdr *Header) ([]byte, error) {
+	var out bytes.Buffer
+	out.Write(image[:HeaderSize])
+
+	switch codec := hdr.Codec(); codec {
+	case CodecDeflate:
+		fw, err := flate.NewWriter(&out, flate.BestCompression)
+		if err != nil {
+			return nil, fmt.Errorf("krb write: failed to create %s encoder: %w", codec, err)
+		}
+		if _, err := fw.Write(image[HeaderSize:]); err != nil {
+			return nil, fmt.Errorf("krb write: failed to compress sections: %w", err)
+		}
+		if err := fw.Close(); err != nil {
+			return nil, fmt.Errorf("krb write: failed to compress sections: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("krb write: unsupported compression codec %s", codec)
+	}
+	return out.Bytes(), nil
+}
@@ -0,0 +1,66 @@
+package krb
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestCompressedRoundTrip(t *testing.T) {
+	for _, path := range exampleFiles {
+		t.Run(path, func(t *testing.T) {
+			raw, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			doc := decode(t, raw)
+			doc.Header.SetCompression(CodecDeflate)
+			compressed := encode(t, doc)
+			if bytes.Equal(compressed[HeaderSize:], raw[HeaderSize:]) {
+				t.Fatal("section data was written uncompressed")
+			}
+
+			doc = decode(t, compressed)
+			if got := doc.Header.Codec(); got != CodecDeflate {
+				t.Errorf("read codec %s, want %s", got, CodecDeflate)
+			}
+			if r := Validate(doc); r.HasErrors() {
+				t.Errorf("decompressed document does not validate:\n%s", r)
+			}
+			doc.Header.SetCompression(CodecNone)
+			if got := encode(t, doc); !bytes.Equal(got, raw) {
+				t.Error("decompressing and re-encoding without compression changed the file")
+			}
+		})
+	}
+}
+
+func TestCompressedPayloadSizeMismatch(t *testing.T) {
+	raw, err := os.ReadFile(exampleFiles[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := decode(t, raw)
+	doc.Header.SetCompression(CodecDeflate)
+	compressed := encode(t, doc)
+
+	for _, tc := range []struct {
+		name      string
+		totalSize uint32
+	}{
+		{"larger", doc.Header.TotalSize + 1},
+		{"smaller", doc.Header.TotalSize - 1},
+		{"huge", 0xFFFFFFFF},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			data := append([]byte(nil), compressed...)
+			// TotalSize is the last header field.
+			data[44], data[45], data[46], data[47] = byte(tc.totalSize), byte(tc.totalSize>>8), byte(tc.totalSize>>16), byte(tc.totalSize>>24)
+			_, err := ReadDocument(bytes.NewReader(data))
+			if err == nil || !strings.Contains(err.Error(), "decompressed section data") {
+				t.Errorf("ReadDocument error = %v, want a size mismatch", err)
+			}
+		})
+	}
+}
@@ -52,6 +52,17 @@ func ReadDocument(r io.ReadSeeker) (*Document, error) {
 			doc.VersionMajor, doc.VersionMinor, SpecVersionMajor, SpecVersionMinor)
 	}
 
+	// --- Transparent Decompression ---
+	// Header offsets describe the uncompressed image, so all section parsing below
+	// runs unchanged against the inflated data.
+	if (doc.Header.Flags & FlagCompressed) != 0 {
+		decompressed, err := decompressSections(r, headerBuf, &doc.Header)
+		if err != nil {
+			return nil, err
+		}
+		r = decompressed
+	}
+
 	// Basic Offset Sanity Checks
 	if doc.Header.ElementCount > 0 && doc.Header.ElementOffset < HeaderSize {
 		return nil, errors.New("krb read: element offset overlaps header")
@@ -72,7 +83,6 @@ func ReadDocument(r io.ReadSeeker) (*Document, error) {
 		return nil, errors.New("krb read: resource offset overlaps header")
 	}
 
-
 	// --- Eagerly Read String Table ---
 	// It's often needed by other sections (like ComponentDef names) for meaningful logging or early validation.
 	if doc.Header.StringCount > 0 {
@@ -106,7 +116,6 @@ func ReadDocument(r io.ReadSeeker) (*Document, error) {
 		}
 	}
 
-
 	// --- 2. Read Element Blocks (Main UI Tree) ---
 	if doc.Header.ElementCount > 0 {
 		doc.Elements = make([]ElementHeader, doc.Header.ElementCount)
@@ -222,7 +231,7 @@ func ReadDocument(r io.ReadSeeker) (*Document, error) {
 					offset := int(j) * AnimationRefSize
 					doc.AnimationRefs[i][j] = AnimationRef{
 						AnimationIndex: animRefBuf[offset],
-						Trigger:        animRefBuf[offset+1],
+						Trigger:        AnimationTrigger(animRefBuf[offset+1]),
 					}
 				}
 			}
@@ -318,7 +327,7 @@ func ReadDocument(r io.ReadSeeker) (*Document, error) {
 					}
 				}
 			}
-			
+
 			// The stream 'r' is now positioned at the start of RootElementTemplateData for component 'i'.
 			// We use calculateAndReadKrbElementTree to parse this self-contained tree.
 			// This function will read from 'r', determine the tree's size, and return the bytes.
@@ -329,7 +338,7 @@ func ReadDocument(r io.ReadSeeker) (*Document, error) {
 			} else {
 				compDefNameForLog = fmt.Sprintf("UnknownName(Index:%d)", compDef.NameIndex)
 			}
-			
+
 			// log.Printf("Debug: Reading RootElementTemplateData for CompDef %d ('%s')", i, compDefNameForLog)
 			_, templateDataBytes, err := calculateAndReadKrbElementTree(r)
 			if err != nil {
@@ -340,13 +349,12 @@ func ReadDocument(r io.ReadSeeker) (*Document, error) {
 		}
 	}
 
-
 	// --- 5. Read Animation Table ---
 	if doc.Header.AnimationCount > 0 {
 		if _, err := r.Seek(int64(doc.Header.AnimationOffset), io.SeekStart); err != nil {
 			return nil, fmt.Errorf("krb read: failed to seek to animation offset %d: %w", doc.Header.AnimationOffset, err)
 		}
-		
+
 		var endOfAnimationSection uint32
 		// Determine end of animation section by finding the start of the next known section,
 		// or defaulting to TotalSize if it's the last one.
@@ -359,10 +367,9 @@ func ReadDocument(r io.ReadSeeker) (*Document, error) {
 			nextSectionOffset = doc.Header.ResourceOffset
 		}
 		// If ComponentDefs are after Animations (unlikely by spec order, but for robustness):
-        if doc.Header.ComponentDefCount > 0 && (doc.Header.Flags&FlagHasComponentDefs) != 0 && doc.Header.ComponentDefOffset > doc.Header.AnimationOffset && doc.Header.ComponentDefOffset < nextSectionOffset {
-             nextSectionOffset = doc.Header.ComponentDefOffset
-        }
-
+		if doc.Header.ComponentDefCount > 0 && (doc.Header.Flags&FlagHasComponentDefs) != 0 && doc.Header.ComponentDefOffset > doc.Header.AnimationOffset && doc.Header.ComponentDefOffset < nextSectionOffset {
+			nextSectionOffset = doc.Header.ComponentDefOffset
+		}
 
 		endOfAnimationSection = nextSectionOffset
 		animationSectionSize := endOfAnimationSection - doc.Header.AnimationOffset
@@ -372,13 +379,32 @@ func ReadDocument(r io.ReadSeeker) (*Document, error) {
 		}
 
 		if animationSectionSize > 0 {
-			doc.Animations = make([]byte, animationSectionSize) // Store as raw blob for now
-			if _, err := io.ReadFull(r, doc.Animations); err != nil {
+			animationData := make([]byte, animationSectionSize)
+			if _, err := io.ReadFull(r, animationData); err != nil {
 				return nil, fmt.Errorf("krb read: failed to read animation table (size %d): %w", animationSectionSize, err)
 			}
-			log.Printf("Warning: KRB Animation Table found (%d animations, %d bytes) but detailed parsing is not yet implemented. Read as raw blob.", doc.Header.AnimationCount, animationSectionSize)
+			animations, trailing, err := parseAnimationTable(animationData, doc.Header.AnimationCount)
+			if err != nil {
+				return nil, err
+			}
+			doc.Animations = animations
+			if trailing > 0 {
+				log.Printf("Warning: KRB Animation Table has %d unused trailing bytes after %d animations.", trailing, len(animations))
+			}
 		} else if animationSectionSize == 0 && doc.Header.AnimationCount > 0 {
-			log.Printf("Warning: KRB Animation Table header indicates %d animations, but calculated section size is 0.", doc.Header.AnimationCount)
+			return nil, fmt.Errorf("krb read: header indicates %d animations, but the animation section is empty", doc.Header.AnimationCount)
+		}
+	}
+
+	// Animation refs were read with the elements; now that the table is known,
+	// report refs that point past it.
+	animationCount := animationEntryCount(doc)
+	for i, animRefs := range doc.AnimationRefs {
+		for j, animRef := range animRefs {
+			if int(animRef.AnimationIndex) >= animationCount {
+				log.Printf("Warning: Element %d animation ref %d points at animation %d, but the table has %d animations.",
+					i, j, animRef.AnimationIndex, animationCount)
+			}
 		}
 	}
 
@@ -415,7 +441,6 @@ func ReadDocument(r io.ReadSeeker) (*Document, error) {
 		}
 	}
 
-
 	// --- 7. Read Resource Table ---
 	if doc.Header.ResourceCount > 0 {
 		doc.Resources = make([]Resource, doc.Header.ResourceCount)
@@ -466,7 +491,6 @@ func ReadDocument(r io.ReadSeeker) (*Document, error) {
 	return doc, nil
 }
 
-
 // calculateAndReadKrbElementTree reads a self-contained KRB element tree from the stream.
 // It determines the total size of this tree (root element + all its descendants within the tree)
 // by parsing its structure, then reads the entire tree into a byte slice.
@@ -486,7 +510,7 @@ func calculateAndReadKrbElementTree(r io.ReadSeeker) (totalTreeSize uint32, tree
 	// Queue of element offsets (relative to startOffsetOfTree) to process.
 	// These offsets point to the headers of elements within the tree.
 	processingQueue := []uint32{0} // Start with the root element at relative offset 0.
-	
+
 	// Tracks the maximum relative offset reached by the end of any processed element block.
 	// This will determine the total size of the serialized tree.
 	maxRelativeExtent := uint32(0)
@@ -533,43 +557,57 @@ func calculateAndReadKrbElementTree(r io.ReadSeeker) (totalTreeSize uint32, tree
 
 		// Size of Standard Properties
 		for j := uint8(0); j < elemHdr.PropertyCount; j++ {
-			if _, err := io.ReadFull(r, propHeaderBuf); err != nil { return 0, nil, fmt.Errorf("calc: std_prop header read failed: %w", err) }
+			if _, err := io.ReadFull(r, propHeaderBuf); err != nil {
+				return 0, nil, fmt.Errorf("calc: std_prop header read failed: %w", err)
+			}
 			currentElementBlockSize += 3
 			propDataSize := propHeaderBuf[2]
 			if propDataSize > 0 {
-				if _, err := r.Seek(int64(propDataSize), io.SeekCurrent); err != nil { return 0, nil, fmt.Errorf("calc: std_prop seek data failed: %w", err) }
+				if _, err := r.Seek(int64(propDataSize), io.SeekCurrent); err != nil {
+					return 0, nil, fmt.Errorf("calc: std_prop seek data failed: %w", err)
+				}
 				currentElementBlockSize += uint32(propDataSize)
 			}
 		}
 		// Size of Custom Properties
 		for j := uint8(0); j < elemHdr.CustomPropCount; j++ {
-			if _, err := io.ReadFull(r, propHeaderBuf); err != nil { return 0, nil, fmt.Errorf("calc: custom_prop header read failed: %w", err) }
+			if _, err := io.ReadFull(r, propHeaderBuf); err != nil {
+				return 0, nil, fmt.Errorf("calc: custom_prop header read failed: %w", err)
+			}
 			currentElementBlockSize += 3
 			propDataSize := propHeaderBuf[2]
 			if propDataSize > 0 {
-				if _, err := r.Seek(int64(propDataSize), io.SeekCurrent); err != nil { return 0, nil, fmt.Errorf("calc: custom_prop seek data failed: %w", err) }
+				if _, err := r.Seek(int64(propDataSize), io.SeekCurrent); err != nil {
+					return 0, nil, fmt.Errorf("calc: custom_prop seek data failed: %w", err)
+				}
 				currentElementBlockSize += uint32(propDataSize)
 			}
 		}
 		// Size of Events
 		eventsBlockSize := uint32(elemHdr.EventCount) * uint32(EventFileEntrySize)
-		if _, err := r.Seek(int64(eventsBlockSize), io.SeekCurrent); err != nil { return 0, nil, fmt.Errorf("calc: events seek failed: %w", err) }
+		if _, err := r.Seek(int64(eventsBlockSize), io.SeekCurrent); err != nil {
+			return 0, nil, fmt.Errorf("calc: events seek failed: %w", err)
+		}
 		currentElementBlockSize += eventsBlockSize
 		// Size of Animation Refs
 		animRefsBlockSize := uint32(elemHdr.AnimationCount) * uint32(AnimationRefSize)
-		if _, err := r.Seek(int64(animRefsBlockSize), io.SeekCurrent); err != nil { return 0, nil, fmt.Errorf("calc: anim_refs seek failed: %w", err) }
+		if _, err := r.Seek(int64(animRefsBlockSize), io.SeekCurrent); err != nil {
+			return 0, nil, fmt.Errorf("calc: anim_refs seek failed: %w", err)
+		}
 		currentElementBlockSize += animRefsBlockSize
 
 		// Add children from ChildRefs to the queue and include ChildRef block size
 		if elemHdr.ChildCount > 0 {
 			for j := uint8(0); j < elemHdr.ChildCount; j++ {
-				if _, err := io.ReadFull(r, childRefBufItem); err != nil { return 0, nil, fmt.Errorf("calc: child_ref read failed: %w", err) }
+				if _, err := io.ReadFull(r, childRefBufItem); err != nil {
+					return 0, nil, fmt.Errorf("calc: child_ref read failed: %w", err)
+				}
 				currentElementBlockSize += uint32(ChildRefSize) // Size of the ChildRef entry itself
-				
+
 				childRelOffsetFromParentHeader := ReadU16LE(childRefBufItem)
 				// The child's offset relative to the *start of the entire tree*
 				childActualTreeRelativeOffset := currentElementRelativeOffset + uint32(childRelOffsetFromParentHeader)
-				
+
 				// Add to queue only if not already processed (or scheduled)
 				// This check isn't strictly necessary with the `elementBlockSizes` map check,
 				// but good for clarity if queue could have duplicates from complex structures.
@@ -588,7 +626,7 @@ func calculateAndReadKrbElementTree(r io.ReadSeeker) (totalTreeSize uint32, tree
 				}
 			}
 		}
-		
+
 		elementBlockSizes[currentElementRelativeOffset] = currentElementBlockSize
 		currentElementEndRelativeOffset := currentElementRelativeOffset + currentElementBlockSize
 		if currentElementEndRelativeOffset > maxRelativeExtent {
@@ -606,7 +644,7 @@ func calculateAndReadKrbElementTree(r io.ReadSeeker) (totalTreeSize uint32, tree
 	if _, err := io.ReadFull(r, treeData); err != nil {
 		return 0, nil, fmt.Errorf("calculateAndReadKrbElementTree: final read of tree data (size %d) failed: %w", totalTreeSize, err)
 	}
-	
+
 	// Critical: Ensure the main reader 'r' is positioned *after* this tree.
 	if _, err := r.Seek(startOffsetOfTree+int64(totalTreeSize), io.SeekStart); err != nil {
 		return 0, nil, fmt.Errorf("calculateAndReadKrbElementTree: final seek to position reader after tree failed: %w", err)
@@ -22,6 +22,18 @@ const (
 	FlagHasApp           uint16 = 1 << 7
 )
 
+// CompressionCodec identifies how the section data of a FlagCompressed file is
+// encoded. It is stored in bits 8-11 of Header.Flags.
+type CompressionCodec uint8
+
+const (
+	CodecNone    CompressionCodec = 0x00
+	CodecDeflate CompressionCodec = 0x01 // RFC 1951 raw DEFLATE (compress/flate)
+
+	CompressionCodecShift        = 8
+	CompressionCodecMask  uint16 = 0x0F << CompressionCodecShift
+)
+
 type ElementType uint8
 
 const (
@@ -42,42 +54,45 @@ const (
 type PropertyID uint8
 
 const (
-	PropIDInvalid           PropertyID = 0x00
-	PropIDBgColor           PropertyID = 0x01
-	PropIDFgColor           PropertyID = 0x02
-	PropIDBorderColor       PropertyID = 0x03
-	PropIDBorderWidth       PropertyID = 0x04
-	PropIDBorderRadius      PropertyID = 0x05
-	PropIDPadding           PropertyID = 0x06
-	PropIDMargin            PropertyID = 0x07
-	PropIDTextContent       PropertyID = 0x08
-	PropIDFontSize          PropertyID = 0x09
-	PropIDFontWeight        PropertyID = 0x0A
-	PropIDTextAlignment     PropertyID = 0x0B
-	PropIDImageSource       PropertyID = 0x0C
-	PropIDOpacity           PropertyID = 0x0D
-	PropIDZIndex            PropertyID = 0x0E
-	PropIDVisibility        PropertyID = 0x0F
-	PropIDGap               PropertyID = 0x10
-	PropIDMinWidth          PropertyID = 0x11
-	PropIDMinHeight         PropertyID = 0x12
-	PropIDMaxWidth          PropertyID = 0x13
-	PropIDMaxHeight         PropertyID = 0x14
-	PropIDAspectRatio       PropertyID = 0x15
-	PropIDTransform         PropertyID = 0x16
-	PropIDShadow            PropertyID = 0x17
-	PropIDOverflow          PropertyID = 0x18
-	PropIDCustomDataBlob    PropertyID = 0x19
-	PropIDLayoutFlags       PropertyID = 0x1A
-	PropIDWindowWidth       PropertyID = 0x20
-	PropIDWindowHeight      PropertyID = 0x21
-	PropIDWindowTitle       PropertyID = 0x22
-	PropIDResizable         PropertyID = 0x23
-	PropIDKeepAspect        PropertyID = 0x24
-	PropIDScaleFactor       PropertyID = 0x25
-	PropIDIcon              PropertyID = 0x26
-	PropIDVersion           PropertyID = 0x27
-	PropIDAuthor            PropertyID = 0x28
+	PropIDInvalid        PropertyID = 0x00
+	PropIDBgColor        PropertyID = 0x01
+	PropIDFgColor        PropertyID = 0x02
+	PropIDBorderColor    PropertyID = 0x03
+	PropIDBorderWidth    PropertyID = 0x04
+	PropIDBorderRadius   PropertyID = 0x05
+	PropIDPadding        PropertyID = 0x06
+	PropIDMargin         PropertyID = 0x07
+	PropIDTextContent    PropertyID = 0x08
+	PropIDFontSize       PropertyID = 0x09
+	PropIDFontWeight     PropertyID = 0x0A
+	PropIDTextAlignment  PropertyID = 0x0B
+	PropIDImageSource    PropertyID = 0x0C
+	PropIDOpacity        PropertyID = 0x0D
+	PropIDZIndex         PropertyID = 0x0E
+	PropIDVisibility     PropertyID = 0x0F
+	PropIDGap            PropertyID = 0x10
+	PropIDMinWidth       PropertyID = 0x11
+	PropIDMinHeight      PropertyID = 0x12
+	PropIDMaxWidth       PropertyID = 0x13
+	PropIDMaxHeight      PropertyID = 0x14
+	PropIDAspectRatio    PropertyID = 0x15
+	PropIDTransform      PropertyID = 0x16
+	PropIDShadow         PropertyID = 0x17
+	PropIDOverflow       PropertyID = 0x18
+	PropIDCustomDataBlob PropertyID = 0x19
+	PropIDLayoutFlags    PropertyID = 0x1A
+	PropIDFontFamily     PropertyID = 0x1B // Font resource (ValTypeResource) or font family name (ValTypeString)
+	PropIDLineHeight     PropertyID = 0x1C // Pixels (ValTypeShort) or relative to the font size (ValTypePercentage)
+	PropIDMaxLines       PropertyID = 0x1D // Maximum number of text lines before an ellipsis; 0 = unlimited
+	PropIDWindowWidth    PropertyID = 0x20
+	PropIDWindowHeight   PropertyID = 0x21
+	PropIDWindowTitle    PropertyID = 0x22
+	PropIDResizable      PropertyID = 0x23
+	PropIDKeepAspect     PropertyID = 0x24
+	PropIDScaleFactor    PropertyID = 0x25
+	PropIDIcon           PropertyID = 0x26
+	PropIDVersion        PropertyID = 0x27
+	PropIDAuthor         PropertyID = 0x28
 )
 
 type ValueType uint8
@@ -114,11 +129,18 @@ const (
 )
 
 const (
-	LayoutDirectionMask   uint8 = 0x03
-	LayoutAlignmentMask   uint8 = 0x0C
-	LayoutWrapBit         uint8 = 1 << 4
-	LayoutGrowBit         uint8 = 1 << 5
-	LayoutAbsoluteBit     uint8 = 1 << 6
+	LayoutDirectionMask uint8 = 0x03
+	LayoutAlignmentMask uint8 = 0x0C
+	LayoutWrapBit       uint8 = 1 << 4
+	LayoutGrowBit       uint8 = 1 << 5
+	LayoutAbsoluteBit   uint8 = 1 << 6
+)
+
+// Values of PropIDOverflow.
+const (
+	OverflowVisible uint8 = 0x00 // Children may draw outside the element
+	OverflowHidden  uint8 = 0x01 // Children are clipped to the element's padding box
+	OverflowScroll  uint8 = 0x02 // Like OverflowHidden, with scrolling
 )
 
 const (
@@ -155,22 +177,22 @@ const (
 )
 
 type Header struct {
-	Magic             [4]byte
-	Version           uint16
-	Flags             uint16
-	ElementCount      uint16
-	StyleCount        uint16
-	ComponentDefCount uint16
-	AnimationCount    uint16
-	StringCount       uint16
-	ResourceCount     uint16
-	ElementOffset     uint32
-	StyleOffset       uint32
+	Magic              [4]byte
+	Version            uint16
+	Flags              uint16
+	ElementCount       uint16
+	StyleCount         uint16
+	ComponentDefCount  uint16
+	AnimationCount     uint16
+	StringCount        uint16
+	ResourceCount      uint16
+	ElementOffset      uint32
+	StyleOffset        uint32
 	ComponentDefOffset uint32
-	AnimationOffset   uint32
-	StringOffset      uint32
-	ResourceOffset    uint32
-	TotalSize         uint32
+	AnimationOffset    uint32
+	StringOffset       uint32
+	ResourceOffset     uint32
+	TotalSize          uint32
 }
 
 const HeaderSize = 48
@@ -216,11 +238,85 @@ const EventFileEntrySize = 2
 
 type AnimationRef struct {
 	AnimationIndex uint8
-	Trigger        uint8
+	Trigger        AnimationTrigger
 }
 
 const AnimationRefSize = 2
 
+type AnimationTrigger uint8
+
+const (
+	AnimTriggerLoad  AnimationTrigger = 0x00
+	AnimTriggerHover AnimationTrigger = 0x01
+	AnimTriggerClick AnimationTrigger = 0x02
+	AnimTriggerFocus AnimationTrigger = 0x03
+)
+
+type AnimationType uint8
+
+const (
+	AnimTypeTransition AnimationType = 0x01
+	AnimTypeKeyframe   AnimationType = 0x02
+)
+
+type EasingType uint8
+
+const (
+	EasingLinear    EasingType = 0x00
+	EasingEaseIn    EasingType = 0x01
+	EasingEaseOut   EasingType = 0x02
+	EasingEaseInOut EasingType = 0x03
+)
+
+// AnimationTransition animates a single property from one value to another.
+// From and To are encoded like a Property value of type ValueType.
+type AnimationTransition struct {
+	PropertyID PropertyID
+	ValueType  ValueType
+	Size       uint8
+	From       []byte
+	To         []byte
+}
+
+// Keyframe sets properties at a point in time. Time 0-255 maps to 0%-100% of
+// the animation duration.
+type Keyframe struct {
+	Time          uint8
+	PropertyCount uint8
+	Properties    []Property
+}
+
+// Animation is one entry of the animation table. Transition is used when Type
+// is AnimTypeTransition, Keyframes when Type is AnimTypeKeyframe.
+//
+// For any other Type, Raw holds the bytes following the entry header as read
+// from the file. Such entries carry no length, so Raw runs to the end of the
+// table and also holds any entries after it; they are written back verbatim.
+type Animation struct {
+	ID            uint8
+	Type          AnimationType
+	DurationMs    uint16
+	Easing        EasingType
+	Transition    AnimationTransition
+	KeyframeCount uint8
+	Keyframes     []Keyframe
+	Raw           []byte
+}
+
+// TrackKey is one value of an AnimationTrack at normalized time T (0.0-1.0).
+type TrackKey struct {
+	T     float32
+	Value []byte
+}
+
+// AnimationTrack is the per-property view of an Animation: the keys for one
+// property, sorted by time. See Animation.Tracks.
+type AnimationTrack struct {
+	PropertyID PropertyID
+	ValueType  ValueType
+	Keys       []TrackKey
+}
+
 type ChildRef struct {
 	ChildOffset uint16
 }
@@ -268,7 +364,7 @@ type Document struct {
 	Events               [][]EventFileEntry
 	ComponentDefinitions []KrbComponentDefinition
 	Styles               []Style
-	Animations           []byte
+	Animations           []Animation
 	Strings              []string
 	Resources            []Resource
 	ChildRefs            [][]ChildRef
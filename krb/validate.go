@@ -0,0 +1,738 @@
+// krb/validate.go
+
+package krb
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Severity ranks a validation Diagnostic.
+type Severity uint8
+
+const (
+	SeverityInfo Severity = iota
+	SeverityWarning
+	SeverityError
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityInfo:
+		return "info"
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	default:
+		return fmt.Sprintf("Severity(%d)", uint8(s))
+	}
+}
+
+// Section identifies the part of a KRB document a Diagnostic refers to.
+type Section uint8
+
+const (
+	SectionHeader Section = iota
+	SectionElements
+	SectionStyles
+	SectionComponentDefs
+	SectionAnimations
+	SectionStrings
+	SectionResources
+)
+
+func (s Section) String() string {
+	switch s {
+	case SectionHeader:
+		return "header"
+	case SectionElements:
+		return "elements"
+	case SectionStyles:
+		return "styles"
+	case SectionComponentDefs:
+		return "component_defs"
+	case SectionAnimations:
+		return "animations"
+	case SectionStrings:
+		return "strings"
+	case SectionResources:
+		return "resources"
+	default:
+		return fmt.Sprintf("Section(%d)", uint8(s))
+	}
+}
+
+// NoIndex is used for Diagnostic.Index when the problem is not tied to a
+// particular element, style, component definition or resource.
+const NoIndex = -1
+
+// Diagnostic describes one problem found by Validate.
+type Diagnostic struct {
+	Severity Severity
+	Section  Section
+	// Index is the element index for SectionElements, and the style, component
+	// definition or resource index for the other sections. NoIndex if not applicable.
+	Index int
+	// Offset is the byte offset in the file of the offending item, or 0 when it
+	// cannot be determined (e.g. for documents that were never encoded).
+	Offset  uint32
+	Message string
+}
+
+func (d Diagnostic) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s: %s", d.Severity, d.Section)
+	if d.Index != NoIndex {
+		fmt.Fprintf(&sb, "[%d]", d.Index)
+	}
+	if d.Offset != 0 {
+		fmt.Fprintf(&sb, " @0x%X", d.Offset)
+	}
+	sb.WriteString(": ")
+	sb.WriteString(d.Message)
+	return sb.String()
+}
+
+// ValidationReport collects the diagnostics produced by Validate.
+type ValidationReport struct {
+	Diagnostics []Diagnostic
+}
+
+// HasErrors reports whether any diagnostic has SeverityError.
+func (r *ValidationReport) HasErrors() bool {
+	for _, d := range r.Diagnostics {
+		if d.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// Filter returns the diagnostics at or above the given severity.
+func (r *ValidationReport) Filter(minSeverity Severity) []Diagnostic {
+	var out []Diagnostic
+	for _, d := range r.Diagnostics {
+		if d.Severity >= minSeverity {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+func (r *ValidationReport) String() string {
+	lines := make([]string, len(r.Diagnostics))
+	for i, d := range r.Diagnostics {
+		lines[i] = d.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (r *ValidationReport) add(severity Severity, section Section, index int, offset uint32, format string, args ...any) {
+	r.Diagnostics = append(r.Diagnostics, Diagnostic{
+		Severity: severity,
+		Section:  section,
+		Index:    index,
+		Offset:   offset,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// propertyValueTypes lists the value types accepted for each standard property.
+// Properties not listed here are only checked for a consistent Size.
+var propertyValueTypes = map[PropertyID][]ValueType{
+	PropIDBgColor:       {ValTypeColor},
+	PropIDFgColor:       {ValTypeColor},
+	PropIDBorderColor:   {ValTypeColor},
+	PropIDBorderWidth:   {ValTypeByte, ValTypeEdgeInsets},
+	PropIDBorderRadius:  {ValTypeByte, ValTypeShort, ValTypeEdgeInsets},
+	PropIDPadding:       {ValTypeByte, ValTypeEdgeInsets},
+	PropIDMargin:        {ValTypeByte, ValTypeEdgeInsets},
+	PropIDTextContent:   {ValTypeString},
+	PropIDFontSize:      {ValTypeShort},
+	PropIDFontWeight:    {ValTypeEnum, ValTypeByte},
+	PropIDTextAlignment: {ValTypeEnum, ValTypeByte},
+	PropIDImageSource:   {ValTypeResource, ValTypeString},
+	PropIDOpacity:       {ValTypeByte, ValTypePercentage},
+	PropIDZIndex:        {ValTypeShort, ValTypeByte},
+	PropIDVisibility:    {ValTypeByte, ValTypeEnum},
+	PropIDGap:           {ValTypeShort},
+	PropIDMinWidth:      {ValTypeShort, ValTypePercentage},
+	PropIDMinHeight:     {ValTypeShort, ValTypePercentage},
+	PropIDMaxWidth:      {ValTypeShort, ValTypePercentage},
+	PropIDMaxHeight:     {ValTypeShort, ValTypePercentage},
+	PropIDAspectRatio:   {ValTypeShort, ValTypePercentage},
+	PropIDOverflow:      {ValTypeEnum, ValTypeByte},
+	PropIDLayoutFlags:   {ValTypeByte},
+	PropIDFontFamily:    {ValTypeResource, ValTypeString},
+	PropIDLineHeight:    {ValTypeShort, ValTypePercentage},
+	PropIDMaxLines:      {ValTypeByte},
+	PropIDWindowWidth:   {ValTypeShort},
+	PropIDWindowHeight:  {ValTypeShort},
+	PropIDWindowTitle:   {ValTypeString},
+	PropIDResizable:     {ValTypeByte},
+	PropIDKeepAspect:    {ValTypeByte},
+	PropIDScaleFactor:   {ValTypeShort},
+	PropIDIcon:          {ValTypeResource, ValTypeString},
+	PropIDVersion:       {ValTypeString},
+	PropIDAuthor:        {ValTypeString},
+}
+
+// expectedValueSizes returns the valid encoded sizes for a value type, or nil
+// if the type is variable-length.
+func expectedValueSizes(valueType ValueType, flags uint16) []int {
+	switch valueType {
+	case ValTypeNone:
+		return []int{0}
+	case ValTypeByte, ValTypeString, ValTypeResource, ValTypeEnum:
+		return []int{1}
+	case ValTypeShort, ValTypePercentage:
+		return []int{2}
+	case ValTypeColor:
+		if flags&FlagExtendedColor != 0 {
+			return []int{4}
+		}
+		return []int{1}
+	case ValTypeEdgeInsets:
+		return []int{4}
+	case ValTypeVector:
+		return []int{4}
+	case ValTypeRect:
+		return []int{8}
+	default:
+		return nil
+	}
+}
+
+// Validate checks doc for structural problems and returns every issue found.
+// It never modifies the document. Offsets are reported relative to the file
+// the document was read from (or will be written to, for built documents) and
+// are 0 when ElementStartOffsets is unavailable.
+func Validate(doc *Document) *ValidationReport {
+	report := &ValidationReport{}
+	if doc == nil {
+		report.add(SeverityError, SectionHeader, NoIndex, 0, "document is nil")
+		return report
+	}
+	v := &validator{doc: doc, report: report}
+	v.checkHeader()
+	v.checkElements()
+	v.checkTree()
+	v.checkStyles()
+	v.checkComponentDefs()
+	v.checkAnimations()
+	v.checkResources()
+	return report
+}
+
+type validator struct {
+	doc    *Document
+	report *ValidationReport
+}
+
+func (v *validator) elementOffset(i int) uint32 {
+	if i >= 0 && i < len(v.doc.ElementStartOffsets) {
+		return v.doc.ElementStartOffsets[i]
+	}
+	return 0
+}
+
+func (v *validator) checkHeader() {
+	doc, hdr := v.doc, &v.doc.Header
+	if hdr.Magic != MagicNumber {
+		v.report.add(SeverityError, SectionHeader, NoIndex, 0, "invalid magic number %v", hdr.Magic)
+	}
+	if hdr.Version != ExpectedVersion {
+		v.report.add(SeverityWarning, SectionHeader, NoIndex, 4, "version %d.%d differs from supported %d.%d",
+			uint8(hdr.Version&0x00FF), uint8(hdr.Version>>8), SpecVersionMajor, SpecVersionMinor)
+	}
+
+	if hdr.Flags&FlagCompressed != 0 && hdr.Codec() != CodecDeflate {
+		v.report.add(SeverityError, SectionHeader, NoIndex, 6, "FlagCompressed is set with unsupported codec %s", hdr.Codec())
+	} else if hdr.Flags&FlagCompressed == 0 && hdr.Codec() != CodecNone {
+		v.report.add(SeverityWarning, SectionHeader, NoIndex, 6, "codec %s is recorded but FlagCompressed is not set", hdr.Codec())
+	}
+
+	counts := []struct {
+		name    string
+		section Section
+		header  uint16
+		actual  int
+	}{
+		{"element", SectionElements, hdr.ElementCount, len(doc.Elements)},
+		{"style", SectionStyles, hdr.StyleCount, len(doc.Styles)},
+		{"component definition", SectionComponentDefs, hdr.ComponentDefCount, len(doc.ComponentDefinitions)},
+		{"animation", SectionAnimations, hdr.AnimationCount, animationEntryCount(doc)},
+		{"string", SectionStrings, hdr.StringCount, len(doc.Strings)},
+		{"resource", SectionResources, hdr.ResourceCount, len(doc.Resources)},
+	}
+	for _, c := range counts {
+		if int(c.header) != c.actual {
+			v.report.add(SeverityError, c.section, NoIndex, 0, "header %s count %d does not match %d decoded entries",
+				c.name, c.header, c.actual)
+		}
+	}
+
+	flagChecks := []struct {
+		name    string
+		flag    uint16
+		present bool
+		section Section
+	}{
+		{"FlagHasStyles", FlagHasStyles, len(doc.Styles) > 0, SectionStyles},
+		{"FlagHasComponentDefs", FlagHasComponentDefs, len(doc.ComponentDefinitions) > 0, SectionComponentDefs},
+		{"FlagHasAnimations", FlagHasAnimations, hdr.AnimationCount > 0, SectionAnimations},
+		{"FlagHasResources", FlagHasResources, len(doc.Resources) > 0, SectionResources},
+	}
+	for _, f := range flagChecks {
+		if f.present && hdr.Flags&f.flag == 0 {
+			v.report.add(SeverityWarning, f.section, NoIndex, 6, "section is present but %s is not set", f.name)
+		}
+	}
+	if hdr.Flags&FlagHasApp != 0 && (len(doc.Elements) == 0 || doc.Elements[0].Type != ElemTypeApp) {
+		v.report.add(SeverityError, SectionElements, 0, v.elementOffset(0), "FlagHasApp is set but element 0 is not an App element")
+	}
+
+	sections := []struct {
+		name    string
+		section Section
+		offset  uint32
+		used    bool
+	}{
+		{"element", SectionElements, hdr.ElementOffset, hdr.ElementCount > 0},
+		{"style", SectionStyles, hdr.StyleOffset, hdr.StyleCount > 0},
+		{"component definition", SectionComponentDefs, hdr.ComponentDefOffset, hdr.ComponentDefCount > 0},
+		{"animation", SectionAnimations, hdr.AnimationOffset, hdr.AnimationCount > 0},
+		{"string", SectionStrings, hdr.StringOffset, hdr.StringCount > 0},
+		{"resource", SectionResources, hdr.ResourceOffset, hdr.ResourceCount > 0},
+	}
+	for _, s := range sections {
+		if !s.used {
+			continue
+		}
+		if s.offset < HeaderSize {
+			v.report.add(SeverityError, s.section, NoIndex, s.offset, "%s section offset %d overlaps the header", s.name, s.offset)
+		} else if hdr.TotalSize != 0 && s.offset >= hdr.TotalSize {
+			v.report.add(SeverityError, s.section, NoIndex, s.offset, "%s section offset %d is past TotalSize %d", s.name, s.offset, hdr.TotalSize)
+		}
+	}
+
+	if len(doc.ElementStartOffsets) != 0 && len(doc.ElementStartOffsets) != len(doc.Elements) {
+		v.report.add(SeverityError, SectionElements, NoIndex, 0, "ElementStartOffsets has %d entries for %d elements",
+			len(doc.ElementStartOffsets), len(doc.Elements))
+	}
+}
+
+func (v *validator) checkStringIndex(section Section, index int, offset uint32, what string, strIdx uint8) {
+	if int(strIdx) >= len(v.doc.Strings) {
+		v.report.add(SeverityError, section, index, offset, "%s string index %d is out of range (string table has %d entries)",
+			what, strIdx, len(v.doc.Strings))
+	}
+}
+
+func (v *validator) styleExists(styleID uint8) bool {
+	for i := range v.doc.Styles {
+		if v.doc.Styles[i].ID == styleID {
+			return true
+		}
+	}
+	return false
+}
+
+// checkProperties validates a standard property list. base is the file offset of
+// the first property header, or 0 if unknown.
+func (v *validator) checkProperties(section Section, index int, base uint32, owner string, props []Property) {
+	offset := base
+	for j := range props {
+		prop := &props[j]
+		propOffset := offset
+		if base != 0 {
+			offset += 3 + uint32(len(prop.Value))
+		}
+		what := fmt.Sprintf("%s property %d (ID 0x%02X)", owner, j, uint8(prop.ID))
+
+		if int(prop.Size) != len(prop.Value) {
+			v.report.add(SeverityError, section, index, propOffset, "%s declares Size %d but has %d value bytes", what, prop.Size, len(prop.Value))
+		}
+		if allowed, known := propertyValueTypes[prop.ID]; known && !containsValueType(allowed, prop.ValueType) {
+			v.report.add(SeverityError, section, index, propOffset, "%s has ValueType 0x%02X, expected one of %s",
+				what, uint8(prop.ValueType), formatValueTypes(allowed))
+		} else if !known && prop.ID != PropIDCustomDataBlob {
+			v.report.add(SeverityWarning, section, index, propOffset, "%s is not a known property ID", what)
+		}
+		if sizes := expectedValueSizes(prop.ValueType, v.doc.Header.Flags); sizes != nil && !containsInt(sizes, len(prop.Value)) {
+			v.report.add(SeverityError, section, index, propOffset, "%s has %d value bytes, ValueType 0x%02X requires %v",
+				what, len(prop.Value), uint8(prop.ValueType), sizes)
+		}
+
+		switch prop.ValueType {
+		case ValTypeString:
+			if len(prop.Value) == 1 {
+				v.checkStringIndex(section, index, propOffset, what, prop.Value[0])
+			}
+		case ValTypeResource:
+			if len(prop.Value) == 1 && int(prop.Value[0]) >= len(v.doc.Resources) {
+				v.report.add(SeverityError, section, index, propOffset, "%s resource index %d is past the resource table (%d entries)",
+					what, prop.Value[0], len(v.doc.Resources))
+			}
+		}
+	}
+}
+
+func containsValueType(list []ValueType, vt ValueType) bool {
+	for _, t := range list {
+		if t == vt {
+			return true
+		}
+	}
+	return false
+}
+
+func containsInt(list []int, n int) bool {
+	for _, x := range list {
+		if x == n {
+			return true
+		}
+	}
+	return false
+}
+
+func formatValueTypes(list []ValueType) string {
+	parts := make([]string, len(list))
+	for i, vt := range list {
+		parts[i] = fmt.Sprintf("0x%02X", uint8(vt))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (v *validator) checkElements() {
+	doc := v.doc
+	animationCount := animationEntryCount(doc)
+	for i := range doc.Elements {
+		elemHdr := &doc.Elements[i]
+		start := v.elementOffset(i)
+
+		if elemHdr.ID != 0 {
+			v.checkStringIndex(SectionElements, i, start+1, "element ID", elemHdr.ID)
+		}
+		if elemHdr.StyleID != 0 && !v.styleExists(elemHdr.StyleID) {
+			v.report.add(SeverityError, SectionElements, i, start+11, "StyleID %d has no matching style", elemHdr.StyleID)
+		}
+		if elemHdr.Type == ElemTypeApp && i != 0 {
+			v.report.add(SeverityWarning, SectionElements, i, start, "App element found at index %d; it is expected to be the first element", i)
+		}
+
+		props := sliceAt(doc.Properties, i)
+		customProps := sliceAt(doc.CustomProperties, i)
+		events := sliceAt(doc.Events, i)
+		animRefs := sliceAt(doc.AnimationRefs, i)
+		childRefs := sliceAt(doc.ChildRefs, i)
+		countChecks := []struct {
+			name   string
+			header uint8
+			actual int
+		}{
+			{"PropertyCount", elemHdr.PropertyCount, len(props)},
+			{"CustomPropCount", elemHdr.CustomPropCount, len(customProps)},
+			{"EventCount", elemHdr.EventCount, len(events)},
+			{"AnimationCount", elemHdr.AnimationCount, len(animRefs)},
+			{"ChildCount", elemHdr.ChildCount, len(childRefs)},
+		}
+		for _, c := range countChecks {
+			if int(c.header) != c.actual {
+				v.report.add(SeverityError, SectionElements, i, start, "%s %d does not match %d decoded entries", c.name, c.header, c.actual)
+			}
+		}
+
+		propBase := uint32(0)
+		if start != 0 {
+			propBase = start + ElementHeaderSize
+		}
+		v.checkProperties(SectionElements, i, propBase, "element", props)
+
+		offset := propBase
+		if offset != 0 {
+			for _, prop := range props {
+				offset += 3 + uint32(len(prop.Value))
+			}
+		}
+		for j, cprop := range customProps {
+			what := fmt.Sprintf("custom property %d", j)
+			v.checkStringIndex(SectionElements, i, offset, what+" key", cprop.KeyIndex)
+			if int(cprop.Size) != len(cprop.Value) {
+				v.report.add(SeverityError, SectionElements, i, offset, "%s declares Size %d but has %d value bytes", what, cprop.Size, len(cprop.Value))
+			}
+			if cprop.ValueType == ValTypeString && len(cprop.Value) == 1 {
+				v.checkStringIndex(SectionElements, i, offset, what+" value", cprop.Value[0])
+			}
+			if cprop.ValueType == ValTypeResource && len(cprop.Value) == 1 && int(cprop.Value[0]) >= len(doc.Resources) {
+				v.report.add(SeverityError, SectionElements, i, offset, "%s resource index %d is past the resource table (%d entries)",
+					what, cprop.Value[0], len(doc.Resources))
+			}
+			if offset != 0 {
+				offset += 3 + uint32(len(cprop.Value))
+			}
+		}
+
+		for j, event := range events {
+			if event.EventType == EventTypeNone || event.EventType > EventTypeCustom {
+				v.report.add(SeverityWarning, SectionElements, i, offset, "event %d has unknown EventType 0x%02X", j, uint8(event.EventType))
+			}
+			v.checkStringIndex(SectionElements, i, offset, fmt.Sprintf("event %d callback", j), event.CallbackID)
+			if offset != 0 {
+				offset += EventFileEntrySize
+			}
+		}
+
+		for j, animRef := range animRefs {
+			if int(animRef.AnimationIndex) >= animationCount {
+				v.report.add(SeverityError, SectionElements, i, offset, "animation ref %d index %d is past the animation table (%d entries)",
+					j, animRef.AnimationIndex, animationCount)
+			}
+			if offset != 0 {
+				offset += AnimationRefSize
+			}
+		}
+	}
+}
+
+// checkTree verifies that every ChildRef lands on an element start, that no
+// element has more than one parent, and that the child graph has no cycles.
+func (v *validator) checkTree() {
+	doc := v.doc
+	if len(doc.ElementStartOffsets) != len(doc.Elements) {
+		for i := range doc.Elements {
+			if len(sliceAt(doc.ChildRefs, i)) > 0 {
+				v.report.add(SeverityError, SectionElements, i, 0, "ChildRefs cannot be resolved without ElementStartOffsets")
+				return
+			}
+		}
+		return
+	}
+
+	offsetToIndex := make(map[uint32]int, len(doc.ElementStartOffsets))
+	for i, off := range doc.ElementStartOffsets {
+		offsetToIndex[off] = i
+	}
+
+	children := make([][]int, len(doc.Elements))
+	parentOf := make([]int, len(doc.Elements))
+	for i := range parentOf {
+		parentOf[i] = NoIndex
+	}
+	for i := range doc.Elements {
+		childRefs := sliceAt(doc.ChildRefs, i)
+		refOffset := doc.ElementStartOffsets[i] + elementBlockSize(doc, i) - uint32(len(childRefs))*ChildRefSize
+		for j, childRef := range childRefs {
+			at := refOffset + uint32(j)*ChildRefSize
+			target := doc.ElementStartOffsets[i] + uint32(childRef.ChildOffset)
+			childIdx, found := offsetToIndex[target]
+			if !found {
+				v.report.add(SeverityError, SectionElements, i, at, "ChildRef %d offset %d (abs 0x%X) does not land on an element start",
+					j, childRef.ChildOffset, target)
+				continue
+			}
+			if childIdx == i {
+				v.report.add(SeverityError, SectionElements, i, at, "ChildRef %d points at the element itself", j)
+				continue
+			}
+			if parentOf[childIdx] != NoIndex && parentOf[childIdx] != i {
+				v.report.add(SeverityError, SectionElements, childIdx, doc.ElementStartOffsets[childIdx],
+					"element is referenced as a child by both element %d and element %d", parentOf[childIdx], i)
+			}
+			parentOf[childIdx] = i
+			children[i] = append(children[i], childIdx)
+		}
+	}
+
+	const (
+		unvisited = iota
+		inProgress
+		done
+	)
+	state := make([]uint8, len(doc.Elements))
+	var visit func(i int, path []int) bool
+	visit = func(i int, path []int) bool {
+		state[i] = inProgress
+		path = append(path, i)
+		for _, c := range children[i] {
+			switch state[c] {
+			case inProgress:
+				cycle := []string{}
+				for k := len(path) - 1; k >= 0; k-- {
+					cycle = append([]string{fmt.Sprint(path[k])}, cycle...)
+					if path[k] == c {
+						break
+					}
+				}
+				v.report.add(SeverityError, SectionElements, i, doc.ElementStartOffsets[i], "child references form a cycle: %s -> %d",
+					strings.Join(cycle, " -> "), c)
+				return true
+			case unvisited:
+				if visit(c, path) {
+					return true
+				}
+			}
+		}
+		state[i] = done
+		return false
+	}
+	for i := range doc.Elements {
+		if state[i] == unvisited {
+			visit(i, nil)
+		}
+	}
+}
+
+func (v *validator) checkStyles() {
+	doc := v.doc
+	offset := uint32(0)
+	if len(doc.Styles) > 0 && doc.Header.StyleOffset >= HeaderSize {
+		offset = doc.Header.StyleOffset
+	}
+	seen := make(map[uint8]int)
+	for i := range doc.Styles {
+		style := &doc.Styles[i]
+		if style.ID == 0 {
+			v.report.add(SeverityError, SectionStyles, i, offset, "style ID 0 is reserved for \"no style\"")
+		} else if prev, dup := seen[style.ID]; dup {
+			v.report.add(SeverityError, SectionStyles, i, offset, "style ID %d is also used by style %d", style.ID, prev)
+		} else {
+			seen[style.ID] = i
+		}
+		v.checkStringIndex(SectionStyles, i, offset, "style name", style.NameIndex)
+		if int(style.PropertyCount) != len(style.Properties) {
+			v.report.add(SeverityError, SectionStyles, i, offset, "PropertyCount %d does not match %d decoded properties",
+				style.PropertyCount, len(style.Properties))
+		}
+		propBase := uint32(0)
+		if offset != 0 {
+			propBase = offset + 3
+		}
+		v.checkProperties(SectionStyles, i, propBase, "style", style.Properties)
+		if offset != 0 {
+			offset = propBase
+			for _, prop := range style.Properties {
+				offset += 3 + uint32(len(prop.Value))
+			}
+		}
+	}
+}
+
+func (v *validator) checkComponentDefs() {
+	doc := v.doc
+	offset := uint32(0)
+	if len(doc.ComponentDefinitions) > 0 && doc.Header.ComponentDefOffset >= HeaderSize {
+		offset = doc.Header.ComponentDefOffset
+	}
+	for i := range doc.ComponentDefinitions {
+		compDef := &doc.ComponentDefinitions[i]
+		v.checkStringIndex(SectionComponentDefs, i, offset, "component name", compDef.NameIndex)
+		if int(compDef.PropertyDefCount) != len(compDef.PropertyDefinitions) {
+			v.report.add(SeverityError, SectionComponentDefs, i, offset, "PropertyDefCount %d does not match %d decoded definitions",
+				compDef.PropertyDefCount, len(compDef.PropertyDefinitions))
+		}
+		propDefOffset := uint32(0)
+		if offset != 0 {
+			propDefOffset = offset + 2
+		}
+		for j, propDef := range compDef.PropertyDefinitions {
+			v.checkStringIndex(SectionComponentDefs, i, propDefOffset, fmt.Sprintf("property definition %d name", j), propDef.NameIndex)
+			if int(propDef.DefaultValueSize) != len(propDef.DefaultValueData) {
+				v.report.add(SeverityError, SectionComponentDefs, i, propDefOffset, "property definition %d declares DefaultValueSize %d but has %d bytes",
+					j, propDef.DefaultValueSize, len(propDef.DefaultValueData))
+			}
+			if propDefOffset != 0 {
+				propDefOffset += 3 + uint32(len(propDef.DefaultValueData))
+			}
+		}
+		if len(compDef.RootElementTemplateData) == 0 {
+			v.report.add(SeverityWarning, SectionComponentDefs, i, offset, "component has no root element template")
+		} else if len(compDef.RootElementTemplateData) < ElementHeaderSize {
+			v.report.add(SeverityError, SectionComponentDefs, i, propDefOffset, "root element template is %d bytes, shorter than an element header",
+				len(compDef.RootElementTemplateData))
+		}
+		if offset != 0 {
+			offset += componentDefSize(compDef)
+		}
+	}
+}
+
+func (v *validator) checkAnimations() {
+	doc := v.doc
+	offset := uint32(0)
+	if len(doc.Animations) > 0 && doc.Header.AnimationOffset >= HeaderSize {
+		offset = doc.Header.AnimationOffset
+	}
+	for i := range doc.Animations {
+		anim := &doc.Animations[i]
+		if anim.Easing > EasingEaseInOut {
+			v.report.add(SeverityWarning, SectionAnimations, i, offset, "unknown easing 0x%02X, linear is used", uint8(anim.Easing))
+		}
+		if anim.DurationMs == 0 {
+			v.report.add(SeverityWarning, SectionAnimations, i, offset, "animation has zero duration")
+		}
+		switch anim.Type {
+		case AnimTypeTransition:
+			tr := &anim.Transition
+			if len(tr.From) != int(tr.Size) || len(tr.To) != int(tr.Size) {
+				v.report.add(SeverityError, SectionAnimations, i, offset, "transition declares Size %d but has %d/%d value bytes",
+					tr.Size, len(tr.From), len(tr.To))
+			}
+			v.checkProperties(SectionAnimations, i, 0, "transition", []Property{
+				{ID: tr.PropertyID, ValueType: tr.ValueType, Size: tr.Size, Value: tr.From},
+			})
+		case AnimTypeKeyframe:
+			if int(anim.KeyframeCount) != len(anim.Keyframes) {
+				v.report.add(SeverityError, SectionAnimations, i, offset, "KeyframeCount %d does not match %d decoded keyframes",
+					anim.KeyframeCount, len(anim.Keyframes))
+			}
+			for k := range anim.Keyframes {
+				kf := &anim.Keyframes[k]
+				if k > 0 && kf.Time < anim.Keyframes[k-1].Time {
+					v.report.add(SeverityWarning, SectionAnimations, i, offset, "keyframe %d time %d is earlier than the previous keyframe", k, kf.Time)
+				}
+				if int(kf.PropertyCount) != len(kf.Properties) {
+					v.report.add(SeverityError, SectionAnimations, i, offset, "keyframe %d PropertyCount %d does not match %d decoded properties",
+						k, kf.PropertyCount, len(kf.Properties))
+				}
+				v.checkProperties(SectionAnimations, i, 0, fmt.Sprintf("keyframe %d", k), kf.Properties)
+			}
+		default:
+			v.report.add(SeverityWarning, SectionAnimations, i, offset, "unknown animation type 0x%02X, %d bytes kept undecoded",
+				uint8(anim.Type), len(anim.Raw))
+		}
+		if offset != 0 {
+			offset += animationSize(anim)
+		}
+	}
+}
+
+func (v *validator) checkResources() {
+	doc := v.doc
+	offset := uint32(0)
+	if len(doc.Resources) > 0 && doc.Header.ResourceOffset >= HeaderSize {
+		offset = doc.Header.ResourceOffset + 2 // skip the table's count prefix
+	}
+	for i := range doc.Resources {
+		res := &doc.Resources[i]
+		if res.Type == ResTypeNone || res.Type > ResTypeCustom {
+			v.report.add(SeverityWarning, SectionResources, i, offset, "unknown resource type 0x%02X", uint8(res.Type))
+		}
+		v.checkStringIndex(SectionResources, i, offset, "resource name", res.NameIndex)
+		switch res.Format {
+		case ResFormatExternal:
+			v.checkStringIndex(SectionResources, i, offset, "external resource data", res.DataStringIndex)
+		case ResFormatInline:
+			if int(res.InlineDataSize) != len(res.InlineData) {
+				v.report.add(SeverityError, SectionResources, i, offset, "InlineDataSize %d does not match %d data bytes",
+					res.InlineDataSize, len(res.InlineData))
+			}
+		default:
+			v.report.add(SeverityError, SectionResources, i, offset, "unknown resource format 0x%02X", uint8(res.Format))
+		}
+		if offset != 0 {
+			offset += resourceSize(res)
+		}
+	}
+}
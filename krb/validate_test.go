@@ -0,0 +1,112 @@
+package krb
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+// findDiagnostic returns the first diagnostic in section whose message
+// contains substr.
+func findDiagnostic(r *ValidationReport, section Section, substr string) (Diagnostic, bool) {
+	for _, d := range r.Diagnostics {
+		if d.Section == section && strings.Contains(d.Message, substr) {
+			return d, true
+		}
+	}
+	return Diagnostic{}, false
+}
+
+func TestValidateExamplesHaveNoErrors(t *testing.T) {
+	for _, path := range exampleFiles {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r := Validate(decode(t, data)); r.HasErrors() {
+			t.Errorf("%s:\n%s", path, r)
+		}
+	}
+}
+
+func TestValidateElementDiagnostics(t *testing.T) {
+	b := NewBuilder()
+	app := b.AddElement(ElemTypeApp)
+	app.AddChild(ElemTypeText).Text("hello")
+	doc, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc = decode(t, encode(t, doc))
+	doc.Elements[1].StyleID = 99
+	doc.ChildRefs[0][0].ChildOffset++
+
+	r := Validate(doc)
+	d, ok := findDiagnostic(r, SectionElements, "StyleID 99")
+	if !ok {
+		t.Fatalf("missing StyleID diagnostic:\n%s", r)
+	}
+	if d.Severity != SeverityError || d.Index != 1 || d.Offset != doc.ElementStartOffsets[1]+11 {
+		t.Errorf("StyleID diagnostic = %+v, want an error for element 1 at 0x%X", d, doc.ElementStartOffsets[1]+11)
+	}
+	if _, ok := findDiagnostic(r, SectionElements, "ChildRef 0"); !ok {
+		t.Errorf("missing diagnostic for the broken ChildRef:\n%s", r)
+	}
+}
+
+func TestValidateComponentDefOffsets(t *testing.T) {
+	b := NewBuilder()
+	b.AddElement(ElemTypeApp)
+	name := b.String("Row")
+	doc, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := make([]byte, ElementHeaderSize)
+	template[0] = byte(ElemTypeContainer)
+	doc.ComponentDefinitions = []KrbComponentDefinition{
+		{NameIndex: name, PropertyDefinitions: []KrbPropertyDefinition{{NameIndex: name, DefaultValueData: []byte{1, 2}}}, RootElementTemplateData: template},
+		{NameIndex: name, PropertyDefinitions: []KrbPropertyDefinition{{NameIndex: name}, {NameIndex: 200}}, RootElementTemplateData: template[:4]},
+	}
+	encode(t, doc) // Assigns the section offsets; the short template could not be read back
+
+	second := doc.Header.ComponentDefOffset + componentDefSize(&doc.ComponentDefinitions[0])
+	r := Validate(doc)
+	d, ok := findDiagnostic(r, SectionComponentDefs, "property definition 1 name")
+	if !ok {
+		t.Fatalf("missing diagnostic for the bad property definition name:\n%s", r)
+	}
+	if want := second + 2 + 3; d.Index != 1 || d.Offset != want {
+		t.Errorf("property definition diagnostic = %+v, want component 1 at 0x%X", d, want)
+	}
+	d, ok = findDiagnostic(r, SectionComponentDefs, "root element template")
+	if !ok {
+		t.Fatalf("missing diagnostic for the short template:\n%s", r)
+	}
+	if want := second + 2 + 3 + 3; d.Offset != want {
+		t.Errorf("template diagnostic at 0x%X, want 0x%X", d.Offset, want)
+	}
+}
+
+func TestValidateResourceOffsets(t *testing.T) {
+	b := NewBuilder()
+	b.AddElement(ElemTypeApp)
+	b.AddInlineResource(ResTypeImage, "icon", []byte{1, 2, 3})
+	b.AddExternalResource(ResTypeFont, "font.ttf")
+	doc, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc = decode(t, encode(t, doc))
+	doc.Resources[1].DataStringIndex = 200
+
+	r := Validate(doc)
+	d, ok := findDiagnostic(r, SectionResources, "external resource data")
+	if !ok {
+		t.Fatalf("missing diagnostic for the bad string index:\n%s", r)
+	}
+	want := doc.Header.ResourceOffset + 2 + resourceSize(&doc.Resources[0])
+	if d.Index != 1 || d.Offset != want {
+		t.Errorf("resource diagnostic = %+v, want resource 1 at 0x%X", d, want)
+	}
+}
@@ -0,0 +1,539 @@
+// krb/writer.go
+
+package krb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// WriteDocument serializes doc as a KRB v0.4 file into w, starting at offset 0.
+// Header counts, section offsets and TotalSize are recomputed from the document
+// contents, and ChildRef offsets are re-targeted to the new element positions.
+// On success doc.Header, doc.ElementStartOffsets and the per-element counts
+// describe the bytes that were written, so ReadDocument on the output yields an
+// equivalent Document.
+//
+// If doc.Header has FlagCompressed set (see Header.SetCompression), everything
+// after the header is compressed with the recorded codec; DEFLATE is used when
+// no codec is recorded.
+func WriteDocument(w io.WriteSeeker, doc *Document) error {
+	if doc == nil {
+		return errors.New("krb write: document is nil")
+	}
+	if err := syncDocumentCounts(doc); err != nil {
+		return err
+	}
+
+	childIndices, err := resolveChildIndices(doc)
+	if err != nil {
+		return err
+	}
+	startOffsets := computeElementStartOffsets(doc)
+	if err := assignChildRefs(doc, childIndices, startOffsets); err != nil {
+		return err
+	}
+	doc.ElementStartOffsets = startOffsets
+	computeSectionOffsets(doc)
+	if doc.Header.Flags&FlagCompressed != 0 && doc.Header.Codec() == CodecNone {
+		doc.Header.SetCompression(CodecDeflate)
+	}
+
+	var buf bytes.Buffer
+	buf.Grow(int(doc.Header.TotalSize))
+	writeHeader(&buf, &doc.Header)
+	for i := range doc.Elements {
+		writeElementBlock(&buf, doc, i)
+	}
+	for i := range doc.Styles {
+		writeStyle(&buf, &doc.Styles[i])
+	}
+	for i := range doc.ComponentDefinitions {
+		writeComponentDefinition(&buf, &doc.ComponentDefinitions[i])
+	}
+	for i := range doc.Animations {
+		writeAnimation(&buf, &doc.Animations[i])
+	}
+	if len(doc.Strings) > 0 {
+		writeStringTable(&buf, doc.Strings)
+	}
+	if len(doc.Resources) > 0 {
+		writeResourceTable(&buf, doc.Resources)
+	}
+
+	if uint32(buf.Len()) != doc.Header.TotalSize {
+		return fmt.Errorf("krb write: encoded size %d does not match computed TotalSize %d", buf.Len(), doc.Header.TotalSize)
+	}
+
+	out := buf.Bytes()
+	if doc.Header.Flags&FlagCompressed != 0 {
+		if out, err = compressSections(out, &doc.Header); err != nil {
+			return err
+		}
+	}
+
+	if _, err := w.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("krb write: failed to seek to start: %w", err)
+	}
+	if _, err := w.Write(out); err != nil {
+		return fmt.Errorf("krb write: failed to write document (%d bytes): %w", len(out), err)
+	}
+	return nil
+}
+
+// syncDocumentCounts makes every count field in the document agree with the
+// slices it describes, and rejects values that cannot be encoded.
+func syncDocumentCounts(doc *Document) error {
+	if len(doc.Elements) > 0xFFFF {
+		return fmt.Errorf("krb write: too many elements (%d)", len(doc.Elements))
+	}
+	for i := range doc.Elements {
+		elemHdr := &doc.Elements[i]
+
+		props := sliceAt(doc.Properties, i)
+		if len(props) > 0xFF {
+			return fmt.Errorf("krb write: element %d has too many properties (%d)", i, len(props))
+		}
+		for j := range props {
+			if err := syncValueSize(&props[j].Size, props[j].Value); err != nil {
+				return fmt.Errorf("krb write: element %d, prop %d: %w", i, j, err)
+			}
+		}
+		customProps := sliceAt(doc.CustomProperties, i)
+		if len(customProps) > 0xFF {
+			return fmt.Errorf("krb write: element %d has too many custom properties (%d)", i, len(customProps))
+		}
+		for j := range customProps {
+			if err := syncValueSize(&customProps[j].Size, customProps[j].Value); err != nil {
+				return fmt.Errorf("krb write: element %d, cprop %d: %w", i, j, err)
+			}
+		}
+		events := sliceAt(doc.Events, i)
+		animRefs := sliceAt(doc.AnimationRefs, i)
+		childRefs := sliceAt(doc.ChildRefs, i)
+		if len(events) > 0xFF || len(animRefs) > 0xFF || len(childRefs) > 0xFF {
+			return fmt.Errorf("krb write: element %d has too many events (%d), anim refs (%d) or children (%d)",
+				i, len(events), len(animRefs), len(childRefs))
+		}
+
+		elemHdr.PropertyCount = uint8(len(props))
+		elemHdr.CustomPropCount = uint8(len(customProps))
+		elemHdr.EventCount = uint8(len(events))
+		elemHdr.AnimationCount = uint8(len(animRefs))
+		elemHdr.ChildCount = uint8(len(childRefs))
+	}
+
+	if len(doc.Styles) > 0xFFFF {
+		return fmt.Errorf("krb write: too many styles (%d)", len(doc.Styles))
+	}
+	for i := range doc.Styles {
+		style := &doc.Styles[i]
+		if len(style.Properties) > 0xFF {
+			return fmt.Errorf("krb write: style %d has too many properties (%d)", i, len(style.Properties))
+		}
+		style.PropertyCount = uint8(len(style.Properties))
+		for j := range style.Properties {
+			if err := syncValueSize(&style.Properties[j].Size, style.Properties[j].Value); err != nil {
+				return fmt.Errorf("krb write: style %d, prop %d: %w", i, j, err)
+			}
+		}
+	}
+
+	if len(doc.ComponentDefinitions) > 0xFFFF {
+		return fmt.Errorf("krb write: too many component definitions (%d)", len(doc.ComponentDefinitions))
+	}
+	for i := range doc.ComponentDefinitions {
+		compDef := &doc.ComponentDefinitions[i]
+		if len(compDef.PropertyDefinitions) > 0xFF {
+			return fmt.Errorf("krb write: comp_def %d has too many property definitions (%d)", i, len(compDef.PropertyDefinitions))
+		}
+		compDef.PropertyDefCount = uint8(len(compDef.PropertyDefinitions))
+		for j := range compDef.PropertyDefinitions {
+			propDef := &compDef.PropertyDefinitions[j]
+			if err := syncValueSize(&propDef.DefaultValueSize, propDef.DefaultValueData); err != nil {
+				return fmt.Errorf("krb write: comp_def %d, prop_def %d: %w", i, j, err)
+			}
+		}
+	}
+
+	if len(doc.Animations) > 0xFFFF {
+		return fmt.Errorf("krb write: too many animations (%d)", len(doc.Animations))
+	}
+	for i := range doc.Animations {
+		if err := syncAnimation(&doc.Animations[i]); err != nil {
+			return fmt.Errorf("krb write: animation %d: %w", i, err)
+		}
+	}
+
+	if len(doc.Strings) > 0xFFFF {
+		return fmt.Errorf("krb write: too many strings (%d)", len(doc.Strings))
+	}
+	for i, s := range doc.Strings {
+		if len(s) > 0xFF {
+			return fmt.Errorf("krb write: string %d is too long (%d bytes, max 255)", i, len(s))
+		}
+	}
+
+	if len(doc.Resources) > 0xFFFF {
+		return fmt.Errorf("krb write: too many resources (%d)", len(doc.Resources))
+	}
+	for i := range doc.Resources {
+		res := &doc.Resources[i]
+		switch res.Format {
+		case ResFormatExternal:
+		case ResFormatInline:
+			if len(res.InlineData) > 0xFFFF {
+				return fmt.Errorf("krb write: inline resource %d is too large (%d bytes)", i, len(res.InlineData))
+			}
+			res.InlineDataSize = uint16(len(res.InlineData))
+		default:
+			return fmt.Errorf("krb write: unknown resource format 0x%02X for resource %d", res.Format, i)
+		}
+	}
+
+	hdr := &doc.Header
+	hdr.Magic = MagicNumber
+	if hdr.Version == 0 {
+		hdr.Version = ExpectedVersion
+	}
+	hdr.ElementCount = uint16(len(doc.Elements))
+	hdr.StyleCount = uint16(len(doc.Styles))
+	hdr.ComponentDefCount = uint16(len(doc.ComponentDefinitions))
+	hdr.StringCount = uint16(len(doc.Strings))
+	hdr.ResourceCount = uint16(len(doc.Resources))
+	hdr.AnimationCount = uint16(animationEntryCount(doc))
+	hdr.Flags = setFlag(hdr.Flags, FlagHasStyles, hdr.StyleCount > 0)
+	hdr.Flags = setFlag(hdr.Flags, FlagHasComponentDefs, hdr.ComponentDefCount > 0)
+	hdr.Flags = setFlag(hdr.Flags, FlagHasAnimations, hdr.AnimationCount > 0)
+	hdr.Flags = setFlag(hdr.Flags, FlagHasResources, hdr.ResourceCount > 0)
+
+	doc.VersionMajor = uint8(hdr.Version & 0x00FF)
+	doc.VersionMinor = uint8(hdr.Version >> 8)
+	return nil
+}
+
+func syncValueSize(size *uint8, value []byte) error {
+	if len(value) > 0xFF {
+		return fmt.Errorf("value too large (%d bytes, max 255)", len(value))
+	}
+	*size = uint8(len(value))
+	return nil
+}
+
+func setFlag(flags, flag uint16, on bool) uint16 {
+	if on {
+		return flags | flag
+	}
+	return flags &^ flag
+}
+
+func sliceAt[T any](s [][]T, i int) []T {
+	if i < len(s) {
+		return s[i]
+	}
+	return nil
+}
+
+// resolveChildIndices converts each element's ChildRefs, which are byte offsets
+// relative to the parent's start, into indices into doc.Elements using the
+// document's current ElementStartOffsets.
+func resolveChildIndices(doc *Document) ([][]int, error) {
+	indices := make([][]int, len(doc.Elements))
+	hasChildren := false
+	for i := range doc.Elements {
+		if len(sliceAt(doc.ChildRefs, i)) > 0 {
+			hasChildren = true
+			break
+		}
+	}
+	if !hasChildren {
+		return indices, nil
+	}
+	if len(doc.ElementStartOffsets) != len(doc.Elements) {
+		return nil, fmt.Errorf("krb write: ElementStartOffsets has %d entries for %d elements; cannot resolve ChildRefs",
+			len(doc.ElementStartOffsets), len(doc.Elements))
+	}
+
+	offsetToIndex := make(map[uint32]int, len(doc.ElementStartOffsets))
+	for i, off := range doc.ElementStartOffsets {
+		offsetToIndex[off] = i
+	}
+	for i := range doc.Elements {
+		childRefs := sliceAt(doc.ChildRefs, i)
+		if len(childRefs) == 0 {
+			continue
+		}
+		indices[i] = make([]int, len(childRefs))
+		for j, childRef := range childRefs {
+			childAbsoluteOffset := doc.ElementStartOffsets[i] + uint32(childRef.ChildOffset)
+			childIndex, found := offsetToIndex[childAbsoluteOffset]
+			if !found {
+				return nil, fmt.Errorf("krb write: element %d ChildRef %d offset %d (abs %d) does not land on an element start",
+					i, j, childRef.ChildOffset, childAbsoluteOffset)
+			}
+			indices[i][j] = childIndex
+		}
+	}
+	return indices, nil
+}
+
+// assignChildRefs rewrites ChildRefs from element indices using the given
+// element start offsets. Children must be placed after their parent.
+func assignChildRefs(doc *Document, childIndices [][]int, startOffsets []uint32) error {
+	for i := range doc.Elements {
+		indices := sliceAt(childIndices, i)
+		if len(indices) == 0 {
+			continue
+		}
+		if len(doc.ChildRefs) < len(doc.Elements) {
+			grown := make([][]ChildRef, len(doc.Elements))
+			copy(grown, doc.ChildRefs)
+			doc.ChildRefs = grown
+		}
+		refs := make([]ChildRef, len(indices))
+		for j, childIndex := range indices {
+			if childIndex < 0 || childIndex >= len(startOffsets) {
+				return fmt.Errorf("krb write: element %d child %d references unknown element index %d", i, j, childIndex)
+			}
+			if startOffsets[childIndex] <= startOffsets[i] {
+				return fmt.Errorf("krb write: element %d child %d (element %d) must be placed after its parent", i, j, childIndex)
+			}
+			relative := startOffsets[childIndex] - startOffsets[i]
+			if relative > 0xFFFF {
+				return fmt.Errorf("krb write: element %d child %d (element %d) is %d bytes away, beyond ChildRef range",
+					i, j, childIndex, relative)
+			}
+			refs[j] = ChildRef{ChildOffset: uint16(relative)}
+		}
+		doc.ChildRefs[i] = refs
+	}
+	return nil
+}
+
+// elementBlockSize returns the encoded size of element i, from its header up to
+// and including its child refs.
+func elementBlockSize(doc *Document, i int) uint32 {
+	size := uint32(ElementHeaderSize)
+	for _, prop := range sliceAt(doc.Properties, i) {
+		size += 3 + uint32(len(prop.Value))
+	}
+	for _, cprop := range sliceAt(doc.CustomProperties, i) {
+		size += 3 + uint32(len(cprop.Value))
+	}
+	size += uint32(len(sliceAt(doc.Events, i))) * EventFileEntrySize
+	size += uint32(len(sliceAt(doc.AnimationRefs, i))) * AnimationRefSize
+	size += uint32(len(sliceAt(doc.ChildRefs, i))) * ChildRefSize
+	return size
+}
+
+func computeElementStartOffsets(doc *Document) []uint32 {
+	if len(doc.Elements) == 0 {
+		return nil
+	}
+	offsets := make([]uint32, len(doc.Elements))
+	pos := uint32(HeaderSize)
+	for i := range doc.Elements {
+		offsets[i] = pos
+		pos += elementBlockSize(doc, i)
+	}
+	return offsets
+}
+
+// computeSectionOffsets lays the sections out in spec order (elements, styles,
+// component definitions, animations, strings, resources) and fills in the
+// header offsets and TotalSize. Empty sections point at the position where
+// they would start.
+func computeSectionOffsets(doc *Document) {
+	hdr := &doc.Header
+	pos := uint32(HeaderSize)
+
+	hdr.ElementOffset = pos
+	for i := range doc.Elements {
+		pos += elementBlockSize(doc, i)
+	}
+
+	hdr.StyleOffset = pos
+	for i := range doc.Styles {
+		pos += 3
+		for _, prop := range doc.Styles[i].Properties {
+			pos += 3 + uint32(len(prop.Value))
+		}
+	}
+
+	hdr.ComponentDefOffset = pos
+	for i := range doc.ComponentDefinitions {
+		pos += componentDefSize(&doc.ComponentDefinitions[i])
+	}
+
+	hdr.AnimationOffset = pos
+	for i := range doc.Animations {
+		pos += animationSize(&doc.Animations[i])
+	}
+
+	hdr.StringOffset = pos
+	if len(doc.Strings) > 0 {
+		pos += 2
+		for _, s := range doc.Strings {
+			pos += 1 + uint32(len(s))
+		}
+	}
+
+	hdr.ResourceOffset = pos
+	if len(doc.Resources) > 0 {
+		pos += 2
+		for i := range doc.Resources {
+			pos += resourceSize(&doc.Resources[i])
+		}
+	}
+
+	hdr.TotalSize = pos
+}
+
+// componentDefSize returns the encoded size of a component definition entry,
+// including its property definitions and root element template.
+func componentDefSize(compDef *KrbComponentDefinition) uint32 {
+	size := uint32(2)
+	for _, propDef := range compDef.PropertyDefinitions {
+		size += 3 + uint32(len(propDef.DefaultValueData))
+	}
+	return size + uint32(len(compDef.RootElementTemplateData))
+}
+
+// resourceSize returns the encoded size of a resource table entry.
+func resourceSize(res *Resource) uint32 {
+	size := uint32(3)
+	switch res.Format {
+	case ResFormatExternal:
+		size++
+	case ResFormatInline:
+		size += 2 + uint32(len(res.InlineData))
+	}
+	return size
+}
+
+func writeU16LE(buf *bytes.Buffer, v uint16) {
+	var b [2]byte
+	binary.LittleEndian.PutUint16(b[:], v)
+	buf.Write(b[:])
+}
+
+func writeU32LE(buf *bytes.Buffer, v uint32) {
+	var b [4]byte
+	binary.LittleEndian.PutUint32(b[:], v)
+	buf.Write(b[:])
+}
+
+func writeHeader(buf *bytes.Buffer, hdr *Header) {
+	buf.Write(hdr.Magic[:])
+	writeU16LE(buf, hdr.Version)
+	writeU16LE(buf, hdr.Flags)
+	writeU16LE(buf, hdr.ElementCount)
+	writeU16LE(buf, hdr.StyleCount)
+	writeU16LE(buf, hdr.ComponentDefCount)
+	writeU16LE(buf, hdr.AnimationCount)
+	writeU16LE(buf, hdr.StringCount)
+	writeU16LE(buf, hdr.ResourceCount)
+	writeU32LE(buf, hdr.ElementOffset)
+	writeU32LE(buf, hdr.StyleOffset)
+	writeU32LE(buf, hdr.ComponentDefOffset)
+	writeU32LE(buf, hdr.AnimationOffset)
+	writeU32LE(buf, hdr.StringOffset)
+	writeU32LE(buf, hdr.ResourceOffset)
+	writeU32LE(buf, hdr.TotalSize)
+}
+
+func writeElementHeader(buf *bytes.Buffer, elemHdr *ElementHeader) {
+	buf.WriteByte(byte(elemHdr.Type))
+	buf.WriteByte(elemHdr.ID)
+	writeU16LE(buf, elemHdr.PosX)
+	writeU16LE(buf, elemHdr.PosY)
+	writeU16LE(buf, elemHdr.Width)
+	writeU16LE(buf, elemHdr.Height)
+	buf.WriteByte(elemHdr.Layout)
+	buf.WriteByte(elemHdr.StyleID)
+	buf.WriteByte(elemHdr.PropertyCount)
+	buf.WriteByte(elemHdr.ChildCount)
+	buf.WriteByte(elemHdr.EventCount)
+	buf.WriteByte(elemHdr.AnimationCount)
+	buf.WriteByte(elemHdr.CustomPropCount)
+}
+
+func writeProperty(buf *bytes.Buffer, prop *Property) {
+	buf.WriteByte(byte(prop.ID))
+	buf.WriteByte(byte(prop.ValueType))
+	buf.WriteByte(prop.Size)
+	buf.Write(prop.Value)
+}
+
+func writeElementBlock(buf *bytes.Buffer, doc *Document, i int) {
+	writeElementHeader(buf, &doc.Elements[i])
+	props := sliceAt(doc.Properties, i)
+	for j := range props {
+		writeProperty(buf, &props[j])
+	}
+	for _, cprop := range sliceAt(doc.CustomProperties, i) {
+		buf.WriteByte(cprop.KeyIndex)
+		buf.WriteByte(byte(cprop.ValueType))
+		buf.WriteByte(cprop.Size)
+		buf.Write(cprop.Value)
+	}
+	for _, event := range sliceAt(doc.Events, i) {
+		buf.WriteByte(byte(event.EventType))
+		buf.WriteByte(event.CallbackID)
+	}
+	for _, animRef := range sliceAt(doc.AnimationRefs, i) {
+		buf.WriteByte(animRef.AnimationIndex)
+		buf.WriteByte(uint8(animRef.Trigger))
+	}
+	for _, childRef := range sliceAt(doc.ChildRefs, i) {
+		writeU16LE(buf, childRef.ChildOffset)
+	}
+}
+
+func writeStyle(buf *bytes.Buffer, style *Style) {
+	buf.WriteByte(style.ID)
+	buf.WriteByte(style.NameIndex)
+	buf.WriteByte(style.PropertyCount)
+	for j := range style.Properties {
+		writeProperty(buf, &style.Properties[j])
+	}
+}
+
+func writeComponentDefinition(buf *bytes.Buffer, compDef *KrbComponentDefinition) {
+	buf.WriteByte(compDef.NameIndex)
+	buf.WriteByte(compDef.PropertyDefCount)
+	for _, propDef := range compDef.PropertyDefinitions {
+		buf.WriteByte(propDef.NameIndex)
+		buf.WriteByte(byte(propDef.ValueTypeHint))
+		buf.WriteByte(propDef.DefaultValueSize)
+		buf.Write(propDef.DefaultValueData)
+	}
+	buf.Write(compDef.RootElementTemplateData)
+}
+
+func writeStringTable(buf *bytes.Buffer, strs []string) {
+	writeU16LE(buf, uint16(len(strs)))
+	for _, s := range strs {
+		buf.WriteByte(uint8(len(s)))
+		buf.WriteString(s)
+	}
+}
+
+func writeResourceTable(buf *bytes.Buffer, resources []Resource) {
+	writeU16LE(buf, uint16(len(resources)))
+	for _, res := range resources {
+		buf.WriteByte(byte(res.Type))
+		buf.WriteByte(res.NameIndex)
+		buf.WriteByte(byte(res.Format))
+		switch res.Format {
+		case ResFormatExternal:
+			buf.WriteByte(res.DataStringIndex)
+		case ResFormatInline:
+			writeU16LE(buf, res.InlineDataSize)
+			buf.Write(res.InlineData)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package krb
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"testing"
+)
+
+// seekBuffer is an in-memory io.WriteSeeker for WriteDocument.
+type seekBuffer struct {
+	data []byte
+	pos  int64
+}
+
+func (b *seekBuffer) Write(p []byte) (int, error) {
+	if end := int(b.pos) + len(p); end > len(b.data) {
+		b.data = append(b.data, make([]byte, end-len(b.data))...)
+	}
+	copy(b.data[b.pos:], p)
+	b.pos += int64(len(p))
+	return len(p), nil
+}
+
+func (b *seekBuffer) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+		b.pos = offset
+	case io.SeekCurrent:
+		b.pos += offset
+	case io.SeekEnd:
+		b.pos = int64(len(b.data)) + offset
+	}
+	return b.pos, nil
+}
+
+func encode(t *testing.T, doc *Document) []byte {
+	t.Helper()
+	var buf seekBuffer
+	if err := WriteDocument(&buf, doc); err != nil {
+		t.Fatalf("WriteDocument: %v", err)
+	}
+	return buf.data
+}
+
+func decode(t *testing.T, data []byte) *Document {
+	t.Helper()
+	doc, err := ReadDocument(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("ReadDocument: %v", err)
+	}
+	return doc
+}
+
+var exampleFiles = []string{
+	"../examples/button/button.krb",
+	"../examples/tabbar/tab_bar.krb",
+}
+
+func TestWriteDocumentRoundTripsExamples(t *testing.T) {
+	for _, path := range exampleFiles {
+		t.Run(path, func(t *testing.T) {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := encode(t, decode(t, data)); !bytes.Equal(got, data) {
+				t.Errorf("re-encoded file is %d bytes and differs from the %d byte original", len(got), len(data))
+			}
+		})
+	}
+}
+
+func TestWriteDocumentSyncsCounts(t *testing.T) {
+	doc := &Document{
+		Elements:         []ElementHeader{{Type: ElemTypeApp, PropertyCount: 9}},
+		Properties:       [][]Property{{{ID: PropIDBorderWidth, ValueType: ValTypeByte, Value: []byte{2}}}},
+		CustomProperties: [][]CustomProperty{nil},
+		Events:           [][]EventFileEntry{nil},
+		AnimationRefs:    [][]AnimationRef{nil},
+		ChildRefs:        [][]ChildRef{nil},
+		Strings:          []string{"app"},
+	}
+	got := decode(t, encode(t, doc))
+	if got.Header.ElementCount != 1 || got.Header.StringCount != 1 {
+		t.Errorf("header counts = %d elements, %d strings, want 1 and 1", got.Header.ElementCount, got.Header.StringCount)
+	}
+	if got.Elements[0].PropertyCount != 1 || got.Properties[0][0].Size != 1 {
+		t.Errorf("element 0 PropertyCount %d, property Size %d, want 1 and 1", got.Elements[0].PropertyCount, got.Properties[0][0].Size)
+	}
+}
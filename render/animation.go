@@ -0,0 +1,581 @@
+// render/animation.go
+package render
+
+import (
+	"encoding/binary"
+	"image/color"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/kryonlabs/kryon-go-runtime/krb"
+)
+
+// Clock supplies the current time to the animation runtime. Renderers use
+// SystemClock by default; tests can inject a ManualClock to step animations
+// deterministically without a window.
+type Clock interface {
+	Now() time.Time
+}
+
+// SystemClock reads the wall clock.
+type SystemClock struct{}
+
+func (SystemClock) Now() time.Time { return time.Now() }
+
+// ManualClock only moves when told to.
+type ManualClock struct {
+	now time.Time
+}
+
+func NewManualClock(start time.Time) *ManualClock { return &ManualClock{now: start} }
+
+func (c *ManualClock) Now() time.Time          { return c.now }
+func (c *ManualClock) Set(t time.Time)         { c.now = t }
+func (c *ManualClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+// animPlayer is one running (or holding) instance of a KRB animation on an element.
+type animPlayer struct {
+	el       *RenderElement
+	anim     *krb.Animation
+	tracks   []krb.AnimationTrack
+	trigger  krb.AnimationTrigger
+	start    time.Time
+	origin   float32 // linear progress when the player was (re)started
+	reverse  bool
+	finished bool
+	eased    float32 // Eased progress at the last Apply
+}
+
+// animBase remembers the values an element had before any animation touched it,
+// so they can be restored when a reversed animation settles back at its start.
+type animBase struct {
+	bgColor     color.RGBA
+	fgColor     color.RGBA
+	borderColor color.RGBA
+	opacity     float32
+	fontSize    float32
+}
+
+// Animator plays the animations referenced by RenderElement.AnimationRefs.
+//
+// Fire starts every animation an element has for a trigger. Animations play
+// forward once and then hold their final values. Release plays hover and focus
+// animations backwards; once they are back at the start the element's original
+// values are restored.
+//
+// Colors, opacity and font size are written into the element and persist
+// between frames. Position and size (PropIDTransform translation and
+// PropIDMaxWidth/PropIDMaxHeight) are layout-owned, so Apply must run after
+// every layout pass. A resized element keeps its place among its siblings and
+// its content is laid out again in its new size by the function given to
+// SetRelayout; a translated element takes its content with it.
+//
+// Colors animate between RGBA values only. Tracks Apply can't play, like
+// palette colors or properties other than these, are dropped with a warning
+// when their animation is fired.
+type Animator struct {
+	clock    Clock
+	scale    float32
+	relayout func(el *RenderElement)
+	players  []*animPlayer
+	bases    map[*RenderElement]*animBase
+}
+
+func NewAnimator(clock Clock) *Animator {
+	if clock == nil {
+		clock = SystemClock{}
+	}
+	return &Animator{clock: clock, scale: 1.0, bases: make(map[*RenderElement]*animBase)}
+}
+
+// SetClock replaces the time source. Running animations keep their start times.
+func (a *Animator) SetClock(clock Clock) {
+	if clock == nil {
+		clock = SystemClock{}
+	}
+	a.clock = clock
+}
+
+// Now returns the current time of the animation clock. Other time-based
+// effects, like the caret blink, use it to stay in step with animations.
+func (a *Animator) Now() time.Time {
+	return a.clock.Now()
+}
+
+// SetScale sets the UI scale factor applied to pixel-valued animated properties.
+func (a *Animator) SetScale(scale float32) {
+	if scale <= 0 {
+		scale = 1.0
+	}
+	a.scale = scale
+}
+
+// SetRelayout sets the function Apply calls to lay out the content of an
+// element within the size an animation gave it, such as the layout engine's
+// LayoutContent. Without one, that content keeps the size it was laid out in.
+func (a *Animator) SetRelayout(relayout func(el *RenderElement)) {
+	a.relayout = relayout
+}
+
+// Reset drops all players without restoring element values.
+func (a *Animator) Reset() {
+	a.players = nil
+	a.bases = make(map[*RenderElement]*animBase)
+}
+
+// Running reports whether any animation is still in progress (not just holding).
+func (a *Animator) Running() bool {
+	for _, p := range a.players {
+		if !p.finished {
+			return true
+		}
+	}
+	return false
+}
+
+// HasTrigger reports whether el references any animation fired by trigger.
+func HasTrigger(el *RenderElement, trigger krb.AnimationTrigger) bool {
+	for _, ref := range el.AnimationRefs {
+		if ref.Trigger == trigger {
+			return true
+		}
+	}
+	return false
+}
+
+// Fire starts all of el's animations for trigger. Hover and focus animations
+// that are already playing forward are left alone; reversed ones turn around
+// from where they are. Load and click animations restart from the beginning.
+func (a *Animator) Fire(el *RenderElement, trigger krb.AnimationTrigger) {
+	if el == nil || el.DocRef == nil {
+		return
+	}
+	now := a.clock.Now()
+	for _, ref := range el.AnimationRefs {
+		if ref.Trigger != trigger {
+			continue
+		}
+		if int(ref.AnimationIndex) >= len(el.DocRef.Animations) {
+			log.Printf("Warn Animator: Element '%s' references animation %d, but the document has %d.",
+				el.SourceElementName, ref.AnimationIndex, len(el.DocRef.Animations))
+			continue
+		}
+		anim := &el.DocRef.Animations[ref.AnimationIndex]
+
+		if p := a.findPlayer(el, anim, trigger); p != nil {
+			isToggle := trigger == krb.AnimTriggerHover || trigger == krb.AnimTriggerFocus
+			if isToggle && !p.reverse {
+				continue
+			}
+			origin := float32(0)
+			if isToggle {
+				origin = p.progress(now)
+			}
+			p.start, p.origin, p.reverse, p.finished = now, origin, false, false
+			continue
+		}
+
+		a.captureBase(el)
+		a.players = append(a.players, &animPlayer{
+			el:      el,
+			anim:    anim,
+			tracks:  playableTracks(el, ref.AnimationIndex, anim.Tracks()),
+			trigger: trigger,
+			start:   now,
+		})
+	}
+}
+
+// Release plays el's animations for trigger back to their start. It is used
+// when the pointer leaves an element (hover) or the element loses focus.
+func (a *Animator) Release(el *RenderElement, trigger krb.AnimationTrigger) {
+	now := a.clock.Now()
+	for _, p := range a.players {
+		if p.el == el && p.trigger == trigger && !p.reverse {
+			p.origin = p.progress(now)
+			p.start, p.reverse, p.finished = now, true, false
+		}
+	}
+}
+
+// playableTracks returns the tracks of el's animation index that Apply can
+// play, logging a warning for each of the others.
+func playableTracks(el *RenderElement, index uint8, tracks []krb.AnimationTrack) []krb.AnimationTrack {
+	playable := tracks[:0]
+	for _, track := range tracks {
+		if trackPlayable(&track) {
+			playable = append(playable, track)
+			continue
+		}
+		log.Printf("Warn Animator: Element '%s' animation %d animates property 0x%X with value type 0x%X, which can't be animated. Ignoring it.",
+			el.SourceElementName, index, track.PropertyID, track.ValueType)
+	}
+	return playable
+}
+
+// trackPlayable reports whether every key of track holds a value applyTrack
+// can write into its property.
+func trackPlayable(track *krb.AnimationTrack) bool {
+	components := 1
+	switch track.PropertyID {
+	case krb.PropIDBgColor, krb.PropIDFgColor, krb.PropIDBorderColor:
+		if track.ValueType != krb.ValTypeColor {
+			return false
+		}
+		components = 4
+	case krb.PropIDTransform:
+		components = 2
+	case krb.PropIDOpacity, krb.PropIDFontSize, krb.PropIDMaxWidth, krb.PropIDMinWidth, krb.PropIDMaxHeight, krb.PropIDMinHeight:
+	default:
+		return false
+	}
+	for _, key := range track.Keys {
+		if v, ok := decodeAnimValue(track.ValueType, key.Value); !ok || len(v) != components {
+			return false
+		}
+	}
+	return len(track.Keys) > 0
+}
+
+// isSizeTrack reports whether track animates the width or height of its element.
+func isSizeTrack(track krb.AnimationTrack) bool {
+	switch track.PropertyID {
+	case krb.PropIDMaxWidth, krb.PropIDMinWidth, krb.PropIDMaxHeight, krb.PropIDMinHeight:
+		return true
+	}
+	return false
+}
+
+func (a *Animator) findPlayer(el *RenderElement, anim *krb.Animation, trigger krb.AnimationTrigger) *animPlayer {
+	for _, p := range a.players {
+		if p.el == el && p.anim == anim && p.trigger == trigger {
+			return p
+		}
+	}
+	return nil
+}
+
+func (a *Animator) captureBase(el *RenderElement) {
+	if _, ok := a.bases[el]; ok {
+		return
+	}
+	a.bases[el] = &animBase{
+		bgColor:     el.BgColor,
+		fgColor:     el.FgColor,
+		borderColor: el.BorderColor,
+		opacity:     el.Opacity,
+		fontSize:    el.ResolvedFontSize,
+	}
+}
+
+// progress returns the player's linear progress (0.0-1.0) at now.
+func (p *animPlayer) progress(now time.Time) float32 {
+	if p.finished {
+		if p.reverse {
+			return 0
+		}
+		return 1
+	}
+	delta := float32(1)
+	if p.anim.DurationMs > 0 {
+		delta = float32(now.Sub(p.start).Seconds()*1000) / float32(p.anim.DurationMs)
+	}
+	if p.reverse {
+		return clamp01(p.origin - delta)
+	}
+	return clamp01(p.origin + delta)
+}
+
+// Apply advances all players to the clock's current time and writes the
+// interpolated values into their elements. Call it once per frame, after layout.
+//
+// Sizes are applied first, outermost element first, each followed by the
+// relayout of its content, which would otherwise undo the sizes and
+// translations of the elements within.
+func (a *Animator) Apply() {
+	if len(a.players) == 0 {
+		return
+	}
+	now := a.clock.Now()
+	kept := a.players[:0]
+	var settled []*animPlayer
+	for _, p := range a.players {
+		t := p.progress(now)
+		if (p.reverse && t <= 0) || (!p.reverse && t >= 1) {
+			p.finished = true
+		}
+		if p.finished && p.reverse {
+			settled = append(settled, p)
+			continue
+		}
+		p.eased = p.anim.Easing.Ease(t)
+		kept = append(kept, p)
+	}
+	a.players = kept
+
+	for _, el := range a.resizedElements() {
+		for _, p := range a.players {
+			if p.el != el {
+				continue
+			}
+			for i := range p.tracks {
+				if isSizeTrack(p.tracks[i]) {
+					a.applyTrack(p.el, &p.tracks[i], p.eased)
+				}
+			}
+		}
+		if a.relayout != nil {
+			a.relayout(el)
+		}
+	}
+	for _, p := range a.players {
+		for i := range p.tracks {
+			if !isSizeTrack(p.tracks[i]) {
+				a.applyTrack(p.el, &p.tracks[i], p.eased)
+			}
+		}
+	}
+
+	for _, p := range settled {
+		a.restore(p)
+	}
+}
+
+// resizedElements returns the elements with size tracks playing, each after
+// its ancestors.
+func (a *Animator) resizedElements() []*RenderElement {
+	var resized []*RenderElement
+	for _, p := range a.players {
+		if !slices.Contains(resized, p.el) && slices.ContainsFunc(p.tracks, isSizeTrack) {
+			resized = append(resized, p.el)
+		}
+	}
+	depth := func(el *RenderElement) int {
+		n := 0
+		for cur := el.Parent; cur != nil; cur = cur.Parent {
+			n++
+		}
+		return n
+	}
+	slices.SortStableFunc(resized, func(x, y *RenderElement) int { return depth(x) - depth(y) })
+	return resized
+}
+
+// restore puts back the pre-animation values a settled player had overridden,
+// unless another player still animates the same property.
+func (a *Animator) restore(settled *animPlayer) {
+	base, ok := a.bases[settled.el]
+	if !ok {
+		return
+	}
+	stillAnimated := func(id krb.PropertyID) bool {
+		for _, p := range a.players {
+			if p.el != settled.el {
+				continue
+			}
+			for _, tr := range p.tracks {
+				if tr.PropertyID == id {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	for _, tr := range settled.tracks {
+		if stillAnimated(tr.PropertyID) {
+			continue
+		}
+		switch tr.PropertyID {
+		case krb.PropIDBgColor:
+			settled.el.BgColor = base.bgColor
+		case krb.PropIDFgColor:
+			settled.el.FgColor = base.fgColor
+		case krb.PropIDBorderColor:
+			settled.el.BorderColor = base.borderColor
+		case krb.PropIDOpacity:
+			settled.el.Opacity = base.opacity
+		case krb.PropIDFontSize:
+			settled.el.ResolvedFontSize = base.fontSize
+		}
+	}
+	for _, p := range a.players {
+		if p.el == settled.el {
+			return
+		}
+	}
+	delete(a.bases, settled.el)
+}
+
+// sampleTrack returns the track value at eased progress t, interpolating
+// between the surrounding keys.
+func sampleTrack(track *krb.AnimationTrack, t float32) ([]float32, bool) {
+	if len(track.Keys) == 0 {
+		return nil, false
+	}
+	first, last := track.Keys[0], track.Keys[len(track.Keys)-1]
+	if t <= first.T || len(track.Keys) == 1 {
+		return decodeAnimValue(track.ValueType, first.Value)
+	}
+	if t >= last.T {
+		return decodeAnimValue(track.ValueType, last.Value)
+	}
+	for k := 1; k < len(track.Keys); k++ {
+		next := track.Keys[k]
+		if t > next.T {
+			continue
+		}
+		prev := track.Keys[k-1]
+		from, okFrom := decodeAnimValue(track.ValueType, prev.Value)
+		to, okTo := decodeAnimValue(track.ValueType, next.Value)
+		if !okFrom || !okTo || len(from) != len(to) {
+			return nil, false
+		}
+		span := next.T - prev.T
+		local := float32(1)
+		if span > 0 {
+			local = (t - prev.T) / span
+		}
+		out := make([]float32, len(from))
+		for i := range from {
+			out[i] = from[i] + (to[i]-from[i])*local
+		}
+		return out, true
+	}
+	return decodeAnimValue(track.ValueType, last.Value)
+}
+
+// decodeAnimValue converts an encoded property value into numeric components.
+// Percentages are returned as ratios (256 = 1.0).
+func decodeAnimValue(valueType krb.ValueType, data []byte) ([]float32, bool) {
+	switch valueType {
+	case krb.ValTypeColor:
+		if len(data) != 4 {
+			return nil, false
+		}
+		return []float32{float32(data[0]), float32(data[1]), float32(data[2]), float32(data[3])}, true
+	case krb.ValTypeByte, krb.ValTypeEnum:
+		if len(data) != 1 {
+			return nil, false
+		}
+		return []float32{float32(data[0])}, true
+	case krb.ValTypeShort:
+		if len(data) != 2 {
+			return nil, false
+		}
+		return []float32{float32(binary.LittleEndian.Uint16(data))}, true
+	case krb.ValTypePercentage:
+		if len(data) != 2 {
+			return nil, false
+		}
+		return []float32{float32(binary.LittleEndian.Uint16(data)) / 256.0}, true
+	case krb.ValTypeVector:
+		if len(data) != 4 {
+			return nil, false
+		}
+		return []float32{
+			float32(int16(binary.LittleEndian.Uint16(data[0:2]))),
+			float32(int16(binary.LittleEndian.Uint16(data[2:4]))),
+		}, true
+	default:
+		return nil, false
+	}
+}
+
+func (a *Animator) applyTrack(el *RenderElement, track *krb.AnimationTrack, t float32) {
+	v, ok := sampleTrack(track, t)
+	if !ok {
+		return
+	}
+	switch track.PropertyID {
+	case krb.PropIDBgColor:
+		if len(v) == 4 {
+			el.BgColor = floatsToColor(v)
+		}
+	case krb.PropIDFgColor:
+		if len(v) == 4 {
+			el.FgColor = floatsToColor(v)
+		}
+	case krb.PropIDBorderColor:
+		if len(v) == 4 {
+			el.BorderColor = floatsToColor(v)
+		}
+	case krb.PropIDOpacity:
+		if track.ValueType == krb.ValTypePercentage {
+			el.Opacity = clamp01(v[0])
+		} else {
+			el.Opacity = clamp01(v[0] / 255.0)
+		}
+	case krb.PropIDFontSize:
+		if v[0] > 0 {
+			el.ResolvedFontSize = v[0]
+		}
+	case krb.PropIDTransform:
+		if len(v) == 2 {
+			translateSubtree(el, v[0]*a.scale, v[1]*a.scale)
+		}
+	case krb.PropIDMaxWidth, krb.PropIDMinWidth:
+		if track.ValueType == krb.ValTypePercentage {
+			el.RenderW *= v[0]
+		} else {
+			el.RenderW = v[0] * a.scale
+		}
+	case krb.PropIDMaxHeight, krb.PropIDMinHeight:
+		if track.ValueType == krb.ValTypePercentage {
+			el.RenderH *= v[0]
+		} else {
+			el.RenderH = v[0] * a.scale
+		}
+	}
+}
+
+func translateSubtree(el *RenderElement, dx, dy float32) {
+	el.RenderX += dx
+	el.RenderY += dy
+	for _, child := range el.Children {
+		if child != nil {
+			translateSubtree(child, dx, dy)
+		}
+	}
+}
+
+func floatsToColor(v []float32) color.RGBA {
+	ch := func(f float32) uint8 {
+		if f <= 0 {
+			return 0
+		}
+		if f >= 255 {
+			return 255
+		}
+		return uint8(f + 0.5)
+	}
+	return color.RGBA{ch(v[0]), ch(v[1]), ch(v[2]), ch(v[3])}
+}
+
+func clamp01(f float32) float32 {
+	if f < 0 {
+		return 0
+	}
+	if f > 1 {
+		return 1
+	}
+	return f
+}
+
+// EffectiveOpacity returns el's opacity multiplied by that of all its ancestors.
+func EffectiveOpacity(el *RenderElement) float32 {
+	opacity := float32(1.0)
+	for cur := el; cur != nil; cur = cur.Parent {
+		opacity *= clamp01(cur.Opacity)
+	}
+	return opacity
+}
+
+// ApplyOpacity scales the alpha channel of c by opacity.
+func ApplyOpacity(c color.RGBA, opacity float32) color.RGBA {
+	if opacity >= 1 {
+		return c
+	}
+	c.A = uint8(float32(c.A) * clamp01(opacity))
+	return c
+}
@@ -0,0 +1,195 @@
+package render
+
+import (
+	"image/color"
+	"testing"
+	"time"
+
+	"github.com/kryonlabs/kryon-go-runtime/krb"
+)
+
+// animatedElement builds a one-element document whose App element references
+// the given animation for trigger.
+func animatedElement(t *testing.T, trigger krb.AnimationTrigger, add func(b *krb.Builder) uint8) *RenderElement {
+	t.Helper()
+	b := krb.NewBuilder()
+	anim := add(b)
+	b.AddElement(krb.ElemTypeApp).Animation(anim, trigger)
+	doc, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &RenderElement{
+		DocRef:        doc,
+		AnimationRefs: doc.AnimationRefs[0],
+		BgColor:       color.RGBA{10, 20, 30, 255},
+		Opacity:       1,
+	}
+}
+
+func bgTransition(easing krb.EasingType) func(b *krb.Builder) uint8 {
+	return func(b *krb.Builder) uint8 {
+		return b.AddTransition(100, easing,
+			krb.ColorProperty(krb.PropIDBgColor, 0, 0, 0, 255),
+			krb.ColorProperty(krb.PropIDBgColor, 200, 100, 0, 255))
+	}
+}
+
+func TestAnimatorTransition(t *testing.T) {
+	el := animatedElement(t, krb.AnimTriggerLoad, bgTransition(krb.EasingLinear))
+	clock := NewManualClock(time.Unix(0, 0))
+	a := NewAnimator(clock)
+	a.Fire(el, krb.AnimTriggerLoad)
+
+	steps := []struct {
+		at   time.Duration
+		want color.RGBA
+	}{
+		{0, color.RGBA{0, 0, 0, 255}},
+		{50 * time.Millisecond, color.RGBA{100, 50, 0, 255}},
+		{100 * time.Millisecond, color.RGBA{200, 100, 0, 255}},
+		{time.Second, color.RGBA{200, 100, 0, 255}},
+	}
+	for _, step := range steps {
+		clock.Set(time.Unix(0, 0).Add(step.at))
+		a.Apply()
+		if el.BgColor != step.want {
+			t.Errorf("at %v BgColor = %v, want %v", step.at, el.BgColor, step.want)
+		}
+	}
+	if a.Running() {
+		t.Error("a finished transition still reports Running")
+	}
+}
+
+func TestAnimatorEasing(t *testing.T) {
+	el := animatedElement(t, krb.AnimTriggerLoad, bgTransition(krb.EasingEaseIn))
+	clock := NewManualClock(time.Unix(0, 0))
+	a := NewAnimator(clock)
+	a.Fire(el, krb.AnimTriggerLoad)
+	clock.Advance(50 * time.Millisecond)
+	a.Apply()
+	// Ease-in at half time is a quarter of the way.
+	if want := (color.RGBA{50, 25, 0, 255}); el.BgColor != want {
+		t.Errorf("BgColor = %v, want %v", el.BgColor, want)
+	}
+}
+
+func TestAnimatorHoverReleaseRestoresBase(t *testing.T) {
+	el := animatedElement(t, krb.AnimTriggerHover, bgTransition(krb.EasingLinear))
+	base := el.BgColor
+	clock := NewManualClock(time.Unix(0, 0))
+	a := NewAnimator(clock)
+
+	a.Fire(el, krb.AnimTriggerHover)
+	clock.Advance(50 * time.Millisecond)
+	a.Apply()
+	// Leaving half way plays back from the current value, not from the end.
+	a.Release(el, krb.AnimTriggerHover)
+	clock.Advance(25 * time.Millisecond)
+	a.Apply()
+	if want := (color.RGBA{50, 25, 0, 255}); el.BgColor != want {
+		t.Errorf("while reversing BgColor = %v, want %v", el.BgColor, want)
+	}
+	if !a.Running() {
+		t.Error("a reversing animation does not report Running")
+	}
+
+	clock.Advance(25 * time.Millisecond)
+	a.Apply()
+	if el.BgColor != base {
+		t.Errorf("after reversing BgColor = %v, want the original %v", el.BgColor, base)
+	}
+	if a.Running() {
+		t.Error("Running after the animation settled")
+	}
+}
+
+func TestAnimatorKeyframes(t *testing.T) {
+	el := animatedElement(t, krb.AnimTriggerLoad, func(b *krb.Builder) uint8 {
+		return b.AddKeyframeAnimation(1000, krb.EasingLinear,
+			krb.Keyframe{Time: 0, Properties: []krb.Property{krb.ShortProperty(krb.PropIDFontSize, 10)}},
+			krb.Keyframe{Time: 51, Properties: []krb.Property{krb.ShortProperty(krb.PropIDFontSize, 30)}},
+			krb.Keyframe{Time: 255, Properties: []krb.Property{krb.ShortProperty(krb.PropIDFontSize, 20)}},
+		)
+	})
+	clock := NewManualClock(time.Unix(0, 0))
+	a := NewAnimator(clock)
+	a.Fire(el, krb.AnimTriggerLoad)
+
+	steps := []struct {
+		at   time.Duration
+		want float32
+	}{
+		{100 * time.Millisecond, 20}, // Half way to the keyframe at 20%
+		{200 * time.Millisecond, 30},
+		{600 * time.Millisecond, 25},
+		{time.Second, 20},
+	}
+	for _, step := range steps {
+		clock.Set(time.Unix(0, 0).Add(step.at))
+		a.Apply()
+		if diff := el.ResolvedFontSize - step.want; diff < -0.01 || diff > 0.01 {
+			t.Errorf("at %v font size = %v, want %v", step.at, el.ResolvedFontSize, step.want)
+		}
+	}
+}
+
+func TestAnimatorResizeRelaysOutContent(t *testing.T) {
+	el := animatedElement(t, krb.AnimTriggerLoad, func(b *krb.Builder) uint8 {
+		return b.AddKeyframeAnimation(100, krb.EasingLinear,
+			krb.Keyframe{Time: 0, Properties: []krb.Property{
+				krb.ShortProperty(krb.PropIDMaxWidth, 100),
+				{ID: krb.PropIDTransform, ValueType: krb.ValTypeVector, Size: 4, Value: []byte{0, 0, 0, 0}},
+			}},
+			krb.Keyframe{Time: 255, Properties: []krb.Property{
+				krb.ShortProperty(krb.PropIDMaxWidth, 200),
+				{ID: krb.PropIDTransform, ValueType: krb.ValTypeVector, Size: 4, Value: []byte{20, 0, 0, 0}},
+			}},
+		)
+	})
+	child := &RenderElement{Parent: el}
+	el.Children = []*RenderElement{child}
+	clock := NewManualClock(time.Unix(0, 0))
+	a := NewAnimator(clock)
+	var relaidOut []*RenderElement
+	a.SetRelayout(func(el *RenderElement) {
+		relaidOut = append(relaidOut, el)
+		child.RenderX, child.RenderW = el.RenderX, el.RenderW
+	})
+	a.Fire(el, krb.AnimTriggerLoad)
+	clock.Advance(50 * time.Millisecond)
+	a.Apply()
+
+	if len(relaidOut) != 1 || relaidOut[0] != el {
+		t.Fatalf("relaid out %v, want only the resized element", relaidOut)
+	}
+	// The content fills the new width and moves with the translation applied after it.
+	if el.RenderW != 150 || child.RenderW != 150 {
+		t.Errorf("widths = %v and %v, want 150 for both", el.RenderW, child.RenderW)
+	}
+	if el.RenderX != 10 || child.RenderX != 10 {
+		t.Errorf("x = %v and %v, want 10 for both", el.RenderX, child.RenderX)
+	}
+}
+
+func TestAnimatorIgnoresUnplayableTracks(t *testing.T) {
+	el := animatedElement(t, krb.AnimTriggerLoad, func(b *krb.Builder) uint8 {
+		palette := func(index uint8) krb.Property {
+			return krb.Property{ID: krb.PropIDBgColor, ValueType: krb.ValTypeColor, Size: 1, Value: []byte{index}}
+		}
+		return b.AddTransition(100, krb.EasingLinear, palette(1), palette(2))
+	})
+	base := el.BgColor
+	clock := NewManualClock(time.Unix(0, 0))
+	a := NewAnimator(clock)
+	a.Fire(el, krb.AnimTriggerLoad)
+	clock.Advance(50 * time.Millisecond)
+	a.Apply()
+	if el.BgColor != base {
+		t.Errorf("palette color animation changed BgColor to %v", el.BgColor)
+	}
+	if len(a.players) != 1 || len(a.players[0].tracks) != 0 {
+		t.Errorf("players = %v, want one with no tracks", a.players)
+	}
+}
@@ -0,0 +1,561 @@
+// render/events.go
+package render
+
+import (
+	"log"
+	"time"
+
+	"github.com/kryonlabs/kryon-go-runtime/krb"
+)
+
+// DefaultLongPressThreshold is how long the pointer has to stay pressed on an
+// element before EventTypeLongPress fires.
+const DefaultLongPressThreshold = 500 * time.Millisecond
+
+// PointerInput is the state of the pointer in one frame.
+type PointerInput struct {
+	X, Y      float32
+	Pressed   bool      // The primary button went down this frame
+	Released  bool      // The primary button went up this frame
+	Modifiers Modifiers // Modifier keys held this frame
+
+	// Wheel scroll this frame in window pixels; positive values scroll the
+	// content right and down.
+	WheelX, WheelY float32
+}
+
+// MouseButton identifies a pointer button.
+type MouseButton uint8
+
+const (
+	MouseButtonNone MouseButton = iota
+	MouseButtonLeft
+	MouseButtonRight
+	MouseButtonMiddle
+)
+
+// Modifiers is a set of held modifier keys.
+type Modifiers uint8
+
+const (
+	ModShift Modifiers = 1 << iota
+	ModCtrl
+	ModAlt
+	ModSuper
+)
+
+// EventPhase is the stage of an event's propagation through the element tree.
+type EventPhase uint8
+
+const (
+	PhaseCapture  EventPhase = iota + 1 // Travelling from the root down to the target's parent
+	PhaseAtTarget                       // At the target itself
+	PhaseBubble                         // Travelling from the target's parent up to the root
+)
+
+// Event describes an event passed to an EventFunc.
+//
+// Events propagate like DOM events: capture listeners run from the root down
+// to the target, then the target's handlers run, then, for events that bubble,
+// the handlers of each ancestor up to the root. Focus, Blur and Hover do not
+// bubble.
+type Event struct {
+	Type          krb.EventType
+	Target        *RenderElement // Element the event fired on
+	CurrentTarget *RenderElement // Element whose handler is running
+	Phase         EventPhase
+	X, Y          float32     // Pointer position in window pixels
+	Button        MouseButton // Button of pointer Press, Release, LongPress and Click events; MouseButtonNone otherwise
+	Modifiers     Modifiers   // Modifier keys held when the event fired
+	Value         string      // Target's text for Change and Submit events
+	Hovered       bool        // For Hover events: true when the pointer entered Target, false when it left
+
+	propagationStopped bool
+	defaultPrevented   bool
+}
+
+// StopPropagation keeps the event from reaching further elements. Handlers of
+// the current element still run.
+func (ev *Event) StopPropagation() {
+	ev.propagationStopped = true
+}
+
+// PreventDefault cancels the runtime's default action for the event: a
+// prevented Press does not move focus and a prevented LongPress lets the
+// Click of the same press fire.
+func (ev *Event) PreventDefault() {
+	ev.defaultPrevented = true
+}
+
+// DefaultPrevented reports whether a handler called PreventDefault.
+func (ev *Event) DefaultPrevented() bool {
+	return ev.defaultPrevented
+}
+
+// Bubbles reports whether events of this type propagate up to the ancestors
+// of their target.
+func (ev *Event) Bubbles() bool {
+	switch ev.Type {
+	case krb.EventTypeFocus, krb.EventTypeBlur, krb.EventTypeHover:
+		return false
+	}
+	return true
+}
+
+// EventFunc handles events. Backends register it by name with
+// RegisterEventFunc and call it for every KRB event bound to that name.
+type EventFunc func(ev *Event)
+
+// EventDispatcher tracks pointer, hover and focus state across frames and
+// fires the KRB events and animation triggers that follow from it. Backends
+// feed it their raw input once per frame, so all of them dispatch events the
+// same way.
+//
+// Handlers run through Dispatch: an element's CustomEventHandler gets the
+// event first, and only if it does not handle it the element's KRB handler for
+// the event type runs.
+type EventDispatcher struct {
+	// LongPressThreshold is how long the pointer has to stay pressed on an
+	// element before EventTypeLongPress fires.
+	LongPressThreshold time.Duration
+
+	renderer       Renderer
+	animator       *Animator
+	handlers       map[string]EventFunc
+	customHandlers map[string]CustomComponentHandler
+
+	pointerX, pointerY float32
+	modifiers          Modifiers
+
+	listeners map[*RenderElement][]listener
+
+	hovered          map[*RenderElement]bool // Elements under the pointer with hover handlers or animations
+	pressed          *RenderElement          // Target of the current press, nil if the pointer is up
+	pressedAt        time.Time
+	longPressFired   bool
+	longPressHandled bool        // A handled, not prevented long press replaces the click of the same press
+	drag             *scrollDrag // Press that may scroll, nil if the pointer is up
+	dragged          bool        // The current press dragged content; it does not click
+	focused          *RenderElement
+	focusVisible     bool // Focus was last moved with the keyboard
+	textInput        *TextInput
+	roots            []*RenderElement
+}
+
+// NewEventDispatcher returns a dispatcher that runs the named handlers and
+// custom component handlers registered with renderer. The maps are shared, so
+// later registrations take effect immediately. Animation triggers are fired on
+// animator.
+func NewEventDispatcher(
+	renderer Renderer,
+	animator *Animator,
+	handlers map[string]EventFunc,
+	customHandlers map[string]CustomComponentHandler,
+) *EventDispatcher {
+	return &EventDispatcher{
+		LongPressThreshold: DefaultLongPressThreshold,
+		renderer:           renderer,
+		animator:           animator,
+		handlers:           handlers,
+		customHandlers:     customHandlers,
+		listeners:          make(map[*RenderElement][]listener),
+		hovered:            make(map[*RenderElement]bool),
+	}
+}
+
+// listener is an EventFunc added to an element with AddListener.
+type listener struct {
+	eventType krb.EventType
+	capture   bool
+	fn        EventFunc
+}
+
+// AddListener makes fn run for events of eventType reaching el. Capture
+// listeners run while the event travels down from the root, before any
+// handler of the target; other listeners run after el's KRB handler when the
+// event is at el or bubbles up to it.
+func (d *EventDispatcher) AddListener(el *RenderElement, eventType krb.EventType, capture bool, fn EventFunc) {
+	if el == nil || fn == nil {
+		return
+	}
+	d.listeners[el] = append(d.listeners[el], listener{eventType: eventType, capture: capture, fn: fn})
+}
+
+// Reset forgets all listeners and pointer, hover and focus state without firing
+// events, e.g. when a new tree replaces the elements they refer to.
+func (d *EventDispatcher) Reset() {
+	d.listeners = make(map[*RenderElement][]listener)
+	d.hovered = make(map[*RenderElement]bool)
+	d.pressed = nil
+	d.drag = nil
+	d.focused = nil
+	d.focusVisible = false
+	d.textInput = nil
+}
+
+// PointerTarget returns the element that reacts to pointer input on el: el or
+// its nearest ancestor that is interactive or has pointer event handlers.
+// Backends use it to pick the mouse cursor.
+func PointerTarget(el *RenderElement) *RenderElement {
+	for ; el != nil; el = el.Parent {
+		if el.IsInteractive || hasPointerHandler(el) {
+			return el
+		}
+	}
+	return nil
+}
+
+// commonAncestor returns the deepest element that is a or b or an ancestor of
+// both, or nil.
+func commonAncestor(a, b *RenderElement) *RenderElement {
+	for x := a; x != nil; x = x.Parent {
+		for y := b; y != nil; y = y.Parent {
+			if x == y {
+				return x
+			}
+		}
+	}
+	return nil
+}
+
+// ContainsPoint reports whether (x, y) lies within el's laid out bounds. Hidden
+// and empty elements contain no points.
+func ContainsPoint(el *RenderElement, x, y float32) bool {
+	return el.IsVisible && el.RenderW > 0 && el.RenderH > 0 &&
+		x >= el.RenderX && x < el.RenderX+el.RenderW &&
+		y >= el.RenderY && y < el.RenderY+el.RenderH
+}
+
+// HasEventHandler reports whether el has a KRB handler for eventType.
+func HasEventHandler(el *RenderElement, eventType krb.EventType) bool {
+	for _, eventInfo := range el.EventHandlers {
+		if eventInfo.EventType == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+func hasPointerHandler(el *RenderElement) bool {
+	for _, eventInfo := range el.EventHandlers {
+		switch eventInfo.EventType {
+		case krb.EventTypeClick, krb.EventTypePress, krb.EventTypeRelease, krb.EventTypeLongPress:
+			return true
+		}
+	}
+	return false
+}
+
+// ProcessPointer handles one frame of pointer input over elements. hits are
+// the elements under the pointer, topmost first, as returned by HitTest; the
+// first is the target of pointer events.
+//
+// Hover fires when the pointer enters and when it leaves an element. A press
+// fires Press on target and moves focus to target's nearest focusable
+// ancestor (or clears focus if there is none). Holding the press for
+// LongPressThreshold fires LongPress. Release fires on the pressed element,
+// followed by Click on the closest common ancestor of the pressed element and
+// target, unless a LongPress handler handled the press.
+//
+// The wheel scrolls the innermost scrolling element around target that can
+// still scroll that way. Pressing a scrollbar drags it instead of pressing
+// the content, and a press that moves DragScrollThreshold pixels drags the
+// content of the scrolling element around it; such a press does not click.
+func (d *EventDispatcher) ProcessPointer(elements []RenderElement, hits []*RenderElement, input PointerInput) {
+	d.pointerX, d.pointerY = input.X, input.Y
+	d.modifiers = input.Modifiers
+	d.updateHover(elements, hits)
+
+	var target *RenderElement
+	if len(hits) > 0 {
+		target = hits[0]
+	}
+
+	if input.WheelX != 0 || input.WheelY != 0 {
+		ScrollBy(scrollTarget(target, input.WheelX, input.WheelY), input.WheelX, input.WheelY)
+	}
+
+	if input.Pressed {
+		d.drag = beginScrollbarDrag(target, input.X, input.Y)
+		if d.drag == nil {
+			d.press(target)
+			d.drag = beginContentDrag(target, input.X, input.Y)
+		}
+	}
+
+	if d.drag != nil && d.drag.update(input.X, input.Y) {
+		d.dragged = true
+	}
+
+	if d.pressed != nil && !d.longPressFired && !d.dragged && d.animator.Now().Sub(d.pressedAt) >= d.LongPressThreshold {
+		d.longPressFired = true
+		ev := d.newEvent(d.pressed, krb.EventTypeLongPress)
+		d.longPressHandled = d.dispatch(ev) && !ev.DefaultPrevented()
+	}
+
+	if input.Released {
+		d.drag = nil
+	}
+	if input.Released && d.pressed != nil {
+		pressed := d.pressed
+		d.pressed = nil
+		d.dispatch(d.newEvent(pressed, krb.EventTypeRelease))
+		if clicked := commonAncestor(pressed, target); clicked != nil && !d.longPressHandled && !d.dragged {
+			d.dispatch(d.newEvent(clicked, krb.EventTypeClick))
+		}
+	}
+}
+
+// press starts a press on target.
+func (d *EventDispatcher) press(target *RenderElement) {
+	d.pressed = target
+	d.pressedAt = d.animator.Now()
+	d.longPressFired = false
+	d.longPressHandled = false
+	d.dragged = false
+	prevented := false
+	if target != nil {
+		for el := target; el != nil; el = el.Parent {
+			d.animator.Fire(el, krb.AnimTriggerClick)
+		}
+		ev := d.newEvent(target, krb.EventTypePress)
+		d.dispatch(ev)
+		prevented = ev.DefaultPrevented()
+	}
+	if !prevented {
+		d.focusVisible = false
+		d.Focus(focusableAncestor(target)) // Clicking outside any focusable element clears focus
+	}
+}
+
+// updateHover fires hover animations and events for elements the pointer
+// entered and reverses them for elements it left. An element is under the
+// pointer if it is in hits. Elements outside elements, like the rows of a
+// list, are tracked from when the pointer enters them.
+func (d *EventDispatcher) updateHover(elements []RenderElement, hits []*RenderElement) {
+	under := make(map[*RenderElement]bool, len(hits))
+	for _, el := range hits {
+		under[el] = true
+	}
+	inElements := make(map[*RenderElement]bool, len(elements))
+	for i := range elements {
+		inElements[&elements[i]] = true
+		d.setHovered(&elements[i], under[&elements[i]])
+	}
+	for _, el := range hits {
+		if !inElements[el] {
+			d.setHovered(el, true)
+		}
+	}
+	for el := range d.hovered {
+		if !inElements[el] && !under[el] {
+			d.setHovered(el, false)
+		}
+	}
+}
+
+// setHovered fires hover animations and events on el if isHovered changed.
+func (d *EventDispatcher) setHovered(el *RenderElement, isHovered bool) {
+	hasHoverAnim := HasTrigger(el, krb.AnimTriggerHover)
+	if !hasHoverAnim && !HasEventHandler(el, krb.EventTypeHover) {
+		return
+	}
+	if isHovered == d.hovered[el] {
+		return
+	}
+	if isHovered {
+		d.hovered[el] = true
+		if hasHoverAnim {
+			d.animator.Fire(el, krb.AnimTriggerHover)
+		}
+	} else {
+		delete(d.hovered, el)
+		if hasHoverAnim {
+			d.animator.Release(el, krb.AnimTriggerHover)
+		}
+	}
+	ev := d.newEvent(el, krb.EventTypeHover)
+	ev.Hovered = isHovered
+	d.dispatch(ev)
+}
+
+// IsHovered reports whether the pointer is over el. Only elements with hover
+// handlers or hover animations are tracked.
+func (d *EventDispatcher) IsHovered(el *RenderElement) bool {
+	return d.hovered[el]
+}
+
+// Focused returns the element with keyboard focus, or nil.
+func (d *EventDispatcher) Focused() *RenderElement {
+	return d.focused
+}
+
+// TextInput returns the editing state of the focused Input, or nil.
+func (d *EventDispatcher) TextInput() *TextInput {
+	return d.textInput
+}
+
+// Focus moves keyboard focus to el; nil clears it. The previously focused
+// element gets Blur and its focus animations reversed, then el gets Focus.
+func (d *EventDispatcher) Focus(el *RenderElement) {
+	if el == d.focused {
+		return
+	}
+	previous := d.focused
+	d.focused = el
+	d.textInput = nil
+	if previous != nil {
+		d.animator.Release(previous, krb.AnimTriggerFocus)
+		d.Dispatch(previous, krb.EventTypeBlur)
+	}
+	if el != nil {
+		if el.Header.Type == krb.ElemTypeInput {
+			d.textInput = NewTextInput(el, d.animator.Now())
+		}
+		d.animator.Fire(el, krb.AnimTriggerFocus)
+		d.Dispatch(el, krb.EventTypeFocus)
+	}
+}
+
+// ProcessText inserts typed text into the focused Input and fires Change.
+func (d *EventDispatcher) ProcessText(text string) {
+	if d.textInput == nil || text == "" {
+		return
+	}
+	if d.textInput.InsertText(text, d.animator.Now()) {
+		d.Dispatch(d.textInput.El, krb.EventTypeChange)
+	}
+}
+
+// ProcessKey handles a key press. Tab and Shift+Tab move focus, see
+// MoveFocus. Other keys edit the focused Input, firing Change or Submit, or,
+// for Enter and Space, activate any other focused element with a Click.
+func (d *EventDispatcher) ProcessKey(key Key, shift bool) {
+	switch {
+	case key == KeyTab:
+		d.MoveFocus(shift)
+	case d.textInput != nil:
+		el := d.textInput.El
+		changed, submitted := d.textInput.HandleKey(key, shift, d.animator.Now())
+		if changed {
+			d.Dispatch(el, krb.EventTypeChange)
+		}
+		if submitted {
+			d.Dispatch(el, krb.EventTypeSubmit)
+		}
+	case d.focused != nil && (key == KeyEnter || key == KeySpace):
+		d.activate(d.focused)
+	}
+}
+
+// Dispatch fires eventType on el and propagates it through el's ancestors. At
+// every element its custom component handler gets the event first; unless
+// that handled it, the element's KRB handler and listeners run. It reports
+// whether any handler ran. Applications can use it to fire EventTypeCustom.
+func (d *EventDispatcher) Dispatch(el *RenderElement, eventType krb.EventType) bool {
+	if el == nil {
+		return false
+	}
+	return d.dispatch(d.newEvent(el, eventType))
+}
+
+// newEvent returns an event of eventType on el carrying the current pointer
+// and modifier state.
+func (d *EventDispatcher) newEvent(el *RenderElement, eventType krb.EventType) *Event {
+	ev := &Event{
+		Type:      eventType,
+		Target:    el,
+		X:         d.pointerX,
+		Y:         d.pointerY,
+		Modifiers: d.modifiers,
+	}
+	switch eventType {
+	case krb.EventTypePress, krb.EventTypeRelease, krb.EventTypeLongPress, krb.EventTypeClick:
+		ev.Button = MouseButtonLeft
+	case krb.EventTypeChange, krb.EventTypeSubmit:
+		ev.Value = el.Text
+	}
+	return ev
+}
+
+// dispatch propagates ev along the path from the root to ev.Target.
+func (d *EventDispatcher) dispatch(ev *Event) bool {
+	var path []*RenderElement // Target first, root last
+	for el := ev.Target; el != nil; el = el.Parent {
+		path = append(path, el)
+	}
+	handled := false
+
+	ev.Phase = PhaseCapture
+	for i := len(path) - 1; i > 0 && !ev.propagationStopped; i-- {
+		handled = d.runListeners(path[i], ev, true) || handled
+	}
+	if ev.propagationStopped {
+		return handled
+	}
+
+	ev.Phase = PhaseAtTarget
+	handled = d.runListeners(ev.Target, ev, true) || handled
+	handled = d.runHandlers(ev.Target, ev) || handled
+	if !ev.Bubbles() {
+		return handled
+	}
+
+	ev.Phase = PhaseBubble
+	for i := 1; i < len(path) && !ev.propagationStopped; i++ {
+		handled = d.runHandlers(path[i], ev) || handled
+	}
+	return handled
+}
+
+// runListeners runs el's capture (or bubble) listeners for ev.
+func (d *EventDispatcher) runListeners(el *RenderElement, ev *Event, capture bool) bool {
+	ran := false
+	for _, l := range d.listeners[el] {
+		if l.eventType == ev.Type && l.capture == capture {
+			ev.CurrentTarget = el
+			l.fn(ev)
+			ran = true
+		}
+	}
+	return ran
+}
+
+// runHandlers runs el's custom component handler and, unless that handled ev,
+// el's KRB handler and bubble listeners. A custom handler that handles an
+// event also stops its propagation.
+func (d *EventDispatcher) runHandlers(el *RenderElement, ev *Event) bool {
+	ev.CurrentTarget = el
+	componentID, isCustomInstance := GetCustomPropertyValue(el, ComponentNameKey, el.DocRef)
+	if isCustomInstance && componentID != "" {
+		if customHandler, handlerExists := d.customHandlers[componentID]; handlerExists {
+			if eventInterface, implementsEvent := customHandler.(CustomEventHandler); implementsEvent {
+				handled, err := eventInterface.HandleEvent(el, ev.Type, d.renderer)
+				if err != nil {
+					log.Printf("ERROR Dispatch: Custom handler for '%s' [%s] returned error for event %d: %v",
+						componentID, el.SourceElementName, ev.Type, err)
+				}
+				if handled {
+					ev.StopPropagation()
+					return true
+				}
+			}
+		}
+	}
+
+	ran := false
+	for _, eventInfo := range el.EventHandlers {
+		if eventInfo.EventType != ev.Type {
+			continue
+		}
+		if goHandlerFunc, found := d.handlers[eventInfo.HandlerName]; found {
+			goHandlerFunc(ev)
+			ran = true
+		} else {
+			log.Printf("Warn Dispatch: Standard KRB handler named '%s' (for %s) is not registered.",
+				eventInfo.HandlerName, el.SourceElementName)
+		}
+		break // One handler per element and event type
+	}
+	return d.runListeners(el, ev, false) || ran
+}
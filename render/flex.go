@@ -0,0 +1,72 @@
+// render/flex.go
+package render
+
+import (
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/kryonlabs/kryon-go-runtime/krb"
+)
+
+// Custom properties weighting how a flow child's main-axis size adapts to its
+// line, like CSS flex-grow, flex-shrink and flex-basis. Grow and shrink are
+// non-negative numbers; the basis is "auto", unscaled pixels with an optional
+// "px" suffix, or a percentage of the parent's content box.
+const (
+	FlexGrowKey   = "flexGrow"
+	FlexShrinkKey = "flexShrink"
+	FlexBasisKey  = "flexBasis"
+)
+
+// Flex is how a flow child's main-axis size adapts to the space in its line.
+//
+// Free space is shared among growing children in proportion to Grow, and
+// overflow is taken from shrinking children in proportion to Shrink times
+// their basis. Children shrink only if they set a Shrink, so lines that
+// overflow keep overflowing by default.
+type Flex struct {
+	Grow   float32 // Without it, krb.LayoutGrowBit counts as a Grow of 1
+	Shrink float32
+	// Basis is the size before growing or shrinking, if HasBasis: unscaled
+	// pixels, or percent of the parent's content box if BasisPercent.
+	// Without a basis, children start from their own size, and growing
+	// children from 0 unless their line has no space to grow into.
+	Basis                  float32
+	HasBasis, BasisPercent bool
+}
+
+// resolveElementFlex applies the flex custom properties.
+func resolveElementFlex(doc *krb.Document, el *RenderElement) {
+	el.Flex = Flex{}
+	for _, f := range []struct {
+		key string
+		dst *float32
+	}{
+		{FlexGrowKey, &el.Flex.Grow},
+		{FlexShrinkKey, &el.Flex.Shrink},
+	} {
+		value, found := GetCustomPropertyValue(el, f.key, doc)
+		if !found {
+			continue
+		}
+		n, err := strconv.ParseFloat(strings.TrimSpace(value), 32)
+		if err != nil || n < 0 {
+			log.Printf("WARN resolveElementFlex: Invalid %s '%s' on '%s'.", f.key, value, el.SourceElementName)
+			continue
+		}
+		*f.dst = float32(n)
+	}
+	value, found := GetCustomPropertyValue(el, FlexBasisKey, doc)
+	if !found || strings.TrimSpace(value) == "auto" {
+		return
+	}
+	value = strings.TrimSpace(value)
+	number, percent := strings.TrimSuffix(value, "%"), strings.HasSuffix(value, "%")
+	n, err := strconv.ParseFloat(strings.TrimSuffix(number, "px"), 32)
+	if err != nil || n < 0 {
+		log.Printf("WARN resolveElementFlex: Invalid %s '%s' on '%s'.", FlexBasisKey, value, el.SourceElementName)
+		return
+	}
+	el.Flex.Basis, el.Flex.HasBasis, el.Flex.BasisPercent = float32(n), true, percent
+}
@@ -0,0 +1,147 @@
+// render/focus.go
+package render
+
+import (
+	"image/color"
+	"log"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kryonlabs/kryon-go-runtime/krb"
+)
+
+// TabIndexKey is the custom property setting an element's RenderElement.TabIndex,
+// like HTML's tabindex attribute: any value makes the element focusable, a
+// negative one keeps it out of Tab order and positive ones come before all
+// other elements, in ascending order.
+const TabIndexKey = "tabIndex"
+
+// The focus ring is drawn inside the bounds of the focused element while
+// focus was last moved with the keyboard.
+const FocusRingWidth = 2 // Unscaled pixels
+
+var FocusRingColor = color.RGBA{R: 0x4C, G: 0x9A, B: 0xFF, A: 0xFF}
+
+// resolveElementTabIndex applies the TabIndexKey custom property.
+func resolveElementTabIndex(doc *krb.Document, el *RenderElement) {
+	el.Focusable = el.IsInteractive
+	value, found := GetCustomPropertyValue(el, TabIndexKey, doc)
+	if !found {
+		return
+	}
+	tabIndex, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		log.Printf("WARN resolveElementTabIndex: Invalid %s '%s' on '%s'.", TabIndexKey, value, el.SourceElementName)
+		return
+	}
+	el.TabIndex = tabIndex
+	el.Focusable = true
+}
+
+// FocusOrder returns the visible elements under roots that Tab moves focus
+// through, in the order it visits them: elements with a positive TabIndex by
+// ascending TabIndex, then those with TabIndex 0. Ties keep document order.
+func FocusOrder(roots []*RenderElement) []*RenderElement {
+	var order []*RenderElement
+	var walk func(el *RenderElement)
+	walk = func(el *RenderElement) {
+		if el == nil || !el.IsVisible {
+			return
+		}
+		if el.Focusable && el.TabIndex >= 0 {
+			order = append(order, el)
+		}
+		for _, child := range el.Children {
+			walk(child)
+		}
+	}
+	for _, root := range roots {
+		walk(root)
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return tabRank(order[i]) < tabRank(order[j])
+	})
+	return order
+}
+
+func tabRank(el *RenderElement) int {
+	if el.TabIndex == 0 {
+		return math.MaxInt
+	}
+	return el.TabIndex
+}
+
+// focusableAncestor returns el or its nearest focusable ancestor.
+func focusableAncestor(el *RenderElement) *RenderElement {
+	for ; el != nil; el = el.Parent {
+		if el.Focusable {
+			return el
+		}
+	}
+	return nil
+}
+
+// shown reports whether el and all its ancestors are visible.
+func shown(el *RenderElement) bool {
+	for ; el != nil; el = el.Parent {
+		if !el.IsVisible {
+			return false
+		}
+	}
+	return true
+}
+
+// SetRoots sets the trees Tab moves focus through. Backends call it whenever
+// they lay out new roots.
+func (d *EventDispatcher) SetRoots(roots []*RenderElement) {
+	d.roots = roots
+}
+
+// MoveFocus moves focus to the next element in FocusOrder, or with backward
+// the previous one, wrapping around at the ends, and scrolls it into view.
+// Focus moved this way shows the focus ring.
+func (d *EventDispatcher) MoveFocus(backward bool) {
+	order := FocusOrder(d.roots)
+	if len(order) == 0 {
+		return
+	}
+	current := -1
+	for i, el := range order {
+		if el == d.focused {
+			current = i
+			break
+		}
+	}
+	next := 0
+	switch {
+	case current < 0 && backward:
+		next = len(order) - 1
+	case current >= 0 && backward:
+		next = (current - 1 + len(order)) % len(order)
+	case current >= 0:
+		next = (current + 1) % len(order)
+	}
+	d.focusVisible = true
+	d.Focus(order[next])
+	ScrollIntoView(order[next])
+}
+
+// FocusRingTarget returns the element to draw the focus ring around: the
+// focused element if focus was last moved with the keyboard and it is shown.
+func (d *EventDispatcher) FocusRingTarget() *RenderElement {
+	if !d.focusVisible || d.focused == nil || !shown(d.focused) {
+		return nil
+	}
+	return d.focused
+}
+
+// activate fires Click on el, as Enter or Space do on a focused element that
+// is not an Input.
+func (d *EventDispatcher) activate(el *RenderElement) {
+	d.animator.Fire(el, krb.AnimTriggerClick)
+	ev := d.newEvent(el, krb.EventTypeClick)
+	ev.Button = MouseButtonNone
+	d.dispatch(ev)
+}
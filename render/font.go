@@ -0,0 +1,238 @@
+// render/font.go
+package render
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+
+	"github.com/kryonlabs/kryon-go-runtime/krb"
+)
+
+// FontFamilyKey is the custom property naming the font family of an element,
+// an alternative to krb.PropIDFontFamily for KRY sources without style support.
+const FontFamilyKey = "fontFamily"
+
+// FontProvider measures text and describes glyphs for layout and drawing.
+// Backends implement it on top of whatever font machinery they draw with, so
+// the layout engine sizes text exactly like it will be drawn.
+type FontProvider interface {
+	// MeasureText returns the advance width of a single line of text in pixels
+	// at fontSize pixels.
+	MeasureText(text string, fontSize float32) float32
+	// LineHeight returns the font's recommended distance in pixels between the
+	// tops of two consecutive lines at fontSize pixels.
+	LineHeight(fontSize float32) float32
+	// Glyph returns the metrics of r at fontSize pixels. ok is false if the font
+	// has no glyph for r.
+	Glyph(r rune, fontSize float32) (metrics GlyphMetrics, ok bool)
+}
+
+// GlyphMetrics describes a single glyph. Bounds are relative to the pen
+// position at the top-left of the line box, with y growing downwards.
+type GlyphMetrics struct {
+	Advance    float32 // Horizontal distance to the next glyph's pen position
+	MinX, MinY float32 // Top-left corner of the glyph's ink
+	MaxX, MaxY float32 // Bottom-right corner of the glyph's ink
+}
+
+// FontSet picks the font an element's text is measured and drawn with.
+type FontSet interface {
+	FontFor(el *RenderElement) FontProvider
+}
+
+// SingleFont is a FontSet that uses the same font for every element.
+type SingleFont struct {
+	FontProvider
+}
+
+func (f SingleFont) FontFor(el *RenderElement) FontProvider {
+	return f.FontProvider
+}
+
+// FindFontResource returns the index of the ResTypeFont resource for family.
+// A resource matches if its name equals family or if its file name without
+// extension does, ignoring case, so "Inter" matches "fonts/Inter.ttf".
+func FindFontResource(doc *krb.Document, family string) (uint8, bool) {
+	if doc == nil || family == "" {
+		return InvalidResourceIndex, false
+	}
+	for i, res := range doc.Resources {
+		if res.Type != krb.ResTypeFont || i >= InvalidResourceIndex {
+			continue
+		}
+		name, ok := StringAt(doc, res.NameIndex)
+		if !ok {
+			continue
+		}
+		base := filepath.Base(name)
+		if name == family || strings.EqualFold(strings.TrimSuffix(base, filepath.Ext(base)), family) {
+			return uint8(i), true
+		}
+	}
+	return InvalidResourceIndex, false
+}
+
+// FontResourceValue resolves a krb.PropIDFontFamily value, either a resource
+// index or a family name, to the index of a ResTypeFont resource.
+func FontResourceValue(prop *krb.Property, doc *krb.Document) (uint8, bool) {
+	if prop == nil || doc == nil {
+		return InvalidResourceIndex, false
+	}
+	idx, ok := ByteValue(prop)
+	if !ok {
+		return InvalidResourceIndex, false
+	}
+	switch prop.ValueType {
+	case krb.ValTypeResource:
+		if int(idx) < len(doc.Resources) && doc.Resources[idx].Type == krb.ResTypeFont {
+			return idx, true
+		}
+		log.Printf("WARN FontResourceValue: Resource %d is not a font resource.", idx)
+	case krb.ValTypeString:
+		family, strOk := StringAt(doc, idx)
+		if !strOk {
+			return InvalidResourceIndex, false
+		}
+		if resIdx, found := FindFontResource(doc, family); found {
+			return resIdx, true
+		}
+		log.Printf("WARN FontResourceValue: No font resource found for family '%s'.", family)
+	}
+	return InvalidResourceIndex, false
+}
+
+var (
+	bundledFontOnce sync.Once
+	bundledFont     *opentype.Font
+	bundledFontErr  error
+)
+
+// TTFFontProvider is a pure-Go FontProvider for TrueType and OpenType fonts.
+// It needs no window or GPU, so layout computed with it is reproducible in tests.
+type TTFFontProvider struct {
+	font *opentype.Font
+
+	mu    sync.Mutex
+	faces map[int]font.Face // One face per pixel size
+	buf   sfnt.Buffer
+}
+
+// NewTTFFontProvider parses TTF or OTF font data.
+func NewTTFFontProvider(data []byte) (*TTFFontProvider, error) {
+	f, err := opentype.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("NewTTFFontProvider: %w", err)
+	}
+	return newTTFFontProvider(f), nil
+}
+
+// NewBundledFontProvider returns a provider for the Go Regular font shipped with
+// x/image, so text measures and renders identically on every machine without
+// system fonts.
+func NewBundledFontProvider() *TTFFontProvider {
+	bundledFontOnce.Do(func() {
+		bundledFont, bundledFontErr = opentype.Parse(goregular.TTF)
+	})
+	if bundledFontErr != nil {
+		log.Printf("ERROR NewBundledFontProvider: Failed to parse bundled font: %v. Text will have no size.", bundledFontErr)
+	}
+	return newTTFFontProvider(bundledFont)
+}
+
+func newTTFFontProvider(f *opentype.Font) *TTFFontProvider {
+	return &TTFFontProvider{font: f, faces: make(map[int]font.Face)}
+}
+
+// Face returns the font face for the given pixel size, or nil if the font could
+// not be loaded. Faces are cached until Close.
+func (p *TTFFontProvider) Face(pixelSize int) font.Face {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.faceLocked(pixelSize)
+}
+
+func (p *TTFFontProvider) faceLocked(pixelSize int) font.Face {
+	if p.font == nil {
+		return nil
+	}
+	if pixelSize < 1 {
+		pixelSize = 1
+	}
+	if face, ok := p.faces[pixelSize]; ok {
+		return face
+	}
+	face, err := opentype.NewFace(p.font, &opentype.FaceOptions{
+		Size:    float64(pixelSize),
+		DPI:     72, // 1pt == 1px
+		Hinting: font.HintingFull,
+	})
+	if err != nil {
+		log.Printf("ERROR TTFFontProvider: Failed to create font face of size %d: %v", pixelSize, err)
+		return nil
+	}
+	p.faces[pixelSize] = face
+	return face
+}
+
+func (p *TTFFontProvider) MeasureText(text string, fontSize float32) float32 {
+	face := p.Face(int(fontSize))
+	if face == nil {
+		return 0
+	}
+	return float32(font.MeasureString(face, text).Ceil())
+}
+
+func (p *TTFFontProvider) LineHeight(fontSize float32) float32 {
+	face := p.Face(int(fontSize))
+	if face == nil {
+		return 0
+	}
+	return float32(face.Metrics().Height.Ceil())
+}
+
+func (p *TTFFontProvider) Glyph(r rune, fontSize float32) (GlyphMetrics, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	face := p.faceLocked(int(fontSize))
+	if face == nil {
+		return GlyphMetrics{}, false
+	}
+	if index, err := p.font.GlyphIndex(&p.buf, r); err != nil || index == 0 {
+		return GlyphMetrics{}, false
+	}
+	bounds, advance, ok := face.GlyphBounds(r)
+	if !ok {
+		return GlyphMetrics{}, false
+	}
+	ascent := face.Metrics().Ascent
+	return GlyphMetrics{
+		Advance: fixedToFloat(advance),
+		MinX:    fixedToFloat(bounds.Min.X),
+		MinY:    fixedToFloat(bounds.Min.Y + ascent),
+		MaxX:    fixedToFloat(bounds.Max.X),
+		MaxY:    fixedToFloat(bounds.Max.Y + ascent),
+	}, true
+}
+
+// Close releases all cached faces. The provider stays usable.
+func (p *TTFFontProvider) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for size, face := range p.faces {
+		face.Close()
+		delete(p.faces, size)
+	}
+}
+
+func fixedToFloat(v fixed.Int26_6) float32 {
+	return float32(v) / 64
+}
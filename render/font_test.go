@@ -0,0 +1,76 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/kryonlabs/kryon-go-runtime/krb"
+)
+
+// The bundled Go Regular font makes these sizes exact on every machine.
+
+func TestTTFFontProviderMeasureText(t *testing.T) {
+	p := NewBundledFontProvider()
+	defer p.Close()
+
+	if got := p.MeasureText("", 18); got != 0 {
+		t.Errorf("empty text measures %v, want 0", got)
+	}
+	if got := p.MeasureText("Hello", 18); got != 43 {
+		t.Errorf("MeasureText(Hello, 18) = %v, want 43", got)
+	}
+	if small, large := p.MeasureText("Hello", 18), p.MeasureText("Hello", 36); large != 2*small {
+		t.Errorf("text at 36px is %v wide, want twice the %v at 18px", large, small)
+	}
+	if got := p.LineHeight(18); got != 21 {
+		t.Errorf("LineHeight(18) = %v, want 21", got)
+	}
+}
+
+func TestTTFFontProviderGlyph(t *testing.T) {
+	p := NewBundledFontProvider()
+	defer p.Close()
+
+	g, ok := p.Glyph('H', 18)
+	if !ok {
+		t.Fatal("no glyph for 'H'")
+	}
+	if g.Advance != 13 {
+		t.Errorf("'H' advance = %v, want 13", g.Advance)
+	}
+	// The ink sits inside the line box, below its top and left of the advance.
+	if g.MinX < 0 || g.MaxX > g.Advance || g.MinY <= 0 || g.MaxY > p.LineHeight(18) || g.MinY >= g.MaxY {
+		t.Errorf("'H' bounds %+v are outside the line box", g)
+	}
+
+	var sum float32
+	for _, r := range "Hello" {
+		g, _ := p.Glyph(r, 18)
+		sum += g.Advance
+	}
+	if want := p.MeasureText("Hello", 18); sum != want {
+		t.Errorf("glyph advances add up to %v, want MeasureText's %v", sum, want)
+	}
+
+	if _, ok := p.Glyph('中', 18); ok {
+		t.Error("the bundled font reports a glyph it does not have")
+	}
+}
+
+func TestFindFontResource(t *testing.T) {
+	b := krb.NewBuilder()
+	b.AddElement(krb.ElemTypeApp)
+	b.AddExternalResource(krb.ResTypeImage, "Inter.png")
+	b.AddExternalResource(krb.ResTypeFont, "fonts/Inter.ttf")
+	doc, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, family := range []string{"Inter", "inter", "fonts/Inter.ttf"} {
+		if idx, ok := FindFontResource(doc, family); !ok || idx != 1 {
+			t.Errorf("FindFontResource(%q) = %d, %v, want the font resource 1", family, idx, ok)
+		}
+	}
+	if _, ok := FindFontResource(doc, "Roboto"); ok {
+		t.Error("found a resource for an unknown family")
+	}
+}
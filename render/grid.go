@@ -0,0 +1,157 @@
+// render/grid.go
+package render
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/kryonlabs/kryon-go-runtime/krb"
+)
+
+// Custom properties defining the tracks of a krb.ElemTypeGrid. Each is a
+// space separated list of track sizes, see ParseGridTracks.
+const (
+	GridColumnsKey = "gridColumns"
+	GridRowsKey    = "gridRows"
+)
+
+// Custom properties placing a child of a grid. Columns and rows are numbered
+// from 1, like CSS grid lines; children that leave them out are placed in the
+// next free cells, row by row. Spans default to 1. The alignments are "start",
+// "center", "end" or "stretch", the default, which sizes children without an
+// explicit width or height to their cell.
+const (
+	GridColumnKey     = "gridColumn"
+	GridRowKey        = "gridRow"
+	GridColumnSpanKey = "gridColumnSpan"
+	GridRowSpanKey    = "gridRowSpan"
+	JustifySelfKey    = "justifySelf" // Horizontal alignment in the cell
+	AlignSelfKey      = "alignSelf"   // Vertical alignment in the cell
+)
+
+// GridSizing is how the size of a grid track is determined.
+type GridSizing uint8
+
+const (
+	GridTrackAuto     GridSizing = iota // Fits the children that span only this track
+	GridTrackFixed                      // Value unscaled pixels
+	GridTrackPercent                    // Value percent of the grid's content box
+	GridTrackFraction                   // Value shares of the space the other tracks leave
+)
+
+// GridTrack is the size of one column or row of a grid.
+type GridTrack struct {
+	Sizing GridSizing
+	Value  float32
+}
+
+// GridCell is where a child of a grid is placed and how it is aligned in its
+// cell. Column and Row are 1-based; 0 lets the grid place the child.
+type GridCell struct {
+	Column, Row         int
+	ColumnSpan, RowSpan int   // At least 1
+	JustifySelf         uint8 // krb.LayoutAlignStart, Center, End or Stretch
+	AlignSelf           uint8
+}
+
+// ParseGridTracks parses a track list like "120 25% 1fr auto": fixed sizes in
+// unscaled pixels, with an optional "px" suffix, percentages of the grid's
+// content box, fractions of the remaining space, and tracks sized to fit
+// their content.
+func ParseGridTracks(s string) ([]GridTrack, error) {
+	fields := strings.Fields(s)
+	tracks := make([]GridTrack, 0, len(fields))
+	for _, field := range fields {
+		track := GridTrack{Sizing: GridTrackFixed}
+		number := strings.TrimSuffix(field, "px")
+		switch {
+		case field == "auto":
+			tracks = append(tracks, GridTrack{Sizing: GridTrackAuto})
+			continue
+		case strings.HasSuffix(field, "fr"):
+			track.Sizing, number = GridTrackFraction, strings.TrimSuffix(field, "fr")
+		case strings.HasSuffix(field, "%"):
+			track.Sizing, number = GridTrackPercent, strings.TrimSuffix(field, "%")
+		}
+		value, err := strconv.ParseFloat(number, 32)
+		if err != nil || value < 0 {
+			return nil, fmt.Errorf("invalid grid track '%s'", field)
+		}
+		track.Value = float32(value)
+		tracks = append(tracks, track)
+	}
+	return tracks, nil
+}
+
+// resolveElementGrid applies the grid custom properties: the tracks of a
+// grid, and the placement of any element in a parent grid.
+func resolveElementGrid(doc *krb.Document, el *RenderElement) {
+	el.GridCell = GridCell{ColumnSpan: 1, RowSpan: 1, JustifySelf: krb.LayoutAlignStretch, AlignSelf: krb.LayoutAlignStretch}
+	if el.Header.Type == krb.ElemTypeGrid {
+		for _, t := range []struct {
+			key string
+			dst *[]GridTrack
+		}{
+			{GridColumnsKey, &el.GridColumns},
+			{GridRowsKey, &el.GridRows},
+		} {
+			value, found := GetCustomPropertyValue(el, t.key, doc)
+			if !found {
+				continue
+			}
+			tracks, err := ParseGridTracks(value)
+			if err != nil {
+				log.Printf("WARN resolveElementGrid: %s '%s' on '%s': %v.", t.key, value, el.SourceElementName, err)
+				continue
+			}
+			*t.dst = tracks
+		}
+	}
+	for _, p := range []struct {
+		key string
+		dst *int
+		min int
+	}{
+		{GridColumnKey, &el.GridCell.Column, 1},
+		{GridRowKey, &el.GridCell.Row, 1},
+		{GridColumnSpanKey, &el.GridCell.ColumnSpan, 1},
+		{GridRowSpanKey, &el.GridCell.RowSpan, 1},
+	} {
+		value, found := GetCustomPropertyValue(el, p.key, doc)
+		if !found {
+			continue
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || n < p.min {
+			log.Printf("WARN resolveElementGrid: Invalid %s '%s' on '%s'.", p.key, value, el.SourceElementName)
+			continue
+		}
+		*p.dst = n
+	}
+	for _, a := range []struct {
+		key string
+		dst *uint8
+	}{
+		{JustifySelfKey, &el.GridCell.JustifySelf},
+		{AlignSelfKey, &el.GridCell.AlignSelf},
+	} {
+		value, found := GetCustomPropertyValue(el, a.key, doc)
+		if !found {
+			continue
+		}
+		switch strings.TrimSpace(value) {
+		case "start":
+			*a.dst = krb.LayoutAlignStart
+		case "center":
+			*a.dst = krb.LayoutAlignCenter
+		case "end":
+			*a.dst = krb.LayoutAlignEnd
+		case "stretch":
+			*a.dst = krb.LayoutAlignStretch
+		default:
+			log.Printf("WARN resolveElementGrid: Invalid %s '%s' on '%s'.", a.key, value, el.SourceElementName)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+// render/hittest.go
+package render
+
+import (
+	"math"
+	"sort"
+
+	"github.com/kryonlabs/kryon-go-runtime/krb"
+)
+
+// Rect is an axis-aligned rectangle in window pixels.
+type Rect struct {
+	X, Y, W, H float32
+}
+
+// unclipped is the clip rectangle of elements no ancestor clips.
+var unclipped = Rect{X: -math.MaxFloat32 / 2, Y: -math.MaxFloat32 / 2, W: math.MaxFloat32, H: math.MaxFloat32}
+
+// Contains reports whether (x, y) lies within r.
+func (r Rect) Contains(x, y float32) bool {
+	return x >= r.X && x < r.X+r.W && y >= r.Y && y < r.Y+r.H
+}
+
+// Intersect returns the part of r that lies within o. The result is empty, not
+// negative, if they do not overlap.
+func (r Rect) Intersect(o Rect) Rect {
+	x0, y0 := max(r.X, o.X), max(r.Y, o.Y)
+	x1, y1 := min(r.X+r.W, o.X+o.W), min(r.Y+r.H, o.Y+o.H)
+	return Rect{X: x0, Y: y0, W: max(x1-x0, 0), H: max(y1-y0, 0)}
+}
+
+// ClipsChildren reports whether el's children are clipped to its padding box.
+func ClipsChildren(el *RenderElement) bool {
+	return el.Overflow != krb.OverflowVisible
+}
+
+// PaddingBox returns el's laid out bounds minus its borders, the area its
+// children are clipped to when it ClipsChildren.
+func PaddingBox(el *RenderElement, scale float32) Rect {
+	top, right := scaledRound(el.BorderWidths[0], scale), scaledRound(el.BorderWidths[1], scale)
+	bottom, left := scaledRound(el.BorderWidths[2], scale), scaledRound(el.BorderWidths[3], scale)
+	return Rect{
+		X: el.RenderX + left,
+		Y: el.RenderY + top,
+		W: max(el.RenderW-left-right, 0),
+		H: max(el.RenderH-top-bottom, 0),
+	}
+}
+
+// scaledRound scales an unscaled pixel value to whole window pixels, like the
+// backends do for borders and padding.
+func scaledRound(value uint8, scale float32) float32 {
+	return float32(math.Round(float64(value) * float64(scale)))
+}
+
+// PaintOrder returns children in the order they are drawn: by ascending
+// ZIndex, and in document order among equal ZIndex. The result may share its
+// backing array with children and must not be modified.
+func PaintOrder(children []*RenderElement) []*RenderElement {
+	sorted := true
+	for i := 1; i < len(children); i++ {
+		if children[i].ZIndex < children[i-1].ZIndex {
+			sorted = false
+			break
+		}
+	}
+	if sorted {
+		return children
+	}
+	ordered := append([]*RenderElement(nil), children...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].ZIndex < ordered[j].ZIndex
+	})
+	return ordered
+}
+
+// HitTest returns every element under (x, y), topmost first: the reverse of
+// the order the trees under roots are drawn in. Hidden elements and their
+// subtrees are skipped, as are points an ancestor's overflow clips away and
+// points outside an element's rounded corners. The first element, if any, is
+// the target of pointer events at (x, y).
+func HitTest(roots []*RenderElement, x, y, scale float32) []*RenderElement {
+	var hits []*RenderElement
+	for i := len(roots) - 1; i >= 0; i-- {
+		hits = hitTest(roots[i], x, y, scale, unclipped, nil, hits)
+	}
+	return hits
+}
+
+// roundedClip is the padding box, with rounded corners, of an ancestor that
+// clips its children.
+type roundedClip struct {
+	box   Rect
+	radii CornerRadii
+}
+
+func hitTest(el *RenderElement, x, y, scale float32, clip Rect, rounded []roundedClip, hits []*RenderElement) []*RenderElement {
+	if el == nil || !el.IsVisible {
+		return hits
+	}
+	childClip, childRounded := clip, rounded
+	if ClipsChildren(el) {
+		box := PaddingBox(el, scale)
+		childClip = clip.Intersect(box)
+		if radii := PaddingBoxRadii(el, scale); !radii.IsZero() {
+			childRounded = append(rounded[:len(rounded):len(rounded)], roundedClip{box, radii})
+		}
+	}
+	children := PaintOrder(el.Children)
+	for i := len(children) - 1; i >= 0; i-- {
+		hits = hitTest(children[i], x, y, scale, childClip, childRounded, hits)
+	}
+	if ContainsPoint(el, x, y) && clip.Contains(x, y) && containsRounded(el, x, y, scale) {
+		for _, c := range rounded {
+			if !RoundedContains(c.box, c.radii, x, y) {
+				return hits
+			}
+		}
+		hits = append(hits, el)
+	}
+	return hits
+}
+
+// containsRounded reports whether (x, y), within el's bounds, is not cut off by
+// its rounded corners.
+func containsRounded(el *RenderElement, x, y, scale float32) bool {
+	radii := BorderRadii(el, scale)
+	if radii.IsZero() {
+		return true
+	}
+	return RoundedContains(Rect{X: el.RenderX, Y: el.RenderY, W: el.RenderW, H: el.RenderH}, radii, x, y)
+}
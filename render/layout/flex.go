@@ -0,0 +1,168 @@
+// render/layout/flex.go
+package layout
+
+import (
+	"math"
+
+	"github.com/kryonlabs/kryon-go-runtime/krb"
+	"github.com/kryonlabs/kryon-go-runtime/render"
+)
+
+// flexGrow returns el's grow factor.
+func flexGrow(el *render.RenderElement) float32 {
+	if el.Flex.Grow == 0 && el.Header.LayoutGrow() {
+		return 1
+	}
+	return el.Flex.Grow
+}
+
+// flexItem is a flow child while its line's main-axis sizes are resolved.
+type flexItem struct {
+	basis, min, max float32
+	size            float32
+	frozen          bool
+}
+
+// resolveFlexSizes returns the main-axis sizes of the children of one line
+// sharing space window pixels, with the gaps already taken off. contentW and
+// contentH are the parent's content box, which percentages refer to.
+//
+// Children start from their basis, clamped to their minimum and maximum size.
+// Free space then goes to growing children by their grow factors, or overflow
+// is taken from shrinking children by their shrink factors times their basis.
+// A child that would pass its minimum or maximum size is frozen there and the
+// rest is shared among the others again, until every child fits its limits.
+func (e *Engine) resolveFlexSizes(line []*render.RenderElement, isMainAxisHorizontal bool, space, contentW, contentH float32) []float32 {
+	items := make([]flexItem, len(line))
+	hypothetical := float32(0)
+	for i, child := range line {
+		items[i] = e.flexItem(child, isMainAxisHorizontal, contentW, contentH, false)
+		hypothetical += items[i].size
+	}
+	growing := hypothetical < space
+	if !growing {
+		// Without space to grow into, growing children keep their own size.
+		for i, child := range line {
+			items[i] = e.flexItem(child, isMainAxisHorizontal, contentW, contentH, true)
+		}
+	}
+
+	for i, child := range line {
+		factor := muxFloat32(growing, flexGrow(child), child.Flex.Shrink)
+		items[i].frozen = factor == 0
+	}
+	for {
+		free, factors := space, float32(0)
+		for i, child := range line {
+			if items[i].frozen {
+				free -= items[i].size
+			} else {
+				free -= items[i].basis
+				factors += muxFloat32(growing, flexGrow(child), child.Flex.Shrink*items[i].basis)
+			}
+		}
+		if factors == 0 {
+			break
+		}
+
+		violation := float32(0)
+		for i, child := range line {
+			item := &items[i]
+			if item.frozen {
+				continue
+			}
+			factor := muxFloat32(growing, flexGrow(child), child.Flex.Shrink*item.basis)
+			target := item.basis + free*factor/factors
+			item.size = float32(math.Min(math.Max(float64(target), float64(item.min)), float64(item.max)))
+			violation += item.size - target
+		}
+
+		// Freeze the children whose limits were hit in the direction of the
+		// total violation, or all of them if none was, and share again.
+		done := true
+		for i := range items {
+			item := &items[i]
+			if item.frozen {
+				continue
+			}
+			clamped := (violation > 0 && item.size == item.min) || (violation < 0 && item.size == item.max)
+			if violation == 0 || clamped {
+				item.frozen = true
+			} else {
+				done = false
+			}
+		}
+		if done {
+			break
+		}
+	}
+
+	sizes := make([]float32, len(items))
+	for i := range items {
+		sizes[i] = items[i].size
+	}
+	return sizes
+}
+
+// flexItem returns child's basis and limits along the main axis, with size
+// set to the basis clamped to its limits. A growing child without a basis
+// starts from 0, or from its own size with ownSize.
+func (e *Engine) flexItem(child *render.RenderElement, isMainAxisHorizontal bool, contentW, contentH float32, ownSize bool) flexItem {
+	scale := e.ScaleFactor
+	mainContentSize := muxFloat32(isMainAxisHorizontal, contentW, contentH)
+	item := flexItem{max: math.MaxFloat32}
+	switch {
+	case child.Flex.HasBasis && child.Flex.BasisPercent:
+		item.basis = child.Flex.Basis / 100 * mainContentSize
+	case child.Flex.HasBasis:
+		item.basis = child.Flex.Basis * scale
+	case flexGrow(child) > 0 && !ownSize:
+		item.basis = 0
+	default:
+		item.basis = muxFloat32(isMainAxisHorizontal, child.RenderW, child.RenderH)
+	}
+
+	minID, maxID := krb.PropIDMinHeight, krb.PropIDMaxHeight
+	if isMainAxisHorizontal {
+		minID, maxID = krb.PropIDMinWidth, krb.PropIDMaxWidth
+	}
+	if limit, ok := e.sizeProperty(child, minID, mainContentSize); ok {
+		item.min = limit
+	}
+	// The width and height properties set the size of children without a
+	// basis; with one they are the largest size the child grows to.
+	if limit, ok := e.sizeProperty(child, maxID, mainContentSize); ok && child.Flex.HasBasis {
+		item.max = maxF(limit, item.min)
+	}
+	item.size = float32(math.Min(math.Max(float64(item.basis), float64(item.min)), float64(item.max)))
+	return item
+}
+
+// sizeProperty returns the size set by el's size property propID, from its
+// direct properties or else its style, in window pixels. Percentages refer to
+// parentSize.
+func (e *Engine) sizeProperty(el *render.RenderElement, propID krb.PropertyID, parentSize float32) (float32, bool) {
+	doc := e.Doc
+	var prop *krb.Property
+	if doc != nil && el.OriginalIndex >= 0 && el.OriginalIndex < len(doc.Properties) {
+		for i := range doc.Properties[el.OriginalIndex] {
+			if doc.Properties[el.OriginalIndex][i].ID == propID {
+				prop = &doc.Properties[el.OriginalIndex][i]
+				break
+			}
+		}
+	}
+	if prop == nil {
+		if style, found := render.FindStyle(doc, el.Header.StyleID); found {
+			prop, _ = render.StyleProperty(style, propID)
+		}
+	}
+	if prop == nil {
+		return 0, false
+	}
+	val, valType, _, err := getNumericValueFromKrbProp(prop, doc)
+	if err != nil || val <= 0 {
+		return 0, false
+	}
+	return muxFloat32(valType == krb.ValTypePercentage, (val/256.0)*parentSize, val*e.ScaleFactor), true
+}
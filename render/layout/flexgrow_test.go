@@ -0,0 +1,47 @@
+package layout
+
+import (
+	"math"
+	"testing"
+
+	"github.com/kryonlabs/kryon-go-runtime/krb"
+	"github.com/kryonlabs/kryon-go-runtime/render"
+)
+
+func TestFlexGrowAndShrink(t *testing.T) {
+	b := krb.NewBuilder()
+	app := window(b, 400, 300).Layout(krb.LayoutDirColumn)
+	grow := app.AddChild(krb.ElemTypeContainer).Size(400, 50).Layout(krb.LayoutDirRow)
+	grow.AddChild(krb.ElemTypeContainer).ID("side").CustomString(render.FlexBasisKey, "100").
+		CustomString(render.FlexGrowKey, "1").Property(krb.ShortProperty(krb.PropIDMaxWidth, 120))
+	grow.AddChild(krb.ElemTypeContainer).ID("main").CustomString(render.FlexGrowKey, "2")
+	grow.AddChild(krb.ElemTypeContainer).ID("bit").Layout(krb.LayoutGrowBit)
+	grow.AddChild(krb.ElemTypeContainer).ID("fixed").Size(60, 0)
+	shrink := app.AddChild(krb.ElemTypeContainer).Size(400, 50).Layout(krb.LayoutDirRow)
+	shrink.AddChild(krb.ElemTypeContainer).ID("a").CustomString(render.FlexBasisKey, "300").
+		CustomString(render.FlexShrinkKey, "1")
+	shrink.AddChild(krb.ElemTypeContainer).ID("b").CustomString(render.FlexBasisKey, "100").
+		CustomString(render.FlexShrinkKey, "1").Property(krb.ShortProperty(krb.PropIDMinWidth, 95))
+	shrink.AddChild(krb.ElemTypeContainer).ID("c").CustomString(render.FlexBasisKey, "50%").
+		CustomString(render.FlexShrinkKey, "2")
+	shrink.AddChild(krb.ElemTypeContainer).ID("rigid").Size(50, 0)
+	tree := layoutTree(t, b)
+
+	// side grows from its basis to its max width; main and the grow bit
+	// split the remaining 220 px 2:1. In the second row the 250 px
+	// overflow is taken in proportion to shrink times basis, with b frozen
+	// at its min width.
+	want := []struct {
+		id   string
+		x, w float32
+	}{
+		{"side", 0, 120}, {"main", 120, 440.0 / 3}, {"bit", 800.0 / 3, 220.0 / 3}, {"fixed", 340, 60},
+		{"a", 0, 195}, {"b", 195, 95}, {"c", 290, 60}, {"rigid", 350, 50},
+	}
+	for _, w := range want {
+		el := tree.ElementByID(w.id)
+		if math.Abs(float64(el.RenderX-w.x)) > 0.01 || math.Abs(float64(el.RenderW-w.w)) > 0.01 {
+			t.Errorf("%s: x %v width %v, want x %v width %v", w.id, el.RenderX, el.RenderW, w.x, w.w)
+		}
+	}
+}
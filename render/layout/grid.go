@@ -0,0 +1,249 @@
+// render/layout/grid.go
+package layout
+
+import (
+	"github.com/kryonlabs/kryon-go-runtime/krb"
+	"github.com/kryonlabs/kryon-go-runtime/render"
+)
+
+// gridItem is a child of a grid and the cells it covers, 0-based.
+type gridItem struct {
+	el                  *render.RenderElement
+	column, row         int
+	columnSpan, rowSpan int
+	width, height       float32 // Measured size, without margins
+}
+
+// measureKey is an element laid out in a content box of w x h.
+type measureKey struct {
+	el   *render.RenderElement
+	w, h float32
+}
+
+// layoutGrid places children, the flow children of the krb.ElemTypeGrid
+// parent, in the cells of its column and row tracks within the content box
+// at (x, y) of size w x h, and lays out their content. Tracks are separated
+// by the parent's gap.
+//
+// Fixed and percentage tracks are sized first, then auto tracks fit the
+// children spanning only them, and fraction tracks share what is left. Rows
+// beyond the parent's GridRows, added to fit the children placed there, are
+// auto tracks.
+//
+// Each child's content is laid out once, in its cell. Its width is measured
+// without its content and its height once per column width, so grids nested
+// in grids stay linear in the size of the tree.
+func (e *Engine) layoutGrid(parent *render.RenderElement, children []*render.RenderElement, x, y, w, h float32) {
+	if e.measured == nil {
+		e.measured = make(map[measureKey][2]float32)
+		defer func() { e.measured = nil }()
+	}
+	gap := e.gap(parent)
+	columns := parent.GridColumns
+	if len(columns) == 0 {
+		columns = []render.GridTrack{{Sizing: render.GridTrackFraction, Value: 1}}
+	}
+	items, columnCount, rowCount := placeGridItems(children, len(columns))
+	columns = withAutoTracks(columns, columnCount)
+	rows := withAutoTracks(parent.GridRows, rowCount)
+
+	// Children are measured in the whole content box for the column widths,
+	// then in their columns, so text wraps as it will in its cell, for the rows.
+	for i := range items {
+		e.performLayout(items[i].el, x, y, w, h, false)
+		items[i].width = items[i].el.RenderW
+	}
+	columnSizes := e.sizeGridTracks(columns, w, gap, items, func(item gridItem) (int, int, float32) {
+		before, after := e.axisMargins(item.el, true)
+		return item.column, item.columnSpan, before + item.width + after
+	})
+	columnStarts := trackStarts(columnSizes, gap)
+	for i := range items {
+		item := &items[i]
+		item.width, item.height = e.measure(item.el, spanSize(columnSizes, item.column, item.columnSpan, gap), h)
+	}
+	rowSizes := e.sizeGridTracks(rows, h, gap, items, func(item gridItem) (int, int, float32) {
+		before, after := e.axisMargins(item.el, false)
+		return item.row, item.rowSpan, before + item.height + after
+	})
+	rowStarts := trackStarts(rowSizes, gap)
+
+	for _, item := range items {
+		el := item.el
+		cellX, cellY := x+columnStarts[item.column], y+rowStarts[item.row]
+		cellW := spanSize(columnSizes, item.column, item.columnSpan, gap)
+		cellH := spanSize(rowSizes, item.row, item.rowSpan, gap)
+		marginTop, marginRight, marginBottom, marginLeft := e.margin(el)
+		el.RenderW, el.RenderH = item.width, item.height
+		if el.GridCell.JustifySelf == krb.LayoutAlignStretch && el.Header.Width == 0 {
+			el.RenderW = maxF(0, cellW-marginLeft-marginRight)
+		}
+		if el.GridCell.AlignSelf == krb.LayoutAlignStretch && el.Header.Height == 0 {
+			el.RenderH = maxF(0, cellH-marginTop-marginBottom)
+		}
+		el.RenderX = cellX + calculateCrossAxisOffsetF(el.GridCell.JustifySelf, cellW, el.RenderW, marginLeft, marginRight)
+		el.RenderY = cellY + calculateCrossAxisOffsetF(el.GridCell.AlignSelf, cellH, el.RenderH, marginTop, marginBottom)
+		if !e.measuring {
+			e.LayoutContent(el)
+		}
+	}
+}
+
+// measure returns the size of el laid out in a content box of w x h. The
+// first time it is asked for during a grid layout it lays el out, without
+// the content of the grid items within, and it remembers the size for the
+// rest of the grid layout.
+func (e *Engine) measure(el *render.RenderElement, w, h float32) (float32, float32) {
+	key := measureKey{el: el, w: w, h: h}
+	if size, ok := e.measured[key]; ok {
+		return size[0], size[1]
+	}
+	measuring := e.measuring
+	e.measuring = true
+	e.PerformLayout(el, 0, 0, w, h)
+	e.measuring = measuring
+	e.measured[key] = [2]float32{el.RenderW, el.RenderH}
+	return el.RenderW, el.RenderH
+}
+
+// placeGridItems assigns cells to children in a grid of columnCount columns.
+// Children with both a column and a row go where they ask first; the others
+// are then placed in document order in the first free cells, scanning row by
+// row from the last auto-placed child, or in their column or row if they set
+// one. It returns the items and the number of columns and rows they cover.
+func placeGridItems(children []*render.RenderElement, columnCount int) ([]gridItem, int, int) {
+	items := make([]gridItem, len(children))
+	occupied := make(map[[2]int]bool)
+	fits := func(item gridItem) bool {
+		for c := item.column; c < item.column+item.columnSpan; c++ {
+			for r := item.row; r < item.row+item.rowSpan; r++ {
+				if occupied[[2]int{c, r}] {
+					return false
+				}
+			}
+		}
+		return true
+	}
+	occupy := func(item gridItem) {
+		for c := item.column; c < item.column+item.columnSpan; c++ {
+			for r := item.row; r < item.row+item.rowSpan; r++ {
+				occupied[[2]int{c, r}] = true
+			}
+		}
+	}
+
+	for i, el := range children {
+		cell := el.GridCell
+		items[i] = gridItem{el: el, column: cell.Column - 1, row: cell.Row - 1, columnSpan: max(cell.ColumnSpan, 1), rowSpan: max(cell.RowSpan, 1)}
+		if cell.Column > 0 && cell.Row > 0 {
+			occupy(items[i])
+		}
+	}
+
+	cursorColumn, cursorRow := 0, 0
+	for i := range items {
+		item := &items[i]
+		switch {
+		case item.column >= 0 && item.row >= 0:
+			continue
+		case item.column >= 0: // Fixed column: the first row it fits in
+			item.row = 0
+			for !fits(*item) {
+				item.row++
+			}
+		case item.row >= 0: // Fixed row: the first columns it fits in, or the first column
+			item.columnSpan = min(item.columnSpan, columnCount)
+			for item.column = 0; item.column+item.columnSpan <= columnCount; item.column++ {
+				if fits(*item) {
+					break
+				}
+			}
+			if item.column+item.columnSpan > columnCount {
+				item.column = 0
+			}
+		default:
+			item.columnSpan = min(item.columnSpan, columnCount)
+			item.column, item.row = cursorColumn, cursorRow
+			for item.column+item.columnSpan > columnCount || !fits(*item) {
+				item.column++
+				if item.column+item.columnSpan > columnCount {
+					item.column = 0
+					item.row++
+				}
+			}
+			cursorColumn, cursorRow = item.column+item.columnSpan, item.row
+		}
+		occupy(*item)
+	}
+
+	rowCount := 0
+	for _, item := range items {
+		columnCount = max(columnCount, item.column+item.columnSpan)
+		rowCount = max(rowCount, item.row+item.rowSpan)
+	}
+	return items, columnCount, rowCount
+}
+
+// withAutoTracks returns tracks extended with auto tracks to count tracks.
+func withAutoTracks(tracks []render.GridTrack, count int) []render.GridTrack {
+	if len(tracks) >= count {
+		return tracks
+	}
+	extended := make([]render.GridTrack, count)
+	copy(extended, tracks)
+	return extended // The zero GridTrack is auto
+}
+
+// sizeGridTracks returns the sizes of tracks sharing available window pixels
+// with gaps between them. span reports the first track, the number of tracks
+// and the size of an item along the tracks' axis.
+func (e *Engine) sizeGridTracks(tracks []render.GridTrack, available, gap float32, items []gridItem, span func(gridItem) (int, int, float32)) []float32 {
+	sizes := make([]float32, len(tracks))
+	used := gap * float32(max(len(tracks)-1, 0))
+	fractions := float32(0)
+	for i, track := range tracks {
+		switch track.Sizing {
+		case render.GridTrackFixed:
+			sizes[i] = track.Value * e.ScaleFactor
+		case render.GridTrackPercent:
+			sizes[i] = track.Value / 100 * available
+		case render.GridTrackAuto:
+			for _, item := range items {
+				if first, count, size := span(item); first == i && count == 1 {
+					sizes[i] = maxF(sizes[i], size)
+				}
+			}
+		case render.GridTrackFraction:
+			fractions += track.Value
+			continue
+		}
+		used += sizes[i]
+	}
+	if fractions > 0 {
+		free := maxF(0, available-used)
+		for i, track := range tracks {
+			if track.Sizing == render.GridTrackFraction {
+				sizes[i] = free * track.Value / fractions
+			}
+		}
+	}
+	return sizes
+}
+
+// trackStarts returns the offset of each track from the first.
+func trackStarts(sizes []float32, gap float32) []float32 {
+	starts := make([]float32, len(sizes))
+	for i := 1; i < len(sizes); i++ {
+		starts[i] = starts[i-1] + sizes[i-1] + gap
+	}
+	return starts
+}
+
+// spanSize returns the size of count tracks from first, with the gaps between them.
+func spanSize(sizes []float32, first, count int, gap float32) float32 {
+	size := gap * float32(count-1)
+	for _, s := range sizes[first : first+count] {
+		size += s
+	}
+	return size
+}
@@ -0,0 +1,129 @@
+package layout
+
+import (
+	"testing"
+
+	"github.com/kryonlabs/kryon-go-runtime/krb"
+	"github.com/kryonlabs/kryon-go-runtime/render"
+)
+
+func TestGridLayout(t *testing.T) {
+	grid := func(b *krb.Builder, width, height, gap uint16, columns string) *krb.ElementBuilder {
+		return window(b, 400, 300).AddChild(krb.ElemTypeGrid).Size(width, height).
+			Property(krb.ShortProperty(krb.PropIDGap, gap)).CustomString(render.GridColumnsKey, columns)
+	}
+	tests := []struct {
+		name  string
+		build func(b *krb.Builder)
+		want  map[string]rect
+	}{
+		{
+			// Columns are 100, the 50 wide auto item, 25% of 380 = 95, and the 1fr
+			// remainder of 105 after three gaps. The 1fr row takes what the fixed
+			// row and the implicit rows of the 10 high items leave of the 200.
+			name: "tracks, spans and alignment",
+			build: func(b *krb.Builder) {
+				g := grid(b, 380, 200, 10, "100 auto 1fr 25%").CustomString(render.GridRowsKey, "40 1fr")
+				g.AddChild(krb.ElemTypeContainer).ID("header").CustomString(render.GridColumnSpanKey, "4")
+				g.AddChild(krb.ElemTypeContainer).ID("sized").Size(60, 20)
+				g.AddChild(krb.ElemTypeContainer).ID("auto").Size(50, 20)
+				g.AddChild(krb.ElemTypeContainer).ID("centered").Size(20, 20).
+					CustomString(render.JustifySelfKey, "center").CustomString(render.AlignSelfKey, "center")
+				g.AddChild(krb.ElemTypeContainer).ID("end").Size(30, 20).CustomString(render.JustifySelfKey, "end")
+				g.AddChild(krb.ElemTypeContainer).ID("placed").Size(10, 10).
+					CustomString(render.GridColumnKey, "2").CustomString(render.GridRowKey, "3").CustomString(render.GridRowSpanKey, "2")
+				g.AddChild(krb.ElemTypeContainer).ID("flowed").Size(10, 10)
+			},
+			want: map[string]rect{
+				"header":   {0, 0, 380, 40},
+				"sized":    {0, 50, 60, 20},
+				"auto":     {110, 50, 50, 20},
+				"centered": {212.5, 100, 20, 20},
+				"end":      {350, 50, 30, 20},
+				"placed":   {110, 180, 10, 10},
+				"flowed":   {0, 180, 10, 10},
+			},
+		},
+		{
+			// fixed takes its cell first; the others flow around it in order,
+			// and wide, too wide for what is left of the first row, starts the
+			// second.
+			name: "auto-placement around placed items",
+			build: func(b *krb.Builder) {
+				g := grid(b, 150, 100, 0, "50 50 50")
+				g.AddChild(krb.ElemTypeContainer).ID("a").Size(10, 10)
+				g.AddChild(krb.ElemTypeContainer).ID("fixed").Size(10, 10).
+					CustomString(render.GridColumnKey, "2").CustomString(render.GridRowKey, "1")
+				g.AddChild(krb.ElemTypeContainer).ID("b").Size(10, 10)
+				g.AddChild(krb.ElemTypeContainer).ID("wide").Size(0, 10).CustomString(render.GridColumnSpanKey, "2")
+				g.AddChild(krb.ElemTypeContainer).ID("c").Size(10, 10)
+			},
+			want: map[string]rect{
+				"a":     {0, 0, 10, 10},
+				"fixed": {50, 0, 10, 10},
+				"b":     {100, 0, 10, 10},
+				"wide":  {0, 10, 100, 10},
+				"c":     {100, 10, 10, 10},
+			},
+		},
+		{
+			// Auto tracks fit only the items within them, so span, which would
+			// fill the whole grid, stretches over the two auto columns and the
+			// gap between them without widening them.
+			name: "item spanning auto tracks",
+			build: func(b *krb.Builder) {
+				g := grid(b, 200, 100, 10, "auto auto 1fr")
+				g.AddChild(krb.ElemTypeContainer).ID("a").Size(40, 10)
+				g.AddChild(krb.ElemTypeContainer).ID("b").Size(30, 10)
+				g.AddChild(krb.ElemTypeContainer).ID("rest").Size(0, 10)
+				g.AddChild(krb.ElemTypeContainer).ID("span").Size(0, 10).CustomString(render.GridColumnSpanKey, "2")
+			},
+			want: map[string]rect{
+				"a":    {0, 0, 40, 10},
+				"b":    {50, 0, 30, 10},
+				"rest": {90, 0, 110, 10},
+				"span": {0, 20, 80, 10},
+			},
+		},
+		{
+			// 150 + 50% of 200 overflows the grid, leaving nothing for 1fr.
+			name: "fraction tracks after overflowing fixed tracks",
+			build: func(b *krb.Builder) {
+				g := grid(b, 200, 100, 0, "150 50% 1fr")
+				g.AddChild(krb.ElemTypeContainer).ID("fixed").Size(0, 10)
+				g.AddChild(krb.ElemTypeContainer).ID("percent").Size(0, 10)
+				g.AddChild(krb.ElemTypeContainer).ID("fraction").Size(0, 10)
+			},
+			want: map[string]rect{
+				"fixed":    {0, 0, 150, 10},
+				"percent":  {150, 0, 100, 10},
+				"fraction": {250, 0, 0, 10},
+			},
+		},
+		{
+			// inner hugs its 8 high row, then stretches to the 10 high outer
+			// row, and its items are laid out in the 160 wide cell.
+			name: "nested grid",
+			build: func(b *krb.Builder) {
+				g := grid(b, 200, 100, 10, "auto 1fr")
+				g.AddChild(krb.ElemTypeContainer).ID("label").Size(30, 10)
+				inner := g.AddChild(krb.ElemTypeGrid).ID("inner").CustomString(render.GridColumnsKey, "20 1fr")
+				inner.AddChild(krb.ElemTypeContainer).ID("x").Size(5, 5)
+				inner.AddChild(krb.ElemTypeContainer).ID("y").Size(0, 8)
+			},
+			want: map[string]rect{
+				"label": {0, 0, 30, 10},
+				"inner": {40, 0, 160, 10},
+				"x":     {40, 0, 5, 5},
+				"y":     {60, 0, 140, 8},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := krb.NewBuilder()
+			tt.build(b)
+			checkRects(t, layoutTree(t, b), tt.want)
+		})
+	}
+}
@@ -0,0 +1,861 @@
+// render/layout/layout.go
+
+// Package layout computes the position and size of every render.RenderElement
+// from its KRB header, properties and style. It draws nothing and depends on no
+// graphics library, so all backends share it and it runs without a window.
+package layout
+
+import (
+	"fmt"
+	"log"
+	"math"
+
+	"github.com/kryonlabs/kryon-go-runtime/krb"
+	"github.com/kryonlabs/kryon-go-runtime/render"
+)
+
+// Engine lays out a render tree built from Doc. It keeps no per-frame state, so
+// one Engine can be reused for every frame of the same document.
+type Engine struct {
+	Doc         *krb.Document
+	ScaleFactor float32
+	Fonts       render.FontSet // May be nil, in which case text has no intrinsic width
+
+	// Only set while a grid lays out its items; see layoutGrid.
+	measured  map[measureKey][2]float32
+	measuring bool
+}
+
+// New returns an Engine for doc at the given UI scale factor.
+// Text is measured with the font fonts picks for each element, which should be
+// the font the backend draws it with.
+func New(doc *krb.Document, scaleFactor float32, fonts render.FontSet) *Engine {
+	return &Engine{Doc: doc, ScaleFactor: scaleFactor, Fonts: fonts}
+}
+
+// LayoutRoots lays out every root in a window of width x height pixels and
+// then moves the content of scrolling elements by their scroll offsets.
+func (e *Engine) LayoutRoots(roots []*render.RenderElement, width, height float32) {
+	for _, root := range roots {
+		if root != nil {
+			e.PerformLayout(root, 0, 0, width, height)
+		}
+	}
+	render.ApplyScrolling(roots, e.ScaleFactor)
+}
+
+// defaultInputWidth is the content width, in unscaled pixels, of an Input
+// without an explicit width.
+const defaultInputWidth = 200
+
+// textFontSize returns el's font size at the whole-pixel size the backends draw at.
+func textFontSize(el *render.RenderElement, scale float32) float32 {
+	fontSize := render.BaseFontSize * scale
+	if el.ResolvedFontSize > 0 {
+		fontSize = el.ResolvedFontSize * scale
+	}
+	return maxF(1.0, float32(int(fontSize)))
+}
+
+func (e *Engine) fontFor(el *render.RenderElement) render.FontProvider {
+	if e.Fonts == nil {
+		return nil
+	}
+	return e.Fonts.FontFor(el)
+}
+
+// PerformLayout sizes and positions el and, recursively, its children within
+// the parent content box given in absolute pixels.
+func (e *Engine) PerformLayout(
+	el *render.RenderElement,
+	parentContentX, parentContentY, parentContentW, parentContentH float32,
+) {
+	e.performLayout(el, parentContentX, parentContentY, parentContentW, parentContentH, true)
+}
+
+// performLayout is PerformLayout, leaving out el's children unless
+// withChildren is set. Children only change an element's height, so its
+// width is final either way.
+func (e *Engine) performLayout(
+	el *render.RenderElement,
+	parentContentX, parentContentY, parentContentW, parentContentH float32,
+	withChildren bool,
+) {
+	if el == nil {
+		return
+	}
+	doc := e.Doc
+	scale := e.ScaleFactor
+
+	elementIdentifier := el.SourceElementName
+	if elementIdentifier == "" && el.Header.ID != 0 && doc != nil {
+		idStr, _ := render.StringAt(doc, el.Header.ID)
+		if idStr != "" {
+			elementIdentifier = idStr
+		}
+	}
+	if elementIdentifier == "" {
+		elementIdentifier = fmt.Sprintf("Type0x%X_Idx%d_NoName", el.Header.Type, el.OriginalIndex)
+	}
+
+	// Example: Enable detailed logging for specific elements if needed for debugging
+	// isSpecificElementToLog := strings.Contains(elementIdentifier, "TabBar") || strings.Contains(elementIdentifier, "main_content_area")
+	isSpecificElementToLog := false // Disable verbose logging by default
+
+	if isSpecificElementToLog {
+		log.Printf(
+			">>>>> PerformLayout for: %s (Type:0x%X, OrigIdx:%d) ParentCTX:%.0f,%.0f,%.0f,%.0f",
+			elementIdentifier, el.Header.Type, el.OriginalIndex, parentContentX, parentContentY, parentContentW, parentContentH,
+		)
+		log.Printf(
+			"      Hdr: W:%d,H:%d,PosX:%d,PosY:%d,Layout:0x%02X(Abs:%t,Grow:%t)",
+			el.Header.Width, el.Header.Height, el.Header.PosX, el.Header.PosY,
+			el.Header.Layout, el.Header.LayoutAbsolute(), el.Header.LayoutGrow(),
+		)
+	}
+
+	isRootElement := (el.Parent == nil)
+	scaledUint16Local := func(v uint16) float32 { return float32(v) * scale }
+
+	// --- Step 1: Determine EXPLICIT Size ---
+	// Priority:
+	// 1. Direct KRB Header Width/Height (from KRY <Element width=X height=Y>)
+	// 2. Direct KRB Property (from KRY width: Z or width: "Z%")
+	// 3. Style Property (from KRY style "s" { width: A or width: "A%" })
+
+	hasExplicitWidth := false
+	desiredWidth := float32(0.0)
+	if el.Header.Width > 0 { // From KRY <Element width=X> (direct KRB header)
+		desiredWidth = scaledUint16Local(el.Header.Width) // KRB Header W/H are direct pixel values
+		hasExplicitWidth = true
+	}
+
+	hasExplicitHeight := false
+	desiredHeight := float32(0.0)
+	if el.Header.Height > 0 { // From KRY <Element height=X> (direct KRB header)
+		desiredHeight = scaledUint16Local(el.Header.Height) // KRB Header W/H are direct pixel values
+		hasExplicitHeight = true
+	}
+
+	// Check direct KRB properties (e.g., from KRY width: "50%" or width: 100)
+	// These override KRB Header Width/Height if both are present (though KRB spec implies header W/H might be max values).
+	// For now, assume direct KRB property takes precedence if it exists and is valid.
+	if doc != nil && el.OriginalIndex >= 0 && el.OriginalIndex < len(doc.Properties) && doc.Properties[el.OriginalIndex] != nil {
+		elementDirectProps := doc.Properties[el.OriginalIndex]
+		// Width from direct KRB property
+		propWVal, propWType, _, propWErr := getNumericValueForSizeProp(elementDirectProps, krb.PropIDMaxWidth, doc)
+		if propWErr == nil {
+			explicitPropWidth := muxFloat32(propWType == krb.ValTypePercentage, (propWVal/256.0)*parentContentW, propWVal*scale)
+			if explicitPropWidth > 0 { // A valid direct prop width was found
+				desiredWidth = explicitPropWidth
+				hasExplicitWidth = true
+			}
+		}
+		// Height from direct KRB property
+		propHVal, propHType, _, propHErr := getNumericValueForSizeProp(elementDirectProps, krb.PropIDMaxHeight, doc)
+		if propHErr == nil {
+			explicitPropHeight := muxFloat32(propHType == krb.ValTypePercentage, (propHVal/256.0)*parentContentH, propHVal*scale)
+			if explicitPropHeight > 0 { // A valid direct prop height was found
+				desiredHeight = explicitPropHeight
+				hasExplicitHeight = true
+			}
+		}
+	}
+
+	// Check element's resolved style for size properties IF NOT ALREADY EXPLICITLY SET by header or direct KRB prop.
+	if !hasExplicitWidth {
+		style, styleFound := render.FindStyle(doc, el.Header.StyleID)
+		if styleFound {
+			prop, propFound := render.StyleProperty(style, krb.PropIDMaxWidth) // KRY 'width' property in style maps to MaxWidth
+			if propFound {
+				val, valType, _, err := getNumericValueFromKrbProp(prop, doc)
+				if err == nil {
+					styledWidth := muxFloat32(valType == krb.ValTypePercentage, (val/256.0)*parentContentW, val*scale)
+					if styledWidth > 0 {
+						desiredWidth = styledWidth
+						hasExplicitWidth = true
+						if isSpecificElementToLog {
+							log.Printf("      S1 - Styled Width for %s: %.1f (from prop value %.1f, type %d, StyleID %d)", elementIdentifier, desiredWidth, val, valType, el.Header.StyleID)
+						}
+					}
+				}
+			}
+		}
+	}
+
+	if !hasExplicitHeight {
+		style, styleFound := render.FindStyle(doc, el.Header.StyleID)
+		if styleFound {
+			prop, propFound := render.StyleProperty(style, krb.PropIDMaxHeight) // KRY 'height' property in style maps to MaxHeight
+			if propFound {
+				val, valType, _, err := getNumericValueFromKrbProp(prop, doc)
+				if err == nil {
+					styledHeight := muxFloat32(valType == krb.ValTypePercentage, (val/256.0)*parentContentH, val*scale)
+					if styledHeight > 0 {
+						desiredHeight = styledHeight
+						hasExplicitHeight = true
+						if isSpecificElementToLog {
+							log.Printf("      S1 - Styled Height for %s: %.1f (from prop value %.1f, type %d, StyleID %d)", elementIdentifier, desiredHeight, val, valType, el.Header.StyleID)
+						}
+					}
+				}
+			}
+		}
+	}
+
+	if isSpecificElementToLog {
+		log.Printf("      S1 - After All Explicit Size Checks for %s: W:%.1f(exp:%t), H:%.1f(exp:%t)", elementIdentifier, desiredWidth, hasExplicitWidth, desiredHeight, hasExplicitHeight)
+	}
+
+	// --- Step 2: Apply INTRINSIC and DEFAULT SIZING (if not explicitly sized) ---
+	hPadding := scaledF32(el.Padding[1], scale) + scaledF32(el.Padding[3], scale)
+	vPadding := scaledF32(el.Padding[0], scale) + scaledF32(el.Padding[2], scale)
+	hBorder := scaledF32(el.BorderWidths[1], scale) + scaledF32(el.BorderWidths[3], scale) // Sum of left and right border
+	vBorder := scaledF32(el.BorderWidths[0], scale) + scaledF32(el.BorderWidths[2], scale) // Sum of top and bottom border
+
+	isGrow := el.Header.LayoutGrow()
+	isAbsolute := el.Header.LayoutAbsolute()
+
+	// Margins keep el clear of the edges of the parent's content box, leaving
+	// this much of it for el itself.
+	marginTop, marginRight, marginBottom, marginLeft := e.margin(el)
+	availableWidth := maxF(0, parentContentW-marginLeft-marginRight)
+	availableHeight := maxF(0, parentContentH-marginTop-marginBottom)
+
+	if (el.Header.Type == krb.ElemTypeText || el.Header.Type == krb.ElemTypeButton) && el.Text != "" {
+		finalFontSizePixels := textFontSize(el, scale)
+		textFont := e.fontFor(el)
+
+		// Text wraps within its explicit width, or within the parent's content box if sized intrinsically.
+		wrapWidth := availableWidth - hPadding - hBorder
+		if hasExplicitWidth {
+			wrapWidth = desiredWidth - hPadding - hBorder
+		}
+		lines := render.WrapText(textFont, el.Text, finalFontSizePixels, wrapWidth, int(el.MaxLines))
+
+		if !hasExplicitWidth {
+			textWidthMeasuredInPixels := float32(0)
+			if textFont != nil {
+				for _, line := range lines {
+					textWidthMeasuredInPixels = maxF(textWidthMeasuredInPixels, textFont.MeasureText(line, finalFontSizePixels))
+				}
+			}
+			// Round up so the content box drawn at whole pixels never re-wraps the widest line.
+			textWidthMeasuredInPixels = float32(math.Ceil(float64(textWidthMeasuredInPixels)))
+			// Intrinsic width includes text + horizontal padding + horizontal border
+			desiredWidth = textWidthMeasuredInPixels + hPadding + hBorder
+			if isSpecificElementToLog {
+				log.Printf("      S2a - Intrinsic W (Text) for %s: %.1f (text:%.1f, hPad:%.1f, hBorder:%.1f)", elementIdentifier, desiredWidth, textWidthMeasuredInPixels, hPadding, hBorder)
+			}
+		}
+		if !hasExplicitHeight {
+			textHeightMeasuredInPixels := float32(len(lines)) * render.TextLineHeight(el, textFont, finalFontSizePixels, scale)
+			// Intrinsic height includes all wrapped lines + vertical padding + vertical border
+			desiredHeight = textHeightMeasuredInPixels + vPadding + vBorder
+			if isSpecificElementToLog {
+				log.Printf("      S2a - Intrinsic H (Text) for %s: %.1f (text:%.1f, lines:%d, vPad:%.1f, vBorder:%.1f)", elementIdentifier, desiredHeight, textHeightMeasuredInPixels, len(lines), vPadding, vBorder)
+			}
+		}
+	} else if el.Header.Type == krb.ElemTypeInput {
+		// Inputs are a single line tall and keep their width while the user types.
+		finalFontSizePixels := textFontSize(el, scale)
+		if !hasExplicitWidth {
+			desiredWidth = defaultInputWidth*scale + hPadding + hBorder
+		}
+		if !hasExplicitHeight {
+			desiredHeight = render.TextLineHeight(el, e.fontFor(el), finalFontSizePixels, scale) + vPadding + vBorder
+		}
+		if isSpecificElementToLog {
+			log.Printf("      S2a - Intrinsic Size (Input) for %s: W:%.1f H:%.1f", elementIdentifier, desiredWidth, desiredHeight)
+		}
+	} else if el.Header.Type == krb.ElemTypeImage && el.ResourceIndex != render.InvalidResourceIndex {
+		texWidthPx := float32(0)
+		texHeightPx := float32(0)
+		if el.TextureLoaded {
+			texWidthPx = float32(el.TextureWidth)
+			texHeightPx = float32(el.TextureHeight)
+		}
+		if !hasExplicitWidth {
+			desiredWidth = texWidthPx*scale + hPadding + hBorder
+			if isSpecificElementToLog {
+				log.Printf("      S2b - Intrinsic W (Image) for %s: %.1f (texW_native:%.1f, scale:%.1f, hPad:%.1f, hBorder:%.1f)", elementIdentifier, desiredWidth, texWidthPx, scale, hPadding, hBorder)
+			}
+		}
+		if !hasExplicitHeight {
+			desiredHeight = texHeightPx*scale + vPadding + vBorder
+			if isSpecificElementToLog {
+				log.Printf("      S2b - Intrinsic H (Image) for %s: %.1f (texH_native:%.1f, scale:%.1f, vPad:%.1f, vBorder:%.1f)", elementIdentifier, desiredHeight, texHeightPx, scale, vPadding, vBorder)
+			}
+		}
+	}
+
+	// Default sizing for containers/app/lists/grids if no explicit/intrinsic size and not growing/absolute
+	if !hasExplicitWidth && !isGrow && !isAbsolute {
+		if desiredWidth == 0 && (el.Header.Type == krb.ElemTypeContainer || el.Header.Type == krb.ElemTypeApp || el.Header.Type == krb.ElemTypeList || el.Header.Type == krb.ElemTypeGrid) {
+			desiredWidth = availableWidth // Default to fill parent's content width
+			if isSpecificElementToLog {
+				log.Printf("      S2c - Default W (Container/App/List/Grid) for %s: %.1f from parent content area", elementIdentifier, desiredWidth)
+			}
+		}
+	}
+	if !hasExplicitHeight && !isGrow && !isAbsolute {
+		if desiredHeight == 0 && (el.Header.Type == krb.ElemTypeContainer || el.Header.Type == krb.ElemTypeApp || el.Header.Type == krb.ElemTypeList || el.Header.Type == krb.ElemTypeGrid) {
+			desiredHeight = availableHeight // Default to fill parent's content height
+			if isSpecificElementToLog {
+				log.Printf("      S2c - Default H (Container/App/List/Grid) for %s: %.1f from parent content area", elementIdentifier, desiredHeight)
+			}
+		}
+	}
+
+	// Assign RenderW/H based on findings
+	if isRootElement {
+		el.RenderW = muxFloat32(hasExplicitWidth, desiredWidth, availableWidth)
+		el.RenderH = muxFloat32(hasExplicitHeight, desiredHeight, availableHeight)
+	} else {
+		el.RenderW = maxF(0, desiredWidth)  // Cannot be negative
+		el.RenderH = maxF(0, desiredHeight) // Cannot be negative
+	}
+
+	if isSpecificElementToLog {
+		log.Printf("      S2 - Assigned RenderW/H for %s: W:%.1f, H:%.1f", elementIdentifier, el.RenderW, el.RenderH)
+	}
+
+	// --- Step 3: Determine Base Render Position ---
+	if el.Header.LayoutAbsolute() {
+		offsetX := scaledUint16Local(el.Header.PosX) + marginLeft
+		offsetY := scaledUint16Local(el.Header.PosY) + marginTop
+		if el.Parent != nil {
+			el.RenderX = el.Parent.RenderX + offsetX // Relative to parent's origin
+			el.RenderY = el.Parent.RenderY + offsetY
+		} else { // Should not happen for absolute if not root, but as fallback
+			el.RenderX = parentContentX + offsetX // Relative to parent's content area origin
+			el.RenderY = parentContentY + offsetY
+		}
+	} else { // Flow layout
+		el.RenderX = parentContentX + marginLeft // Initial position before flow adjustments by PerformLayoutChildren
+		el.RenderY = parentContentY + marginTop
+	}
+
+	if isSpecificElementToLog {
+		log.Printf("      S3 - Initial Position for %s: X:%.1f, Y:%.1f (Abs:%t)", elementIdentifier, el.RenderX, el.RenderY, el.Header.LayoutAbsolute())
+	}
+
+	// --- Step 4: Calculate Content Area for Children ---
+	// This uses the *current* el.RenderW/H which might be adjusted by PerformLayoutChildren if content hugging occurs.
+	// For now, calculate based on current el.RenderW/H.
+	childPaddingTop := scaledF32(el.Padding[0], scale)
+	childPaddingRight := scaledF32(el.Padding[1], scale)
+	childPaddingBottom := scaledF32(el.Padding[2], scale)
+	childPaddingLeft := scaledF32(el.Padding[3], scale)
+	childBorderTop := scaledF32(el.BorderWidths[0], scale)
+	childBorderRight := scaledF32(el.BorderWidths[1], scale)
+	childBorderBottom := scaledF32(el.BorderWidths[2], scale)
+	childBorderLeft := scaledF32(el.BorderWidths[3], scale)
+
+	// childContentAreaX/Y are absolute screen coordinates for where children's layout context begins
+	childContentAreaX := el.RenderX + childBorderLeft + childPaddingLeft
+	childContentAreaY := el.RenderY + childBorderTop + childPaddingTop
+	// childAvailableWidth/Height is the space *within* this element for its children to flow
+	childAvailableWidth := el.RenderW - (childBorderLeft + childBorderRight + childPaddingLeft + childPaddingRight)
+	childAvailableHeight := el.RenderH - (childBorderTop + childBorderBottom + childPaddingTop + childPaddingBottom)
+	childAvailableWidth = maxF(0, childAvailableWidth)   // Ensure non-negative
+	childAvailableHeight = maxF(0, childAvailableHeight) // Ensure non-negative
+
+	if isSpecificElementToLog {
+		log.Printf("      S4 - Child Content Area for %s (abs origin: X:%.1f, Y:%.1f. available W:%.1f, H:%.1f)",
+			elementIdentifier, childContentAreaX, childContentAreaY, childAvailableWidth, childAvailableHeight)
+	}
+
+	// --- Step 5 & 6: Layout Children & Content Hugging ---
+	if withChildren && el.List != nil {
+		// A bound list lays out only the rows in view and scrolls instead of hugging them.
+		e.layoutListRows(el, childContentAreaX, childContentAreaY, childAvailableWidth, childAvailableHeight)
+	} else if withChildren && len(el.Children) > 0 && !el.Header.LayoutAbsolute() { // Absolute positioned elements don't manage flow of their children in this model
+		if isSpecificElementToLog {
+			log.Printf("      S5 - Calling PerformLayoutChildren for %s...", elementIdentifier)
+		}
+		// This call will position children within childContentAreaX/Y using childAvailableWidth/Height
+		e.PerformLayoutChildren(el, childContentAreaX, childContentAreaY, childAvailableWidth, childAvailableHeight)
+
+		// Content Hugging: If element has no explicit height and is not set to grow, adjust its height to fit children.
+		// This is a simplified version. A full implementation would need to consider layout direction more deeply.
+		if !isRootElement && !hasExplicitHeight && !isGrow {
+			actualChildrenMaxY := float32(0)
+			isColumn := el.Header.LayoutDirection() == krb.LayoutDirColumn || el.Header.LayoutDirection() == krb.LayoutDirColumnReverse
+			if isColumn && el.Header.Type != krb.ElemTypeGrid && !el.Header.LayoutWrap() {
+				// For column layout, sum heights of flow children + gaps
+				currentYPos := float32(0)
+				numFlowChildren := 0
+				gapVal := float32(0) // Simplified: get actual gap
+				for _, child := range el.Children {
+					if child != nil && !child.Header.LayoutAbsolute() {
+						if numFlowChildren > 0 {
+							currentYPos += gapVal
+						}
+						childMarginTop, childMarginBottom := e.axisMargins(child, false)
+						currentYPos += childMarginTop + child.RenderH + childMarginBottom
+						numFlowChildren++
+					}
+				}
+				actualChildrenMaxY = currentYPos
+			} else { // For row layout (or grid, or wrapped lines), find max Y extent of children relative to childContentAreaY
+				for _, child := range el.Children {
+					if child != nil && !child.Header.LayoutAbsolute() {
+						_, childMarginBottom := e.axisMargins(child, false)
+						childBottomYRelativeToContentArea := (child.RenderY - childContentAreaY) + child.RenderH + childMarginBottom
+						if childBottomYRelativeToContentArea > actualChildrenMaxY {
+							actualChildrenMaxY = childBottomYRelativeToContentArea
+						}
+					}
+				}
+			}
+
+			// If children dictate a height, and it's different from current desiredHeight (which might be 0 or from intrinsic text/image)
+			if actualChildrenMaxY > 0 {
+				newHeightFromChildren := actualChildrenMaxY + vPadding + vBorder // Add back own padding and border
+				// Only hug if it makes sense (e.g. if children define a larger space than intrinsic, or if intrinsic was 0)
+				// Or if current RenderH is larger than needed (e.g. a container was given parent height but children are smaller)
+				if el.RenderH == 0 || newHeightFromChildren > el.RenderH || (el.RenderH > newHeightFromChildren && (el.Header.Type == krb.ElemTypeContainer || el.Header.Type == krb.ElemTypeApp || el.Header.Type == krb.ElemTypeGrid)) {
+					el.RenderH = newHeightFromChildren
+					if isSpecificElementToLog {
+						log.Printf("      S6 - Content Hug/Shrink H for %s: %.1f", elementIdentifier, el.RenderH)
+					}
+					// Recalculate childAvailableHeight if parent height changed due to hugging
+					childAvailableHeight = el.RenderH - (vBorder + vPadding)
+					childAvailableHeight = maxF(0, childAvailableHeight)
+					// OPTIONAL: Re-run PerformLayoutChildren if parent height changed and children might need to re-flow/re-align in new space
+					// For simplicity, not doing a full re-layout pass here, but a robust engine might.
+				}
+			}
+		}
+	} else if withChildren && len(el.Children) > 0 && el.Header.LayoutAbsolute() {
+		// For absolute positioned parents, their children are also laid out relative to parent's origin,
+		// but within the parent's bounds (passed as parentContentX/Y/W/H to PerformLayout).
+		for _, child := range el.Children {
+			// Each child (absolute or flow) of an absolute parent is laid out starting from the parent's (X,Y)
+			// using parent's (W,H) as the available space.
+			e.PerformLayout(child, el.RenderX, el.RenderY, el.RenderW, el.RenderH)
+		}
+	}
+
+	if isSpecificElementToLog {
+		log.Printf("      S5/6 - After Children/Hugging for %s: W:%.1f, H:%.1f, X:%.1f, Y:%.1f",
+			elementIdentifier, el.RenderW, el.RenderH, el.RenderX, el.RenderY)
+	}
+
+	// --- Step 7: Apply Min/Max-Width/Height Constraints (from direct KRB properties) ---
+	// MaxWidth/MaxHeight were already considered in Step 1 from direct KRB props.
+	// Here, we apply MinWidth/MinHeight.
+	if doc != nil && el.OriginalIndex >= 0 && el.OriginalIndex < len(doc.Properties) && doc.Properties[el.OriginalIndex] != nil {
+		elementDirectProps := doc.Properties[el.OriginalIndex]
+		minWVal, minWType, _, minWErr := getNumericValueForSizeProp(elementDirectProps, krb.PropIDMinWidth, doc)
+		if minWErr == nil {
+			minWidthConstraint := muxFloat32(minWType == krb.ValTypePercentage, (minWVal/256.0)*parentContentW, minWVal*scale)
+			if minWidthConstraint > 0 && el.RenderW < minWidthConstraint {
+				el.RenderW = minWidthConstraint
+			}
+		}
+		minHVal, minHType, _, minHErr := getNumericValueForSizeProp(elementDirectProps, krb.PropIDMinHeight, doc)
+		if minHErr == nil {
+			minHeightConstraint := muxFloat32(minHType == krb.ValTypePercentage, (minHVal/256.0)*parentContentH, minHVal*scale)
+			if minHeightConstraint > 0 && el.RenderH < minHeightConstraint {
+				el.RenderH = minHeightConstraint
+			}
+		}
+	}
+
+	if isSpecificElementToLog {
+		log.Printf("      S7 - Min Constraints Applied for %s: W:%.1f, H:%.1f", elementIdentifier, el.RenderW, el.RenderH)
+	}
+
+	// --- Step 8: Final Fallback for Zero Size (as per spec 3.1) ---
+	el.RenderW = maxF(0, el.RenderW) // Ensure non-negative
+	el.RenderH = maxF(0, el.RenderH) // Ensure non-negative
+
+	// If an element is intended to be visible but ended up with zero height (and has width)
+	if el.RenderW > 0 && el.RenderH == 0 {
+		isConsideredVisibleDueToContentOrStyle := el.Header.Type == krb.ElemTypeContainer ||
+			el.Header.Type == krb.ElemTypeApp ||
+			el.BgColor.A > 0 ||
+			(el.BorderWidths[0]+el.BorderWidths[1]+el.BorderWidths[2]+el.BorderWidths[3] > 0) ||
+			((el.Header.Type == krb.ElemTypeText || el.Header.Type == krb.ElemTypeButton) && el.Text != "") // Text element with text
+
+		if isConsideredVisibleDueToContentOrStyle {
+			// Default to a scaled base font size or 1.0 * scaleFactor if font size is also zero
+			minVisibleDim := maxF(render.BaseFontSize*scale, 1.0*scale)
+			el.RenderH = minVisibleDim
+			if isSpecificElementToLog {
+				log.Printf("      S8 - Fallback Zero H for %s: %.1f applied (min visible dimension)", elementIdentifier, el.RenderH)
+			}
+		}
+	}
+	// Symmetrically for width
+	if el.RenderH > 0 && el.RenderW == 0 {
+		isConsideredVisibleDueToContentOrStyle := el.Header.Type == krb.ElemTypeContainer ||
+			el.Header.Type == krb.ElemTypeApp ||
+			el.BgColor.A > 0 ||
+			(el.BorderWidths[0]+el.BorderWidths[1]+el.BorderWidths[2]+el.BorderWidths[3] > 0) ||
+			((el.Header.Type == krb.ElemTypeText || el.Header.Type == krb.ElemTypeButton) && el.Text != "")
+
+		if isConsideredVisibleDueToContentOrStyle {
+			minVisibleDim := maxF(render.BaseFontSize*scale, 1.0*scale)
+			el.RenderW = minVisibleDim
+			if isSpecificElementToLog {
+				log.Printf("      S8 - Fallback Zero W for %s: %.1f applied (min visible dimension)", elementIdentifier, el.RenderW)
+			}
+		}
+	}
+	el.RenderW = maxF(0, el.RenderW) // Final clamp after potential fallback
+	el.RenderH = maxF(0, el.RenderH) // Final clamp
+
+	if isSpecificElementToLog {
+		log.Printf(
+			"<<<<< PerformLayout END for: %s -- Final Render: X:%.1f,Y:%.1f, W:%.1f,H:%.1f",
+			elementIdentifier, el.RenderX, el.RenderY, el.RenderW, el.RenderH,
+		)
+	}
+}
+
+// layoutListRows lays out the rows of the bound list el in view, stacked from
+// the top of its content box.
+func (e *Engine) layoutListRows(el *render.RenderElement, contentX, contentY, contentW, contentH float32) {
+	el.List.LayoutRows(contentH, e.ScaleFactor, func(row *render.RenderElement, rowY float32) {
+		e.PerformLayout(row, contentX, contentY+rowY, contentW, contentH)
+	})
+}
+
+// gap returns the space between el's children in window pixels, from its
+// PropIDGap direct property or else its style.
+func (e *Engine) gap(el *render.RenderElement) float32 {
+	doc := e.Doc
+	if doc != nil && el.OriginalIndex >= 0 && el.OriginalIndex < len(doc.Properties) {
+		for _, prop := range doc.Properties[el.OriginalIndex] {
+			if prop.ID == krb.PropIDGap {
+				if gVal, valOk := render.ShortValue(&prop); valOk {
+					return float32(gVal) * e.ScaleFactor
+				}
+			}
+		}
+	}
+	if style, found := render.FindStyle(doc, el.Header.StyleID); found {
+		if gapProp, propFound := render.StyleProperty(style, krb.PropIDGap); propFound {
+			if gVal, valOk := render.ShortValue(gapProp); valOk {
+				return float32(gVal) * e.ScaleFactor
+			}
+		}
+	}
+	return 0
+}
+
+// margin returns el's margins in window pixels.
+func (e *Engine) margin(el *render.RenderElement) (top, right, bottom, left float32) {
+	scale := e.ScaleFactor
+	return scaledF32(el.Margin[0], scale), scaledF32(el.Margin[1], scale), scaledF32(el.Margin[2], scale), scaledF32(el.Margin[3], scale)
+}
+
+// axisMargins returns el's margins before and after it along the horizontal
+// axis, left and right, or else the vertical one, top and bottom.
+func (e *Engine) axisMargins(el *render.RenderElement, horizontal bool) (before, after float32) {
+	top, right, bottom, left := e.margin(el)
+	if horizontal {
+		return left, right
+	}
+	return top, bottom
+}
+
+// LayoutContent lays out the children of el, which is already sized and
+// positioned, within its content box.
+func (e *Engine) LayoutContent(el *render.RenderElement) {
+	if len(el.Children) == 0 {
+		return
+	}
+	scale := e.ScaleFactor
+	x := el.RenderX + scaledF32(el.BorderWidths[3], scale) + scaledF32(el.Padding[3], scale)
+	y := el.RenderY + scaledF32(el.BorderWidths[0], scale) + scaledF32(el.Padding[0], scale)
+	w := el.RenderW - scaledF32(el.BorderWidths[1], scale) - scaledF32(el.BorderWidths[3], scale) - scaledF32(el.Padding[1], scale) - scaledF32(el.Padding[3], scale)
+	h := el.RenderH - scaledF32(el.BorderWidths[0], scale) - scaledF32(el.BorderWidths[2], scale) - scaledF32(el.Padding[0], scale) - scaledF32(el.Padding[2], scale)
+	if el.List != nil {
+		e.layoutListRows(el, x, y, maxF(0, w), maxF(0, h))
+	} else {
+		e.PerformLayoutChildren(el, x, y, maxF(0, w), maxF(0, h))
+	}
+}
+
+// wrapLines breaks children, in order, into lines that each fit mainSize
+// along the main axis with gap between children, measured with size. Every
+// line has at least one child, even if it alone is larger than mainSize.
+func wrapLines(children []*render.RenderElement, mainSize, gap float32, size func(*render.RenderElement) float32) [][]*render.RenderElement {
+	var lines [][]*render.RenderElement
+	start, used := 0, float32(0)
+	for i, child := range children {
+		childSize := size(child)
+		if i > start && used+gap+childSize > mainSize {
+			lines = append(lines, children[start:i])
+			start, used = i, 0
+		}
+		if i > start {
+			used += gap
+		}
+		used += childSize
+	}
+	return append(lines, children[start:])
+}
+
+// PerformLayoutChildren flows the children of parent through the parent's
+// client area and lays out its absolutely positioned children.
+func (e *Engine) PerformLayoutChildren(
+	parent *render.RenderElement,
+	parentClientOriginX, parentClientOriginY,
+	availableClientWidth, availableClientHeight float32,
+) {
+
+	if parent == nil || len(parent.Children) == 0 {
+		return
+	}
+	scale := e.ScaleFactor
+
+	parentIdentifier := parent.SourceElementName
+
+	if parentIdentifier == "" {
+		parentIdentifier = fmt.Sprintf("ParentType0x%X_Idx%d", parent.Header.Type, parent.OriginalIndex)
+	}
+
+	//isParentSpecificToLog := strings.Contains(parentIdentifier, "HelloWidget") || parentIdentifier == "Type0x0_Idx0"
+	isParentSpecificToLog := false
+	if isParentSpecificToLog {
+		log.Printf(
+			">>>>> PerformLayoutChildren for PARENT: %s (ContentOrigin: X:%.0f,Y:%.0f, AvailW:%.0f,AvailH:%.0f, LayoutByte:0x%02X)",
+			parentIdentifier, parentClientOriginX, parentClientOriginY, availableClientWidth, availableClientHeight, parent.Header.Layout,
+		)
+	}
+
+	flowChildren := make([]*render.RenderElement, 0, len(parent.Children))
+	absoluteChildren := make([]*render.RenderElement, 0)
+
+	for _, child := range parent.Children {
+
+		if child != nil {
+
+			if child.Header.LayoutAbsolute() {
+				absoluteChildren = append(absoluteChildren, child)
+			} else {
+				flowChildren = append(flowChildren, child)
+			}
+		}
+	}
+
+	scaledUint16Local := func(v uint16) float32 { return float32(v) * scale }
+
+	// --- Layout Flow Children ---
+	if len(flowChildren) > 0 && parent.Header.Type == krb.ElemTypeGrid {
+		e.layoutGrid(parent, flowChildren, parentClientOriginX, parentClientOriginY, availableClientWidth, availableClientHeight)
+	} else if len(flowChildren) > 0 {
+		layoutDirection := parent.Header.LayoutDirection()
+		layoutAlignment := parent.Header.LayoutAlignment()
+		crossAxisAlignment := parent.Header.LayoutCrossAlignment()
+		isLayoutReversed := (layoutDirection == krb.LayoutDirRowReverse || layoutDirection == krb.LayoutDirColumnReverse)
+		isMainAxisHorizontal := (layoutDirection == krb.LayoutDirRow || layoutDirection == krb.LayoutDirRowReverse)
+
+		gapValue := e.gap(parent)
+
+		mainAxisEffectiveSpaceForParentLayout := muxFloat32(isMainAxisHorizontal, availableClientWidth, availableClientHeight)
+		crossAxisEffectiveSizeForParentLayout := muxFloat32(isMainAxisHorizontal, availableClientHeight, availableClientWidth)
+
+		// Pass 1: Sizing
+		for _, child := range flowChildren {
+
+			if isParentSpecificToLog {
+				log.Printf("      PLC Pass 1 (Sizing) - Calling PerformLayout for child: %s", child.SourceElementName)
+			}
+			e.PerformLayout(child, parentClientOriginX, parentClientOriginY, availableClientWidth, availableClientHeight)
+		}
+
+		// Lines: one with all flow children, or with wrapping as many children
+		// as fit the main axis on each, stacked along the cross axis.
+		lines := [][]*render.RenderElement{flowChildren}
+		lineCrossSizes := []float32{crossAxisEffectiveSizeForParentLayout}
+		lineCrossOffsets := []float32{0}
+
+		if parent.Header.LayoutWrap() {
+			lines = wrapLines(flowChildren, mainAxisEffectiveSpaceForParentLayout, gapValue, func(child *render.RenderElement) float32 {
+				before, after := e.axisMargins(child, isMainAxisHorizontal)
+				return before + e.flexItem(child, isMainAxisHorizontal, availableClientWidth, availableClientHeight, true).size + after
+			})
+			lineCrossSizes = make([]float32, len(lines))
+			totalLineCrossSize := gapValue * float32(len(lines)-1)
+			for i, line := range lines {
+				for _, child := range line {
+					before, after := e.axisMargins(child, !isMainAxisHorizontal)
+					lineCrossSizes[i] = maxF(lineCrossSizes[i], before+muxFloat32(isMainAxisHorizontal, child.RenderH, child.RenderW)+after)
+				}
+				totalLineCrossSize += lineCrossSizes[i]
+			}
+			// The lines as a whole are aligned like the children within a line.
+			linesAlignment := crossAxisAlignment
+			if linesAlignment == krb.LayoutAlignStretch {
+				linesAlignment = krb.LayoutAlignStart
+			}
+			firstLineOffset, lineSpacing := calculateAlignmentOffsetsF(
+				linesAlignment, crossAxisEffectiveSizeForParentLayout, totalLineCrossSize, len(lines), false, gapValue,
+			)
+			lineCrossOffsets = make([]float32, len(lines))
+			for i := range lines {
+				if i == 0 {
+					lineCrossOffsets[i] = firstLineOffset
+				} else {
+					lineCrossOffsets[i] = lineCrossOffsets[i-1] + lineCrossSizes[i-1] + lineSpacing
+				}
+			}
+		}
+
+		for lineIndex, line := range lines {
+			lineCrossSize := lineCrossSizes[lineIndex]
+			lineCrossOffset := lineCrossOffsets[lineIndex]
+
+			totalGapSpace := float32(0)
+
+			if len(line) > 1 {
+				totalGapSpace = gapValue * float32(len(line)-1)
+			}
+			// Main-axis margins take their space like gaps do.
+			for _, child := range line {
+				before, after := e.axisMargins(child, isMainAxisHorizontal)
+				totalGapSpace += before + after
+			}
+			mainAxisEffectiveSpaceForElements := maxF(0, mainAxisEffectiveSpaceForParentLayout-totalGapSpace)
+
+			// Pass 2: Resolve the main-axis sizes of growing and shrinking children
+			mainAxisSizes := e.resolveFlexSizes(line, isMainAxisHorizontal, mainAxisEffectiveSpaceForElements, availableClientWidth, availableClientHeight)
+
+			// Pass 3: Apply main-axis sizes and cross-axis stretch
+			totalFinalElementSizeOnMainAxis := float32(0)
+
+			for i, child := range line {
+
+				if isMainAxisHorizontal {
+					child.RenderW = mainAxisSizes[i]
+				} else {
+					child.RenderH = mainAxisSizes[i]
+				}
+
+				if crossAxisAlignment == krb.LayoutAlignStretch {
+					crossMarginBefore, crossMarginAfter := e.axisMargins(child, !isMainAxisHorizontal)
+					stretchedCrossSize := lineCrossSize - crossMarginBefore - crossMarginAfter
+
+					if isMainAxisHorizontal {
+
+						if child.Header.Height == 0 && child.RenderH < stretchedCrossSize {
+							child.RenderH = stretchedCrossSize
+
+							if isParentSpecificToLog {
+								log.Printf("      PLC Pass 3 (Stretch) - Child %s stretched H to %.1f", child.SourceElementName, child.RenderH)
+							}
+						}
+					} else {
+
+						if child.Header.Width == 0 && child.RenderW < stretchedCrossSize {
+							child.RenderW = stretchedCrossSize
+
+							if isParentSpecificToLog {
+								log.Printf("      PLC Pass 3 (Stretch) - Child %s stretched W to %.1f", child.SourceElementName, child.RenderW)
+							}
+						}
+					}
+				}
+				child.RenderW = maxF(0, child.RenderW)
+				child.RenderH = maxF(0, child.RenderH)
+				totalFinalElementSizeOnMainAxis += muxFloat32(isMainAxisHorizontal, child.RenderW, child.RenderH)
+			}
+
+			totalUsedSpaceWithGaps := totalFinalElementSizeOnMainAxis + totalGapSpace
+			startOffsetOnMainAxis, effectiveSpacingBetweenItems := calculateAlignmentOffsetsF(
+				layoutAlignment,
+				mainAxisEffectiveSpaceForParentLayout,
+				totalUsedSpaceWithGaps,
+				len(line), isLayoutReversed, gapValue,
+			)
+
+			if isParentSpecificToLog {
+				log.Printf("      PLC Details: mainEffSpaceForElems:%.0f, crossEffSizeForParent:%.0f", mainAxisEffectiveSpaceForElements, lineCrossSize)
+				log.Printf("      PLC Details: mainAxisSizes:%v", mainAxisSizes)
+				log.Printf("      PLC Details: totalFinalMainAxis:%.0f, totalUsedWithGaps:%.0f", totalFinalElementSizeOnMainAxis, totalUsedSpaceWithGaps)
+				log.Printf("      PLC Details: startOffMain:%.0f, effSpacing:%.0f", startOffsetOnMainAxis, effectiveSpacingBetweenItems)
+			}
+
+			// Pass 4: Position and recurse
+			currentMainAxisPosition := startOffsetOnMainAxis
+			childOrderIndices := make([]int, len(line))
+
+			for i := range childOrderIndices {
+				childOrderIndices[i] = i
+			}
+
+			if isLayoutReversed {
+				reverseSliceInt(childOrderIndices)
+			}
+
+			for i, orderedChildIndex := range childOrderIndices {
+				child := line[orderedChildIndex]
+				childMainAxisSizeValue := muxFloat32(isMainAxisHorizontal, child.RenderW, child.RenderH)
+				childCrossAxisSizeValue := muxFloat32(isMainAxisHorizontal, child.RenderH, child.RenderW)
+				mainMarginBefore, mainMarginAfter := e.axisMargins(child, isMainAxisHorizontal)
+				crossMarginBefore, crossMarginAfter := e.axisMargins(child, !isMainAxisHorizontal)
+				crossAxisOffset := calculateCrossAxisOffsetF(crossAxisAlignment, lineCrossSize, childCrossAxisSizeValue, crossMarginBefore, crossMarginAfter)
+				currentMainAxisPosition += mainMarginBefore
+
+				if isMainAxisHorizontal {
+					child.RenderX = parentClientOriginX + currentMainAxisPosition
+					child.RenderY = parentClientOriginY + lineCrossOffset + crossAxisOffset
+				} else {
+					child.RenderX = parentClientOriginX + lineCrossOffset + crossAxisOffset
+					child.RenderY = parentClientOriginY + currentMainAxisPosition
+				}
+
+				if !child.Header.LayoutAbsolute() && (child.Header.PosX != 0 || child.Header.PosY != 0) {
+					childOwnOffsetX := scaledUint16Local(child.Header.PosX)
+					childOwnOffsetY := scaledUint16Local(child.Header.PosY)
+					child.RenderX += childOwnOffsetX
+					child.RenderY += childOwnOffsetY
+					if isParentSpecificToLog || child.SourceElementName == "Type0x1_Idx1" {
+						log.Printf("      PLC Pass 4 - Child %s applied its own PosX/Y offset: dX:%.1f, dY:%.1f. New pos: X:%.1f,Y:%.1f",
+							child.SourceElementName, childOwnOffsetX, childOwnOffsetY, child.RenderX, child.RenderY)
+					}
+				}
+
+				if isParentSpecificToLog {
+					log.Printf(
+						"      PLC Pass 4 - Positioned Child %s: Final X:%.0f,Y:%.0f (Child W:%.0f,H:%.0f)",
+						child.SourceElementName, child.RenderX, child.RenderY, child.RenderW, child.RenderH,
+					)
+				}
+
+				e.LayoutContent(child)
+
+				currentMainAxisPosition += childMainAxisSizeValue + mainMarginAfter
+
+				if i < len(line)-1 {
+					currentMainAxisPosition += effectiveSpacingBetweenItems
+				}
+			}
+		}
+	}
+
+	// --- Layout Absolute Children ---
+	if len(absoluteChildren) > 0 {
+
+		for _, child := range absoluteChildren {
+
+			if isParentSpecificToLog {
+				log.Printf(
+					"      PLC - Calling PerformLayout for Absolute Child: %s (Parent Frame: X:%.0f,Y:%.0f W:%.0f,H:%.0f)",
+					child.SourceElementName, parent.RenderX, parent.RenderY, parent.RenderW, parent.RenderH,
+				)
+			}
+			e.PerformLayout(child, parent.RenderX, parent.RenderY, parent.RenderW, parent.RenderH)
+		}
+	}
+
+	if isParentSpecificToLog {
+		log.Printf("<<<<< PerformLayoutChildren END for PARENT: %s", parentIdentifier)
+	}
+}
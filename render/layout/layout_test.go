@@ -0,0 +1,165 @@
+package layout
+
+import (
+	"testing"
+
+	"github.com/kryonlabs/kryon-go-runtime/krb"
+	"github.com/kryonlabs/kryon-go-runtime/render"
+)
+
+// layoutTree builds the document of b and lays it out in its window with the
+// bundled font, so text sizes do not depend on the machine.
+func layoutTree(t *testing.T, b *krb.Builder) *render.Tree {
+	t.Helper()
+	doc, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tree, err := render.BuildTree(doc, "test.krb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fonts := render.NewBundledFontProvider()
+	t.Cleanup(fonts.Close)
+	New(doc, tree.ScaleFactor, render.SingleFont{FontProvider: fonts}).
+		LayoutRoots(tree.Roots, float32(tree.Config.Width), float32(tree.Config.Height))
+	return tree
+}
+
+func window(b *krb.Builder, width, height uint16) *krb.ElementBuilder {
+	return b.AddElement(krb.ElemTypeApp).Property(
+		krb.ShortProperty(krb.PropIDWindowWidth, width),
+		krb.ShortProperty(krb.PropIDWindowHeight, height),
+	)
+}
+
+// elementByID returns the element of tree with the given ID, or nil.
+func elementByID(tree *render.Tree, id string) *render.RenderElement {
+	for i := range tree.Elements {
+		el := &tree.Elements[i]
+		if name, ok := render.StringAt(el.DocRef, el.Header.ID); ok && name == id {
+			return el
+		}
+	}
+	return nil
+}
+
+type rect struct{ x, y, w, h float32 }
+
+// checkRects compares the layout of the elements with the given IDs.
+func checkRects(t *testing.T, tree *render.Tree, want map[string]rect) {
+	t.Helper()
+	for id, w := range want {
+		el := elementByID(tree, id)
+		if el == nil {
+			t.Errorf("no element %q", id)
+			continue
+		}
+		if got := (rect{el.RenderX, el.RenderY, el.RenderW, el.RenderH}); got != w {
+			t.Errorf("%s: got %+v, want %+v", id, got, w)
+		}
+	}
+}
+
+func TestPerformLayoutRow(t *testing.T) {
+	b := krb.NewBuilder()
+	app := window(b, 300, 200).Layout(krb.LayoutDirRow)
+	app.AddChild(krb.ElemTypeContainer).ID("a").Size(50, 20)
+	app.AddChild(krb.ElemTypeContainer).ID("b").Size(60, 30)
+	tree := layoutTree(t, b)
+	checkRects(t, tree, map[string]rect{
+		"a": {0, 0, 50, 20},
+		"b": {50, 0, 60, 30},
+	})
+}
+
+func TestPerformLayoutColumnCenteredWithGapAndPadding(t *testing.T) {
+	b := krb.NewBuilder()
+	app := window(b, 300, 200).Layout(krb.LayoutDirColumn|krb.LayoutAlignCenter<<2).
+		Property(krb.ShortProperty(krb.PropIDGap, 10), krb.ByteProperty(krb.PropIDPadding, 5))
+	app.AddChild(krb.ElemTypeContainer).ID("a").Size(50, 20)
+	app.AddChild(krb.ElemTypeContainer).ID("b").Size(60, 30)
+	tree := layoutTree(t, b)
+	// 60 pixels of content centered in the 290x190 content box; center
+	// alignment also centers each child on the cross axis.
+	checkRects(t, tree, map[string]rect{
+		"a": {125, 70, 50, 20},
+		"b": {120, 100, 60, 30},
+	})
+}
+
+func TestPerformLayoutAbsolute(t *testing.T) {
+	b := krb.NewBuilder()
+	app := window(b, 300, 200)
+	app.AddChild(krb.ElemTypeContainer).ID("flow").Size(40, 40)
+	app.AddChild(krb.ElemTypeContainer).ID("abs").Layout(krb.LayoutAbsoluteBit).Pos(100, 50).Size(20, 10)
+	tree := layoutTree(t, b)
+	checkRects(t, tree, map[string]rect{
+		"flow": {0, 0, 40, 40},
+		"abs":  {100, 50, 20, 10},
+	})
+}
+
+func TestPerformLayoutTextUsesFontMetrics(t *testing.T) {
+	b := krb.NewBuilder()
+	window(b, 300, 200).AddChild(krb.ElemTypeText).ID("label").Text("Hello, layout").
+		Property(krb.ShortProperty(krb.PropIDFontSize, 20))
+	tree := layoutTree(t, b)
+
+	fonts := render.NewBundledFontProvider()
+	defer fonts.Close()
+	label := elementByID(tree, "label")
+	if want := fonts.MeasureText("Hello, layout", 20); label.RenderW < want || label.RenderW > want+1 {
+		t.Errorf("text width %v, want the measured %v", label.RenderW, want)
+	}
+	if label.RenderH < 20 {
+		t.Errorf("text height %v is less than the font size", label.RenderH)
+	}
+}
+
+func TestPerformLayoutScaleFactor(t *testing.T) {
+	b := krb.NewBuilder()
+	app := window(b, 300, 200)
+	app.AddChild(krb.ElemTypeContainer).ID("a").Size(50, 20)
+	doc, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tree, err := render.BuildTree(doc, "test.krb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	New(doc, 2, nil).LayoutRoots(tree.Roots, 600, 400)
+	checkRects(t, tree, map[string]rect{"a": {0, 0, 100, 40}})
+}
+
+func TestPerformLayoutWrapsText(t *testing.T) {
+	const text = "The quick brown fox jumps over the lazy dog again and again"
+	b := krb.NewBuilder()
+	box := window(b, 200, 200).AddChild(krb.ElemTypeContainer).Size(120, 0).Layout(krb.LayoutDirColumn)
+	box.AddChild(krb.ElemTypeText).ID("wrapped").Text(text)
+	box.AddChild(krb.ElemTypeText).ID("spaced").Text("one\ntwo").Property(krb.ShortProperty(krb.PropIDLineHeight, 30))
+	box.AddChild(krb.ElemTypeText).ID("clamped").Text(text).Property(krb.ByteProperty(krb.PropIDMaxLines, 1))
+	tree := layoutTree(t, b)
+
+	fonts := render.NewBundledFontProvider()
+	defer fonts.Close()
+	lineHeight := fonts.LineHeight(render.BaseFontSize)
+	lines := len(render.WrapText(fonts, text, render.BaseFontSize, 120, 0))
+	if lines < 2 {
+		t.Fatalf("test text fits on %d line", lines)
+	}
+	for id, want := range map[string]float32{
+		"wrapped": float32(lines) * lineHeight,
+		"spaced":  60,
+		"clamped": lineHeight,
+	} {
+		el := elementByID(tree, id)
+		if el.RenderW > 120 {
+			t.Errorf("%s: %v wide, more than its 120 pixel container", id, el.RenderW)
+		}
+		if el.RenderH != want {
+			t.Errorf("%s: %v tall, want %v", id, el.RenderH, want)
+		}
+	}
+}
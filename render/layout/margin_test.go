@@ -0,0 +1,36 @@
+package layout
+
+import (
+	"testing"
+
+	"github.com/kryonlabs/kryon-go-runtime/krb"
+)
+
+func TestMargins(t *testing.T) {
+	b := krb.NewBuilder()
+	b.AddStyle("spaced", krb.ByteProperty(krb.PropIDMargin, 5))
+	app := window(b, 300, 300).Layout(krb.LayoutDirColumn)
+	row := app.AddChild(krb.ElemTypeContainer).Size(300, 100).Layout(krb.LayoutDirRow | krb.LayoutAlignEnd<<2)
+	row.AddChild(krb.ElemTypeContainer).ID("a").Size(50, 20).
+		Property(krb.EdgeInsetsProperty(krb.PropIDMargin, 1, 10, 3, 4))
+	row.AddChild(krb.ElemTypeContainer).ID("b").Size(50, 20).StyleName("spaced")
+	row.AddChild(krb.ElemTypeContainer).ID("stretched").Size(50, 0).
+		Property(krb.EdgeInsetsProperty(krb.PropIDMargin, 2, 0, 6, 0))
+	app.AddChild(krb.ElemTypeContainer).ID("fill").
+		Property(krb.EdgeInsetsProperty(krb.PropIDMargin, 7, 8, 9, 10), krb.ShortProperty(krb.PropIDMaxHeight, 30))
+	app.AddChild(krb.ElemTypeContainer).ID("abs").Size(10, 10).Pos(100, 100).Layout(krb.LayoutAbsoluteBit).
+		Property(krb.EdgeInsetsProperty(krb.PropIDMargin, 3, 0, 0, 5))
+	tree := layoutTree(t, b)
+	if got, want := tree.ElementByID("b").Margin, [4]uint8{5, 5, 5, 5}; got != want {
+		t.Errorf("margin from style = %v, want %v", got, want)
+	}
+	// The row's 174 px of margin boxes are aligned to the end on both
+	// axes; a child without a height stretches between its margins.
+	checkRects(t, tree, map[string]rect{
+		"a":         {130, 77, 50, 20},
+		"b":         {195, 75, 50, 20},
+		"stretched": {250, 2, 50, 92},
+		"fill":      {10, 107, 282, 30},
+		"abs":       {105, 103, 10, 10},
+	})
+}
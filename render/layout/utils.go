@@ -0,0 +1,150 @@
+// render/layout/utils.go
+package layout
+
+import (
+	"encoding/binary"
+	"fmt"
+	"log"
+
+	"github.com/kryonlabs/kryon-go-runtime/krb"
+)
+
+func getNumericValueForSizeProp(
+	props []krb.Property,
+	propID krb.PropertyID,
+	doc *krb.Document,
+) (value float32, valueType krb.ValueType, rawSizeBytes uint8, err error) {
+
+	for i := range props {
+
+		if props[i].ID == propID {
+			return getNumericValueFromKrbProp(&props[i], doc)
+		}
+	}
+	return 0, krb.ValTypeNone, 0, fmt.Errorf("property ID 0x%X not found in list", propID)
+}
+
+func getNumericValueFromKrbProp(
+	prop *krb.Property,
+	doc *krb.Document,
+) (value float32, valueType krb.ValueType, rawSizeBytes uint8, err error) {
+
+	if prop == nil {
+		return 0, krb.ValTypeNone, 0, fmt.Errorf("getNumericValueFromKrbProp: received nil property")
+	}
+
+	if prop.ValueType == krb.ValTypeShort && len(prop.Value) == 2 {
+		return float32(binary.LittleEndian.Uint16(prop.Value)), krb.ValTypeShort, 2, nil
+	}
+
+	if prop.ValueType == krb.ValTypePercentage && len(prop.Value) == 2 {
+		return float32(binary.LittleEndian.Uint16(prop.Value)), krb.ValTypePercentage, 2, nil
+	}
+	return 0, prop.ValueType, prop.Size, fmt.Errorf(
+		"unsupported KRB ValueType (%d) or Size (%d for PropID %X) for numeric size conversion",
+		prop.ValueType, prop.Size, prop.ID,
+	)
+}
+
+// calculateAlignmentOffsetsF returns where the first child starts along the
+// main axis and the spacing between children. The used space includes the
+// children's margins along the main axis as well as the gaps.
+func calculateAlignmentOffsetsF(
+	alignment uint8,
+	availableSpaceOnMainAxis float32,
+	totalUsedSpaceByChildrenAndGaps float32,
+	numberOfChildren int,
+	isLayoutReversed bool,
+	fixedGapBetweenChildren float32,
+) (startOffset float32, spacingToApplyBetweenChildren float32) {
+	unusedSpace := maxF(0, availableSpaceOnMainAxis-totalUsedSpaceByChildrenAndGaps)
+	startOffset = 0.0
+	spacingToApplyBetweenChildren = fixedGapBetweenChildren
+
+	switch alignment {
+
+	case krb.LayoutAlignStart:
+		startOffset = muxFloat32(isLayoutReversed, unusedSpace, 0)
+
+	case krb.LayoutAlignCenter:
+		startOffset = unusedSpace / 2.0
+
+	case krb.LayoutAlignEnd:
+		startOffset = muxFloat32(isLayoutReversed, 0, unusedSpace)
+
+	case krb.LayoutAlignSpaceBetween:
+		if numberOfChildren > 1 {
+			spacingToApplyBetweenChildren += unusedSpace / float32(numberOfChildren-1)
+		} else { // Center single child
+			startOffset = unusedSpace / 2.0
+		}
+
+	default:
+		log.Printf("Warn calculateAlignmentOffsetsF: Unknown alignment %d. Defaulting to Start.", alignment)
+		startOffset = muxFloat32(isLayoutReversed, unusedSpace, 0)
+	}
+	return startOffset, spacingToApplyBetweenChildren
+}
+
+// calculateCrossAxisOffsetF returns the offset of a child's border box from the
+// start of the parent's cross axis, aligning the child together with its
+// margins before and after it along that axis.
+func calculateCrossAxisOffsetF(
+	alignment uint8,
+	parentCrossAxisSize float32,
+	childCrossAxisSize float32,
+	marginBefore, marginAfter float32,
+) float32 {
+
+	if alignment == krb.LayoutAlignStretch { // Stretch handled by size, not offset
+		return marginBefore
+	}
+	availableSpace := parentCrossAxisSize - childCrossAxisSize - marginBefore - marginAfter
+
+	if availableSpace <= 0 {
+		return marginBefore
+	}
+
+	offset := float32(0.0)
+	switch alignment {
+
+	case krb.LayoutAlignStart:
+		offset = 0.0
+
+	case krb.LayoutAlignCenter:
+		offset = availableSpace / 2.0
+
+	case krb.LayoutAlignEnd:
+		offset = availableSpace
+
+	default: // Fallback for unknown
+		offset = 0.0
+	}
+	return marginBefore + maxF(0, offset)
+}
+
+// --- Math & Slice Utilities ---
+
+func reverseSliceInt(s []int) {
+	for i, j := 0, len(s)-1; i < j; i, j = i+1, j-1 {
+		s[i], s[j] = s[j], s[i]
+	}
+}
+
+func scaledF32(value uint8, scale float32) float32 {
+	return float32(value) * scale
+}
+
+func maxF(a, b float32) float32 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func muxFloat32(cond bool, valTrue, valFalse float32) float32 {
+	if cond {
+		return valTrue
+	}
+	return valFalse
+}
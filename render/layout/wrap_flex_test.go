@@ -0,0 +1,42 @@
+package layout
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/kryonlabs/kryon-go-runtime/krb"
+)
+
+func TestFlexWrap(t *testing.T) {
+	b := krb.NewBuilder()
+	app := window(b, 300, 300).Layout(krb.LayoutDirColumn)
+	row := app.AddChild(krb.ElemTypeContainer).ID("row").Size(300, 120).
+		Layout(krb.LayoutDirRow | krb.LayoutWrapBit | krb.LayoutAlignCenter<<2).
+		Property(krb.ShortProperty(krb.PropIDGap, 6))
+	for i, w := range []uint16{80, 120, 60, 100, 90} {
+		row.AddChild(krb.ElemTypeContainer).ID(fmt.Sprintf("r%d", i)).Size(w, uint16(20+10*(i%3)))
+	}
+	col := app.AddChild(krb.ElemTypeContainer).ID("col").Size(300, 120).
+		Layout(krb.LayoutDirColumn | krb.LayoutWrapBit).Property(krb.ShortProperty(krb.PropIDGap, 4))
+	for i := 0; i < 5; i++ {
+		col.AddChild(krb.ElemTypeContainer).ID(fmt.Sprintf("c%d", i)).Size(uint16(40+10*i), 35)
+	}
+	tree := layoutTree(t, b)
+	// The row breaks after 272 px of the 300; both lines are centered on
+	// the main axis, the 76 px block of lines on the cross axis, and each
+	// child within its line's height.
+	checkRects(t, tree, map[string]rect{
+		"r0": {14, 32, 80, 20},
+		"r1": {100, 27, 120, 30},
+		"r2": {226, 22, 60, 40},
+		"r3": {52, 73, 100, 20},
+		"r4": {158, 68, 90, 30},
+		// Three children fill 113 of the 120 px column; the next line
+		// starts one gap past the widest child of the first.
+		"c0": {0, 120, 40, 35},
+		"c1": {0, 159, 50, 35},
+		"c2": {0, 198, 60, 35},
+		"c3": {64, 120, 70, 35},
+		"c4": {64, 159, 80, 35},
+	})
+}
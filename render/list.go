@@ -0,0 +1,342 @@
+// render/list.go
+package render
+
+import (
+	"fmt"
+	"image/color"
+	"log"
+
+	"github.com/kryonlabs/kryon-go-runtime/krb"
+)
+
+// ListItemComponentKey is the custom property of a krb.ElemTypeList naming the
+// component every row is instantiated from.
+const ListItemComponentKey = "itemComponent"
+
+// ListItem is the content of one list row: texts keyed by the ID of the
+// element in the row component that shows them.
+type ListItem map[string]string
+
+// ListDataSource supplies the rows of a list bound with Tree.BindList.
+type ListDataSource interface {
+	// Count returns the number of rows.
+	Count() int
+	// Item returns the content of row index, 0 <= index < Count().
+	Item(index int) ListItem
+}
+
+// ListRowBinder is implemented by data sources that fill in rows beyond the
+// texts of their ListItem, e.g. colors or visibility. BindRow runs after the
+// item is applied, with the root element of the row instance.
+type ListRowBinder interface {
+	BindRow(index int, row *RenderElement)
+}
+
+// ListView virtualizes a krb.ElemTypeList: only the rows in and just around
+// the visible window exist as elements, instantiated from the list's item
+// component and recycled as the list scrolls. All rows have the height of the
+// first one.
+//
+// The layout engine drives it through LayoutRows. The list scrolls like any
+// element whose overflow is krb.OverflowScroll, binding and laying out the
+// rows that scroll into view as it does.
+type ListView struct {
+	El     *RenderElement
+	Source ListDataSource
+
+	tree          *Tree
+	compDef       *krb.KrbComponentDefinition
+	rowHeader     krb.ElementHeader
+	rowProps      []krb.Property
+	rowSize       int        // Elements in one row instance
+	rowHeight     float32    // Window pixels; 0 until the first row is laid out
+	contentHeight float32    // Height of all rows together
+	bound         []*listRow // Rows showing an item, by ascending index
+	free          []*listRow // Rows ready for reuse
+
+	// From the last LayoutRows, to lay out rows again when the list scrolls.
+	height    float32
+	scale     float32
+	layoutRow func(row *RenderElement, y float32)
+}
+
+// listRow is one instance of the item component. Its elements live in their
+// own slice, so they do not count against the tree's elements.
+type listRow struct {
+	root     *RenderElement
+	elements []RenderElement
+	index    int
+}
+
+// BindList makes the list el show the rows of source, instantiating the
+// component named by el's ListItemComponentKey for each visible row.
+func (t *Tree) BindList(el *RenderElement, source ListDataSource) (*ListView, error) {
+	if el == nil || el.Header.Type != krb.ElemTypeList {
+		return nil, fmt.Errorf("BindList: element is not a list")
+	}
+	if source == nil {
+		return nil, fmt.Errorf("BindList: list '%s' has no data source", el.SourceElementName)
+	}
+	componentName, found := GetCustomPropertyValue(el, ListItemComponentKey, t.Doc)
+	if !found || componentName == "" {
+		return nil, fmt.Errorf("BindList: list '%s' has no %s", el.SourceElementName, ListItemComponentKey)
+	}
+	compDef := t.findComponentDefinition(componentName)
+	if compDef == nil {
+		return nil, fmt.Errorf("BindList: component '%s' for list '%s' not found", componentName, el.SourceElementName)
+	}
+	rowHeader, rowProps, err := templateRoot(compDef.RootElementTemplateData)
+	if err != nil {
+		return nil, fmt.Errorf("BindList: component '%s': %w", componentName, err)
+	}
+
+	lv := &ListView{El: el, Source: source, tree: t, compDef: compDef, rowHeader: rowHeader, rowProps: rowProps, rowSize: 16}
+	// Instantiate one row now, so a template that cannot be expanded is
+	// reported here rather than during a frame. It is reused for the first row.
+	row, err := lv.newRow()
+	if err != nil {
+		return nil, fmt.Errorf("BindList: component '%s': %w", componentName, err)
+	}
+	lv.free = append(lv.free, row)
+	for _, child := range el.Children {
+		child.Parent = nil // Static children are replaced by the rows
+	}
+	el.Children = nil
+	el.List = lv
+	return lv, nil
+}
+
+// Refresh rebinds every row on the next layout, after the items of the data
+// source changed.
+func (lv *ListView) Refresh() {
+	lv.free = append(lv.free, lv.bound...)
+	lv.bound = nil
+}
+
+// RowIndex returns the index of the row el belongs to.
+func (lv *ListView) RowIndex(el *RenderElement) (int, bool) {
+	for ; el != nil; el = el.Parent {
+		if el.Parent == lv.El {
+			for _, row := range lv.bound {
+				if row.root == el {
+					return row.index, true
+				}
+			}
+			return 0, false
+		}
+	}
+	return 0, false
+}
+
+// RowHeight returns the height of every row in window pixels, 0 before the
+// first layout.
+func (lv *ListView) RowHeight() float32 {
+	return lv.rowHeight
+}
+
+// ContentHeight returns the height of all rows together in window pixels.
+func (lv *ListView) ContentHeight() float32 {
+	return lv.contentHeight
+}
+
+// listOverscan is the number of rows bound beyond each end of the visible
+// window, so rows scrolled in by dragging already exist when they appear.
+const listOverscan = 1
+
+// LayoutRows makes the list's children the rows visible at its current scroll
+// offset, in a content box height pixels tall, and lays them out with
+// layoutRow at their y offset from the top of the content box, not moved by
+// the scroll offset. layoutRow is kept to lay out rows again whenever the list
+// scrolls; it is called first to measure the row height.
+func (lv *ListView) LayoutRows(height, scale float32, layoutRow func(row *RenderElement, y float32)) {
+	lv.height, lv.scale, lv.layoutRow = height, scale, layoutRow
+	count := max(lv.Source.Count(), 0)
+	if count > 0 && lv.rowHeight <= 0 {
+		row, err := lv.rowFor(0)
+		if err != nil {
+			log.Printf("Error ListView: list '%s': %v", lv.El.SourceElementName, err)
+			lv.El.Children = nil
+			return
+		}
+		layoutRow(row.root, 0)
+		margins := float32(row.root.Margin[0]) + float32(row.root.Margin[2])
+		lv.rowHeight = max(row.root.RenderH+margins*scale, 1)
+	}
+	lv.contentHeight = float32(count) * lv.rowHeight
+
+	first, last := 0, -1
+	if count > 0 {
+		first = min(max(int(lv.El.Scroll.Y/lv.rowHeight)-listOverscan, 0), count-1)
+		last = min(int((lv.El.Scroll.Y+height)/lv.rowHeight)+listOverscan, count-1)
+	}
+
+	// Rows that scrolled out of the window are recycled for those that scrolled in.
+	kept := lv.bound[:0]
+	for _, row := range lv.bound {
+		if row.index >= first && row.index <= last {
+			kept = append(kept, row)
+		} else {
+			lv.free = append(lv.free, row)
+		}
+	}
+	clear(lv.bound[len(kept):])
+	lv.bound = kept
+
+	children := make([]*RenderElement, 0, last-first+1)
+	for index := first; index <= last; index++ {
+		row, err := lv.rowFor(index)
+		if err != nil {
+			log.Printf("Error ListView: list '%s': %v", lv.El.SourceElementName, err)
+			break
+		}
+		children = append(children, row.root)
+		layoutRow(row.root, float32(index)*lv.rowHeight)
+	}
+	lv.El.Children = children
+}
+
+// scrolled rebinds and lays out the rows after the list scrolled, and moves
+// them by the new scroll offset.
+func (lv *ListView) scrolled() {
+	if lv.layoutRow == nil {
+		return // Not laid out yet
+	}
+	lv.LayoutRows(lv.height, lv.scale, lv.layoutRow)
+	for _, row := range lv.El.Children {
+		applyScrolling(row, lv.scale)
+	}
+	shiftDescendants(lv.El, -lv.El.Scroll.X, -lv.El.Scroll.Y)
+}
+
+// rowFor returns the row bound to index, binding a free or new row if needed.
+func (lv *ListView) rowFor(index int) (*listRow, error) {
+	for _, row := range lv.bound {
+		if row.index == index {
+			return row, nil
+		}
+	}
+	var row *listRow
+	if n := len(lv.free); n > 0 {
+		row, lv.free = lv.free[n-1], lv.free[:n-1]
+	} else {
+		var err error
+		if row, err = lv.newRow(); err != nil {
+			return nil, err
+		}
+	}
+	row.index = index
+	row.root.Parent = lv.El
+	lv.bind(row)
+
+	// Keep bound rows ordered by index.
+	at := len(lv.bound)
+	for at > 0 && lv.bound[at-1].index > index {
+		at--
+	}
+	lv.bound = append(lv.bound, nil)
+	copy(lv.bound[at+1:], lv.bound[at:])
+	lv.bound[at] = row
+	return row, nil
+}
+
+// bind fills row with the item at row.index.
+func (lv *ListView) bind(row *listRow) {
+	item := lv.Source.Item(row.index)
+	for i := range row.elements {
+		el := &row.elements[i]
+		if text, ok := item[ElementID(el)]; ok && el.Header.ID != 0 {
+			el.Text = text
+		}
+	}
+	if binder, ok := lv.Source.(ListRowBinder); ok {
+		binder.BindRow(row.index, row.root)
+	}
+}
+
+// newRow instantiates the item component. The elements slice is allocated
+// large enough up front that expansion never moves it, since the expanded
+// elements point at each other.
+func (lv *ListView) newRow() (*listRow, error) {
+	t, doc := lv.tree, lv.tree.Doc
+	for {
+		placeholder := &RenderElement{
+			OriginalIndex:     -1,
+			Header:            lv.rowHeader,
+			DocRef:            doc,
+			SourceElementName: lv.El.SourceElementName + "_row",
+		}
+		elements := make([]RenderElement, 0, lv.rowSize)
+		next := 0
+		err := t.expandComponent(placeholder, lv.compDef, &elements, &next, nil)
+		if next > lv.rowSize {
+			lv.rowSize = next
+			continue // The slice grew during expansion; retry at the final size
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to instantiate row: %w", err)
+		}
+		if len(placeholder.Children) == 0 {
+			return nil, fmt.Errorf("failed to instantiate row: template has no root element")
+		}
+		for i := range elements {
+			elements[i].OriginalIndex = -1 // Not backed by a document element
+		}
+		root := placeholder.Children[0]
+		root.Parent = lv.El
+		// Expansion gives a component root the instance's properties; a row
+		// has no instance, so it gets the ones of the template root instead.
+		t.applyDirectPropertiesToElement(lv.rowProps, doc, root)
+		t.applyContextualDefaults(root)
+		t.applyInheritanceRecursive(root, inheritedFgColor(lv.El, t.Config.DefaultFgColor), lv.El.ResolvedFontSize, lv.El.TextAlignment, lv.El.FontResourceIndex)
+		return &listRow{root: root, elements: elements}, nil
+	}
+}
+
+// inheritedFgColor returns the foreground color el passes on to its children.
+func inheritedFgColor(el *RenderElement, fallback color.RGBA) color.RGBA {
+	for ; el != nil; el = el.Parent {
+		if el.FgColor.A > 0 {
+			return el.FgColor
+		}
+	}
+	return fallback
+}
+
+// templateRoot decodes the header and direct properties of the first element
+// of a component template.
+func templateRoot(data []byte) (krb.ElementHeader, []krb.Property, error) {
+	if len(data) < krb.ElementHeaderSize {
+		return krb.ElementHeader{}, nil, fmt.Errorf("template has no root element")
+	}
+	header := krb.ElementHeader{
+		Type:            krb.ElementType(data[0]),
+		ID:              data[1],
+		PosX:            krb.ReadU16LE(data[2:4]),
+		PosY:            krb.ReadU16LE(data[4:6]),
+		Width:           krb.ReadU16LE(data[6:8]),
+		Height:          krb.ReadU16LE(data[8:10]),
+		Layout:          data[10],
+		StyleID:         data[11],
+		PropertyCount:   data[12],
+		ChildCount:      data[13],
+		EventCount:      data[14],
+		AnimationCount:  data[15],
+		CustomPropCount: data[16],
+	}
+	props := make([]krb.Property, 0, header.PropertyCount)
+	offset := krb.ElementHeaderSize
+	for i := 0; i < int(header.PropertyCount); i++ {
+		if offset+3 > len(data) || offset+3+int(data[offset+2]) > len(data) {
+			return header, nil, fmt.Errorf("template root property %d is truncated", i)
+		}
+		size := data[offset+2]
+		props = append(props, krb.Property{
+			ID:        krb.PropertyID(data[offset]),
+			ValueType: krb.ValueType(data[offset+1]),
+			Size:      size,
+			Value:     data[offset+3 : offset+3+int(size)],
+		})
+		offset += 3 + int(size)
+	}
+	return header, props, nil
+}
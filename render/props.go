@@ -0,0 +1,305 @@
+// render/props.go
+package render
+
+import (
+	"encoding/binary"
+	"fmt"
+	"image/color"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/kryonlabs/kryon-go-runtime/krb"
+)
+
+// ComponentNameKey is the custom property naming the component an element instantiates.
+const ComponentNameKey = "_componentName"
+
+// ChildrenSlotID is the element ID marking where KRY-usage children are placed
+// inside an expanded component template.
+const ChildrenSlotID = "children_host"
+
+// GetCustomPropertyValue returns the string value of the custom property keyName
+// on el's original KRB element.
+func GetCustomPropertyValue(
+	el *RenderElement,
+	keyName string,
+	doc *krb.Document,
+) (string, bool) {
+
+	if doc == nil || el == nil {
+		return "", false
+	}
+
+	var targetKeyIndex uint8 = 0xFF
+	keyFoundInStrings := false
+
+	for idx, str := range doc.Strings {
+
+		if str == keyName {
+			targetKeyIndex = uint8(idx)
+			keyFoundInStrings = true
+			break
+		}
+	}
+
+	if !keyFoundInStrings {
+		return "", false
+	}
+
+	if el.OriginalIndex < 0 || el.OriginalIndex >= len(doc.CustomProperties) {
+		return "", false
+	}
+	elementCustomProps := doc.CustomProperties[el.OriginalIndex]
+
+	if len(elementCustomProps) == 0 {
+		return "", false
+	}
+
+	for _, prop := range elementCustomProps {
+
+		if prop.KeyIndex == targetKeyIndex {
+
+			if (prop.ValueType == krb.ValTypeString || prop.ValueType == krb.ValTypeResource) && prop.Size == 1 {
+
+				if len(prop.Value) == 1 {
+					valueStringIndex := prop.Value[0]
+
+					if int(valueStringIndex) < len(doc.Strings) {
+						return doc.Strings[valueStringIndex], true
+					}
+					log.Printf(
+						"WARN GetCustomPropertyValue: Custom prop key '%s' for element '%s', value string index %d out of bounds (len %d).",
+						keyName, el.SourceElementName, valueStringIndex, len(doc.Strings),
+					)
+				} else {
+					log.Printf(
+						"WARN GetCustomPropertyValue: Custom prop key '%s' for element '%s', value data empty despite Size=1.",
+						keyName, el.SourceElementName,
+					)
+				}
+			} else {
+				log.Printf(
+					"WARN GetCustomPropertyValue: Custom prop key '%s' for element '%s', unexpected ValueType %X or Size %d.",
+					keyName, el.SourceElementName, prop.ValueType, prop.Size,
+				)
+			}
+			return "", false // Property found but malformed
+		}
+	}
+	return "", false // Key not found
+}
+
+// FindStyleIDByName looks up a style's 1-based ID by its string name.
+// Returns 0 if not found.
+func FindStyleIDByName(doc *krb.Document, name string) uint8 {
+	if doc == nil || name == "" {
+		return 0
+	}
+	for i := range doc.Styles { // Iterate by index to get pointer
+		style := &doc.Styles[i]
+		// KRB Style.NameIndex is 0-based index into doc.Strings
+		if styleName, ok := StringAt(doc, style.NameIndex); ok && styleName == name {
+			return style.ID // KRB Style.ID is 1-based
+		}
+	}
+	return 0 // Not found
+}
+
+// FindStyle returns the style with the given 1-based ID. StyleID 0 means "no style".
+func FindStyle(doc *krb.Document, styleID uint8) (*krb.Style, bool) {
+
+	if doc == nil || styleID == 0 {
+		return nil, false
+	}
+	styleIndex := int(styleID - 1) // StyleID is 1-based
+
+	if styleIndex < 0 || styleIndex >= len(doc.Styles) {
+		return nil, false
+	}
+	return &doc.Styles[styleIndex], true
+}
+
+// StyleProperty returns the first property with the given ID in style.
+func StyleProperty(style *krb.Style, propID krb.PropertyID) (*krb.Property, bool) {
+
+	if style == nil {
+		return nil, false
+	}
+
+	for i := range style.Properties {
+
+		if style.Properties[i].ID == propID {
+			return &style.Properties[i], true
+		}
+	}
+	return nil, false
+}
+
+// StringAt returns the string table entry at stringIndex.
+func StringAt(doc *krb.Document, stringIndex uint8) (string, bool) {
+
+	if doc != nil && int(stringIndex) < len(doc.Strings) {
+		return doc.Strings[stringIndex], true
+	}
+	return "", false
+}
+
+// ElementID returns the KRY id of el, or "" if it has none.
+func ElementID(el *RenderElement) string {
+	if el == nil {
+		return ""
+	}
+	id, _ := StringAt(el.DocRef, el.Header.ID)
+	return id
+}
+
+// ResourceData returns the bytes of resource resIndex: the inline data, or the
+// contents of the external file resolved relative to resourceDir.
+func ResourceData(doc *krb.Document, resourceDir string, resIndex uint8) ([]byte, error) {
+	if doc == nil {
+		return nil, fmt.Errorf("KRB document reference is nil")
+	}
+	if int(resIndex) >= len(doc.Resources) {
+		return nil, fmt.Errorf("resource index %d out of bounds for doc.Resources (len %d)", resIndex, len(doc.Resources))
+	}
+	res := doc.Resources[resIndex]
+
+	switch res.Format {
+	case krb.ResFormatExternal:
+		resourceName, ok := StringAt(doc, res.NameIndex)
+		if !ok {
+			return nil, fmt.Errorf("could not get resource name for external resource (name index %d)", res.NameIndex)
+		}
+		data, err := os.ReadFile(filepath.Join(resourceDir, resourceName))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read external resource: %w", err)
+		}
+		return data, nil
+	case krb.ResFormatInline:
+		if len(res.InlineData) == 0 {
+			return nil, fmt.Errorf("inline resource data is empty (name index %d)", res.NameIndex)
+		}
+		return res.InlineData, nil
+	default:
+		return nil, fmt.Errorf("unknown resource format %d (name index %d)", res.Format, res.NameIndex)
+	}
+}
+
+// ColorValue decodes a color property. flags are the document header flags,
+// which select RGBA or palette encoding.
+func ColorValue(prop *krb.Property, flags uint16) (color.RGBA, bool) {
+
+	if prop == nil || prop.ValueType != krb.ValTypeColor {
+		return color.RGBA{}, false
+	}
+	useExtended := (flags & krb.FlagExtendedColor) != 0
+
+	if useExtended { // RGBA
+
+		if len(prop.Value) == 4 {
+			return color.RGBA{prop.Value[0], prop.Value[1], prop.Value[2], prop.Value[3]}, true
+		}
+	} else { // Palette index
+
+		if len(prop.Value) == 1 {
+			log.Printf(
+				"Warn ColorValue: Palette color (index %d) requested, palette system not implemented. Returning Magenta.",
+				prop.Value[0],
+			)
+			return color.RGBA{255, 0, 255, 255}, true // Placeholder for palette
+		}
+	}
+	log.Printf(
+		"Warn ColorValue: Invalid color data for PropID %X, ValueType %X, Size %d, ExtendedFlag %t",
+		prop.ID, prop.ValueType, prop.Size, useExtended,
+	)
+	return color.RGBA{}, false
+}
+
+// ParseHexColor parses a "#RGB", "#RRGGBB" or "#RRGGBBAA" color. The leading
+// '#' is optional; colors without alpha are opaque.
+func ParseHexColor(s string) (color.RGBA, bool) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "#")
+	if len(s) == 3 {
+		s = string([]byte{s[0], s[0], s[1], s[1], s[2], s[2]})
+	}
+	if len(s) == 6 {
+		s += "ff"
+	}
+	if len(s) != 8 {
+		return color.RGBA{}, false
+	}
+	v, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return color.RGBA{}, false
+	}
+	return color.RGBA{R: uint8(v >> 24), G: uint8(v >> 16), B: uint8(v >> 8), A: uint8(v)}, true
+}
+
+// ByteValue decodes a single-byte property (Byte, String, Resource or Enum).
+func ByteValue(prop *krb.Property) (uint8, bool) {
+
+	if prop != nil &&
+		(prop.ValueType == krb.ValTypeByte ||
+			prop.ValueType == krb.ValTypeString ||
+			prop.ValueType == krb.ValTypeResource ||
+			prop.ValueType == krb.ValTypeEnum) &&
+		len(prop.Value) == 1 {
+		return prop.Value[0], true
+	}
+	return 0, false
+}
+
+// ShortValue decodes a little-endian uint16 Short property.
+func ShortValue(prop *krb.Property) (uint16, bool) {
+
+	if prop != nil && prop.ValueType == krb.ValTypeShort && len(prop.Value) == 2 {
+		return binary.LittleEndian.Uint16(prop.Value), true
+	}
+	return 0, false
+}
+
+// EdgeInsetsValue decodes an EdgeInsets property as Top, Right, Bottom, Left.
+func EdgeInsetsValue(prop *krb.Property) ([4]uint8, bool) {
+
+	if prop != nil && prop.ValueType == krb.ValTypeEdgeInsets && len(prop.Value) == 4 {
+		return [4]uint8{prop.Value[0], prop.Value[1], prop.Value[2], prop.Value[3]}, true
+	}
+	return [4]uint8{}, false
+}
+
+// BorderRadiusValue decodes a BorderRadius property as the Top-left, Top-right,
+// Bottom-right and Bottom-left corner radii: a Byte or Short for all corners,
+// or EdgeInsets for each.
+func BorderRadiusValue(prop *krb.Property) ([4]uint16, bool) {
+	if r, ok := ShortValue(prop); ok {
+		return [4]uint16{r, r, r, r}, true
+	}
+	if r, ok := ByteValue(prop); ok {
+		return [4]uint16{uint16(r), uint16(r), uint16(r), uint16(r)}, true
+	}
+	if corners, ok := EdgeInsetsValue(prop); ok {
+		return [4]uint16{uint16(corners[0]), uint16(corners[1]), uint16(corners[2]), uint16(corners[3])}, true
+	}
+	return [4]uint16{}, false
+}
+
+// NumericValue decodes a Short or Percentage property. Percentages are returned
+// raw in 8.8 fixed point (256 = 100%).
+func NumericValue(prop *krb.Property) (value float32, valueType krb.ValueType, err error) {
+
+	if prop == nil {
+		return 0, krb.ValTypeNone, fmt.Errorf("NumericValue: received nil property")
+	}
+
+	if (prop.ValueType == krb.ValTypeShort || prop.ValueType == krb.ValTypePercentage) && len(prop.Value) == 2 {
+		return float32(binary.LittleEndian.Uint16(prop.Value)), prop.ValueType, nil
+	}
+	return 0, prop.ValueType, fmt.Errorf(
+		"unsupported KRB ValueType (%d) or Size (%d for PropID %X) for numeric conversion",
+		prop.ValueType, prop.Size, prop.ID,
+	)
+}
@@ -0,0 +1,106 @@
+// render/radius.go
+package render
+
+import "math"
+
+// Corner is the horizontal and vertical radius of a rounded corner. A corner
+// whose radii differ is a quarter ellipse; one with either radius 0 is square.
+type Corner struct {
+	X, Y float32
+}
+
+// CornerRadii are the corners of a box in window pixels: Top-left, Top-right,
+// Bottom-right and Bottom-left, the order of RenderElement.BorderRadius.
+type CornerRadii [4]Corner
+
+// IsZero reports whether every corner is square.
+func (c CornerRadii) IsZero() bool {
+	for _, corner := range c {
+		if corner.X > 0 && corner.Y > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Inset returns the corners of the box inset from a box with corners c by the
+// given edge widths: each radius less the width of the edge it meets.
+func (c CornerRadii) Inset(top, right, bottom, left float32) CornerRadii {
+	return CornerRadii{
+		{max(c[0].X-left, 0), max(c[0].Y-top, 0)},
+		{max(c[1].X-right, 0), max(c[1].Y-top, 0)},
+		{max(c[2].X-right, 0), max(c[2].Y-bottom, 0)},
+		{max(c[3].X-left, 0), max(c[3].Y-bottom, 0)},
+	}
+}
+
+// Center returns the center of the ellipse the corner'th corner of box is
+// rounded along.
+func (c CornerRadii) Center(box Rect, corner int) (x, y float32) {
+	x, y = box.X+c[corner].X, box.Y+c[corner].Y
+	if corner == 1 || corner == 2 {
+		x = box.X + box.W - c[corner].X
+	}
+	if corner == 2 || corner == 3 {
+		y = box.Y + box.H - c[corner].Y
+	}
+	return x, y
+}
+
+// BorderRadii returns the corners of el's laid out bounds. Like CSS, all radii
+// are reduced in proportion where adjacent corners would overlap, so corners
+// of half the height or more make a pill.
+func BorderRadii(el *RenderElement, scale float32) CornerRadii {
+	var radii CornerRadii
+	for i, r := range el.BorderRadius {
+		scaled := float32(math.Round(float64(r) * float64(scale)))
+		radii[i] = Corner{scaled, scaled}
+	}
+	fit := float32(1)
+	for _, side := range [][2]float32{
+		{radii[0].X + radii[1].X, el.RenderW},
+		{radii[3].X + radii[2].X, el.RenderW},
+		{radii[0].Y + radii[3].Y, el.RenderH},
+		{radii[1].Y + radii[2].Y, el.RenderH},
+	} {
+		if side[0] > side[1] {
+			fit = min(fit, max(side[1], 0)/side[0])
+		}
+	}
+	if fit < 1 {
+		for i := range radii {
+			radii[i] = Corner{radii[i].X * fit, radii[i].Y * fit}
+		}
+	}
+	return radii
+}
+
+// PaddingBoxRadii returns the corners of el's PaddingBox, the inner edge of its
+// border. Corners between borders of different widths are elliptical, and
+// corners whose radius is within the border are square.
+func PaddingBoxRadii(el *RenderElement, scale float32) CornerRadii {
+	top, right := scaledRound(el.BorderWidths[0], scale), scaledRound(el.BorderWidths[1], scale)
+	bottom, left := scaledRound(el.BorderWidths[2], scale), scaledRound(el.BorderWidths[3], scale)
+	return BorderRadii(el, scale).Inset(top, right, bottom, left)
+}
+
+// RoundedContains reports whether (x, y) lies within box with its corners
+// rounded by radii.
+func RoundedContains(box Rect, radii CornerRadii, x, y float32) bool {
+	if !box.Contains(x, y) {
+		return false
+	}
+	for i, corner := range radii {
+		if corner.X <= 0 || corner.Y <= 0 {
+			continue
+		}
+		cx, cy := radii.Center(box, i)
+		dx, dy := (x-cx)/corner.X, (y-cy)/corner.Y
+		// Only the quarter of the ellipse beyond its center bounds the box.
+		outward := (i == 0 || i == 3) == (dx < 0) && (i == 0 || i == 1) == (dy < 0)
+		if outward && dx*dx+dy*dy > 1 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,61 @@
+package render
+
+import "testing"
+
+func TestBorderRadii(t *testing.T) {
+	el := &RenderElement{RenderW: 200, RenderH: 30, BorderRadius: [4]uint16{999, 999, 999, 999}}
+	want := CornerRadii{{15, 15}, {15, 15}, {15, 15}, {15, 15}}
+	if got := BorderRadii(el, 1); got != want {
+		t.Errorf("pill radii = %v, want %v", got, want)
+	}
+
+	el = &RenderElement{RenderW: 200, RenderH: 120, BorderRadius: [4]uint16{30, 0, 10, 5}}
+	want = CornerRadii{{60, 60}, {0, 0}, {20, 20}, {10, 10}}
+	if got := BorderRadii(el, 2); got != want {
+		t.Errorf("scaled radii = %v, want %v", got, want)
+	}
+}
+
+func TestPaddingBoxRadii(t *testing.T) {
+	el := &RenderElement{
+		RenderW: 120, RenderH: 40,
+		BorderRadius: [4]uint16{12, 12, 12, 12},
+		BorderWidths: [4]uint8{3, 20, 3, 8},
+	}
+	// Each inner radius loses the width of the border it meets; the right
+	// border is wider than the radius, so those corners are square.
+	want := CornerRadii{{4, 9}, {0, 9}, {0, 9}, {4, 9}}
+	if got := PaddingBoxRadii(el, 1); got != want {
+		t.Errorf("PaddingBoxRadii = %v, want %v", got, want)
+	}
+	if PaddingBoxRadii(el, 1).IsZero() {
+		t.Error("IsZero reports rounded left corners as square")
+	}
+	el.BorderWidths[3] = 12
+	if !PaddingBoxRadii(el, 1).IsZero() {
+		t.Error("IsZero reports corners with a zero horizontal radius as rounded")
+	}
+}
+
+func TestRoundedContains(t *testing.T) {
+	box := Rect{X: 10, Y: 10, W: 40, H: 40}
+	radii := CornerRadii{{10, 10}, {0, 0}, {10, 5}, {0, 0}}
+	tests := []struct {
+		x, y float32
+		want bool
+	}{
+		{30, 30, true},
+		{12, 12, false}, // Outside the top-left arc.
+		{20, 11, true},  // On the top edge past the arc.
+		{11, 20, true},  // On the left edge below the arc.
+		{49, 11, true},  // Square top-right corner.
+		{49, 49, false}, // Outside the elliptical bottom-right arc.
+		{42, 48, true},  // Within its wide horizontal radius.
+		{5, 30, false},  // Outside the box.
+	}
+	for _, tt := range tests {
+		if got := RoundedContains(box, radii, tt.x, tt.y); got != tt.want {
+			t.Errorf("RoundedContains(%v, %v) = %v, want %v", tt.x, tt.y, got, tt.want)
+		}
+	}
+}
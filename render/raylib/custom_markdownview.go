@@ -115,6 +115,7 @@ func addMarkdownPlaceholder(parent *render.RenderElement, message string) {
 		Header:            krb.ElementHeader{Type: krb.ElemTypeText, Layout: krb.LayoutGrowBit},
 		Text:              message,
 		IsVisible:         true,
+		Opacity:           1.0,
 		FgColor:           rl.Red,
 		BgColor:           rl.NewColor(50, 0, 0, 100),
 		DocRef:            parent.DocRef,
@@ -0,0 +1,170 @@
+// render/raylib/font_provider.go
+package raylib
+
+import (
+	"bytes"
+	"fmt"
+	"image/color"
+	"log"
+	"path/filepath"
+	"strings"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"github.com/kryonlabs/kryon-go-runtime/krb"
+	"github.com/kryonlabs/kryon-go-runtime/render"
+)
+
+// raylibTextLineSpacing is raylib's default gap between lines of multi-line
+// text, see rl.SetTextLineSpacing.
+const raylibTextLineSpacing = 2
+
+// fontAtlasSize is the pixel size font resources are rasterized at. Larger
+// text is scaled up from the atlas with bilinear filtering.
+const fontAtlasSize = 64
+
+// RaylibFontProvider implements render.FontProvider for a raylib font and draws
+// text with it, so layout measures text exactly like it is drawn.
+type RaylibFontProvider struct {
+	font         rl.Font
+	isDefault    bool
+	spacingRatio float32 // Extra spacing between glyphs, relative to the font size
+}
+
+// NewRaylibFontProvider returns a provider for font, e.g. one loaded with
+// rl.LoadFontEx or rl.LoadFontFromMemory. The caller keeps ownership of font.
+func NewRaylibFontProvider(font rl.Font) *RaylibFontProvider {
+	return &RaylibFontProvider{font: font}
+}
+
+// NewDefaultFontProvider returns a provider for raylib's built-in font. The font
+// is only available once the window is open; until then text measures as zero.
+func NewDefaultFontProvider() *RaylibFontProvider {
+	// rl.DrawText spaces glyphs by fontSize/10 for the 10px default font.
+	return &RaylibFontProvider{isDefault: true, spacingRatio: 0.1}
+}
+
+// Font returns the raylib font text is drawn with.
+func (p *RaylibFontProvider) Font() rl.Font {
+	if p.isDefault {
+		return rl.GetFontDefault()
+	}
+	return p.font
+}
+
+func (p *RaylibFontProvider) MeasureText(text string, fontSize float32) float32 {
+	font := p.Font()
+	if font.BaseSize == 0 || text == "" {
+		return 0
+	}
+	return rl.MeasureTextEx(font, text, fontSize, fontSize*p.spacingRatio).X
+}
+
+func (p *RaylibFontProvider) LineHeight(fontSize float32) float32 {
+	return fontSize + raylibTextLineSpacing
+}
+
+func (p *RaylibFontProvider) Glyph(r rune, fontSize float32) (render.GlyphMetrics, bool) {
+	font := p.Font()
+	if font.BaseSize == 0 {
+		return render.GlyphMetrics{}, false
+	}
+	info := rl.GetGlyphInfo(font, r)
+	if info.Value != int32(r) { // raylib falls back to '?' for missing glyphs
+		return render.GlyphMetrics{}, false
+	}
+	rec := rl.GetGlyphAtlasRec(font, r)
+	scale := fontSize / float32(font.BaseSize)
+
+	advance := float32(info.AdvanceX)
+	if advance == 0 {
+		advance = rec.Width
+	}
+	minX := float32(info.OffsetX) * scale
+	minY := float32(info.OffsetY) * scale
+	return render.GlyphMetrics{
+		Advance: advance*scale + fontSize*p.spacingRatio,
+		MinX:    minX,
+		MinY:    minY,
+		MaxX:    minX + rec.Width*scale,
+		MaxY:    minY + rec.Height*scale,
+	}, true
+}
+
+// DrawText draws a single line of text with its line box's top-left corner at (x, y).
+func (p *RaylibFontProvider) DrawText(text string, x, y, fontSize float32, c color.RGBA) {
+	font := p.Font()
+	if font.BaseSize == 0 || text == "" {
+		return
+	}
+	rl.DrawTextEx(font, text, rl.NewVector2(x, y), fontSize, fontSize*p.spacingRatio, c)
+}
+
+// performFontLoading loads every font resource used by a text-bearing element.
+func (r *RaylibRenderer) performFontLoading(errorCounter *int) {
+	if r.docRef == nil || r.elements == nil {
+		return
+	}
+	for i := range r.elements {
+		el := &r.elements[i]
+		isTextBearing := el.Header.Type == krb.ElemTypeText || el.Header.Type == krb.ElemTypeButton || el.Header.Type == krb.ElemTypeInput
+		if !isTextBearing || el.FontResourceIndex == render.InvalidResourceIndex {
+			continue
+		}
+		if _, exists := r.fontResources[el.FontResourceIndex]; exists {
+			continue
+		}
+		fontProvider, err := r.loadFontResource(el.FontResourceIndex)
+		if err != nil {
+			log.Printf("Error performFontLoading: Font for elem %s (GlobalIdx %d): %v", el.SourceElementName, el.OriginalIndex, err)
+			if errorCounter != nil {
+				*errorCounter++
+			}
+			continue
+		}
+		r.fontResources[el.FontResourceIndex] = fontProvider
+	}
+}
+
+func (r *RaylibRenderer) loadFontResource(resIndex uint8) (*RaylibFontProvider, error) {
+	data, err := render.ResourceData(r.docRef, r.krbFileDir, resIndex)
+	if err != nil {
+		return nil, err
+	}
+	fileType := fontFileType(r.docRef, resIndex, data)
+	font := rl.LoadFontFromMemory(fileType, data, fontAtlasSize, nil)
+	if font.BaseSize == 0 || font.Texture.ID == 0 {
+		return nil, fmt.Errorf("failed to load font resource %d (%s)", resIndex, fileType)
+	}
+	rl.SetTextureFilter(font.Texture, rl.FilterBilinear)
+	return NewRaylibFontProvider(font), nil
+}
+
+// fontFileType returns the file extension raylib needs to decode font data:
+// the resource name's extension, or one sniffed from the data.
+func fontFileType(doc *krb.Document, resIndex uint8, data []byte) string {
+	if name, ok := render.StringAt(doc, doc.Resources[resIndex].NameIndex); ok {
+		if ext := strings.ToLower(filepath.Ext(name)); ext == ".ttf" || ext == ".otf" {
+			return ext
+		}
+	}
+	if bytes.HasPrefix(data, []byte("OTTO")) {
+		return ".otf"
+	}
+	return ".ttf"
+}
+
+// fontFor returns the font el's text is measured and drawn with: its font
+// resource if that loaded, the default font otherwise.
+func (r *RaylibRenderer) fontFor(el *render.RenderElement) *RaylibFontProvider {
+	if el != nil && el.FontResourceIndex != render.InvalidResourceIndex {
+		if fontProvider, ok := r.fontResources[el.FontResourceIndex]; ok {
+			return fontProvider
+		}
+	}
+	return r.fonts
+}
+
+// FontFor implements render.FontSet.
+func (r *RaylibRenderer) FontFor(el *render.RenderElement) render.FontProvider {
+	return r.fontFor(el)
+}
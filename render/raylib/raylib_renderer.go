@@ -7,36 +7,97 @@ import (
 	"math"
 	"os"
 	"path/filepath"
-	"strings" // Keep for GetCustomPropertyValue and logging
 
 	rl "github.com/gen2brain/raylib-go/raylib"
 	"github.com/kryonlabs/kryon-go-runtime/krb"
 	"github.com/kryonlabs/kryon-go-runtime/render"
+	"github.com/kryonlabs/kryon-go-runtime/render/layout"
 )
 
-const baseFontSize = 18.0
-const componentNameConventionKey = "_componentName"
-const childrenSlotIDName = "children_host" // Convention for KRY-usage children slot
-
 type RaylibRenderer struct {
 	config          render.WindowConfig
+	tree            *render.Tree
 	elements        []render.RenderElement // Stores all elements, including expanded ones
 	roots           []*render.RenderElement
 	loadedTextures  map[uint8]rl.Texture2D
 	krbFileDir      string
 	scaleFactor     float32
 	docRef          *krb.Document
-	eventHandlerMap map[string]func()
+	eventHandlerMap map[string]render.EventFunc
 	customHandlers  map[string]render.CustomComponentHandler
+	fonts           *RaylibFontProvider           // Default font for text without a font resource
+	fontResources   map[uint8]*RaylibFontProvider // Fonts loaded from ResTypeFont resources by resource index
+
+	animator              *render.Animator
+	loadAnimationsPending bool // Load-triggered animations start on the first layout pass
+	events                *render.EventDispatcher
+	scissors              []render.Rect // Active clip rectangles, innermost last; raylib scissors do not nest
+	roundedClips          [][]vertex    // Outlines of active rounded clips, innermost last, each within the ones before
+}
+
+// inputKeys maps raylib keys to the editing and navigation keys handled by
+// the event dispatcher.
+var inputKeys = []struct {
+	rlKey int32
+	key   render.Key
+}{
+	{rl.KeyBackspace, render.KeyBackspace},
+	{rl.KeyDelete, render.KeyDelete},
+	{rl.KeyLeft, render.KeyLeft},
+	{rl.KeyRight, render.KeyRight},
+	{rl.KeyHome, render.KeyHome},
+	{rl.KeyEnd, render.KeyEnd},
+	{rl.KeyEnter, render.KeyEnter},
+	{rl.KeyKpEnter, render.KeyEnter},
+	{rl.KeyTab, render.KeyTab},
+	{rl.KeySpace, render.KeySpace},
 }
 
 func NewRaylibRenderer() *RaylibRenderer {
-	return &RaylibRenderer{
+	r := &RaylibRenderer{
 		loadedTextures:  make(map[uint8]rl.Texture2D),
 		scaleFactor:     1.0,
-		eventHandlerMap: make(map[string]func()),
+		eventHandlerMap: make(map[string]render.EventFunc),
 		customHandlers:  make(map[string]render.CustomComponentHandler),
+		fonts:           NewDefaultFontProvider(),
+		fontResources:   make(map[uint8]*RaylibFontProvider),
+
+		animator: render.NewAnimator(render.SystemClock{}),
+	}
+	r.events = render.NewEventDispatcher(r, r.animator, r.eventHandlerMap, r.customHandlers)
+	return r
+}
+
+// SetClock replaces the time source driving KRB animations (see render.ManualClock).
+func (r *RaylibRenderer) SetClock(clock render.Clock) {
+	r.animator.SetClock(clock)
+}
+
+// SetFontProvider replaces the default font, used to measure and draw text of
+// elements without a font resource. The renderer starts with
+// NewDefaultFontProvider.
+func (r *RaylibRenderer) SetFontProvider(fonts *RaylibFontProvider) {
+	if fonts == nil {
+		log.Println("WARN SetFontProvider: Ignoring nil font provider.")
+		return
 	}
+	r.fonts = fonts
+}
+
+// FontProvider returns the default font. See FontFor for the font of an element.
+func (r *RaylibRenderer) FontProvider() render.FontProvider {
+	return r.fonts
+}
+
+// Animator exposes the animation runtime, e.g. to fire triggers from custom handlers.
+func (r *RaylibRenderer) Animator() *render.Animator {
+	return r.animator
+}
+
+// Events exposes the event dispatcher, e.g. to fire EventTypeCustom or to
+// change the long-press threshold.
+func (r *RaylibRenderer) Events() *render.EventDispatcher {
+	return r.events
 }
 
 func (r *RaylibRenderer) Init(config render.WindowConfig) error {
@@ -77,6 +138,11 @@ func (r *RaylibRenderer) Cleanup() {
 	log.Printf("RaylibRenderer Cleanup: Unloaded %d textures from cache.", unloadedCount)
 	r.loadedTextures = make(map[uint8]rl.Texture2D) // Reinitialize map
 
+	for resourceIdx, fontProvider := range r.fontResources {
+		rl.UnloadFont(fontProvider.Font())
+		delete(r.fontResources, resourceIdx)
+	}
+
 	if rl.IsWindowReady() {
 		log.Println("RaylibRenderer Cleanup: Closing Raylib window...")
 		rl.CloseWindow()
@@ -135,13 +201,38 @@ func (r *RaylibRenderer) UpdateLayout(roots []*render.RenderElement) {
 	}
 
 	r.roots = roots // Store/update roots
+	r.events.SetRoots(roots)
 
-	for _, root := range r.roots {
-		if root != nil {
-			r.PerformLayout(root, 0, 0, float32(currentWidth), float32(currentHeight))
+	r.layoutEngine().LayoutRoots(r.roots, float32(currentWidth), float32(currentHeight))
+	r.ApplyCustomComponentLayoutAdjustments()
+	r.updateAnimations()
+}
+
+// updateAnimations starts pending load animations and applies all animation
+// values on top of the freshly computed layout.
+func (r *RaylibRenderer) updateAnimations() {
+	r.animator.SetScale(r.scaleFactor)
+	r.animator.SetRelayout(r.layoutEngine().LayoutContent)
+	if r.loadAnimationsPending {
+		r.loadAnimationsPending = false
+		for i := range r.elements {
+			if render.HasTrigger(&r.elements[i], krb.AnimTriggerLoad) {
+				r.animator.Fire(&r.elements[i], krb.AnimTriggerLoad)
+			}
 		}
 	}
-	r.ApplyCustomComponentLayoutAdjustments()
+	r.animator.Apply()
+}
+
+// FocusedElement returns the element with keyboard focus, or nil.
+func (r *RaylibRenderer) FocusedElement() *render.RenderElement {
+	return r.events.Focused()
+}
+
+// HitTest returns the elements at window coordinates (x, y), topmost first, in
+// the order they were drawn by the last DrawFrame.
+func (r *RaylibRenderer) HitTest(x, y float32) []*render.RenderElement {
+	return render.HitTest(r.roots, x, y, r.scaleFactor)
 }
 
 func (r *RaylibRenderer) PerformLayoutChildrenOfElement(
@@ -149,141 +240,140 @@ func (r *RaylibRenderer) PerformLayoutChildrenOfElement(
 	parentClientOriginX, parentClientOriginY,
 	availableClientWidth, availableClientHeight float32,
 ) {
-	r.PerformLayoutChildren(parent, parentClientOriginX, parentClientOriginY, availableClientWidth, availableClientHeight)
+	r.layoutEngine().PerformLayoutChildren(parent, parentClientOriginX, parentClientOriginY, availableClientWidth, availableClientHeight)
+}
+
+// layoutEngine returns the shared layout engine, measuring text with the same
+// fonts drawContent uses.
+func (r *RaylibRenderer) layoutEngine() *layout.Engine {
+	return layout.New(r.docRef, r.scaleFactor, r)
 }
 
+// PollEventsAndProcessInteractions feeds this frame's mouse and keyboard input
+// to the event dispatcher, which fires hover, press, release, long-press,
+// click, focus, change and submit events, and updates the mouse cursor.
 func (r *RaylibRenderer) PollEventsAndProcessInteractions() {
 	if !rl.IsWindowReady() {
 		return
 	}
 
 	mousePos := rl.GetMousePosition()
-	currentMouseCursor := rl.MouseCursorDefault // Start with default
-
-	isMouseButtonClicked := rl.IsMouseButtonPressed(rl.MouseButtonLeft)
-	clickHandledThisFrame := false              // Ensure only one click is processed per frame globally
-	hoveredInteractiveElementThisFrame := false // Flag to ensure cursor is set by the topmost interactive element
-
-	// Iterate in reverse order through all elements in the flat list.
-	// This means elements added later (like expanded component children) are checked first.
-	// This often (but not perfectly) approximates checking "topmost" elements first.
-	for i := len(r.elements) - 1; i >= 0; i-- {
-		el := &r.elements[i]
-
-		isTabButton := strings.HasPrefix(el.SourceElementName, "tab_") // For specific logging
-
-		if isTabButton {
-			log.Printf("DEBUG PollEvents: Checking Tab Button '%s', Visible: %t, Interactive: %t, Bounds: (X:%.1f,Y:%.1f W:%.1f,H:%.1f), Mouse: (%.1f, %.1f)",
-				el.SourceElementName, el.IsVisible, el.IsInteractive,
-				el.RenderX, el.RenderY, el.RenderW, el.RenderH,
-				mousePos.X, mousePos.Y)
-		}
-
-		if !el.IsVisible || el.RenderW <= 0 || el.RenderH <= 0 {
-			if isTabButton {
-				log.Printf("DEBUG PollEvents: Tab Button '%s' skipped (not visible or zero size).", el.SourceElementName)
-			}
-			continue
+	input := render.PointerInput{
+		X:         mousePos.X,
+		Y:         mousePos.Y,
+		Pressed:   rl.IsMouseButtonPressed(rl.MouseButtonLeft),
+		Released:  rl.IsMouseButtonReleased(rl.MouseButtonLeft),
+		Modifiers: heldModifiers(),
+	}
+	// raylib's wheel is positive when turned away from the user, which scrolls
+	// the content up. Shift turns a vertical wheel into a horizontal one.
+	wheel := rl.GetMouseWheelMoveV()
+	if input.Modifiers&render.ModShift != 0 && wheel.X == 0 {
+		wheel.X, wheel.Y = wheel.Y, 0
+	}
+	input.WheelX = -wheel.X * render.WheelScrollStep * r.scaleFactor
+	input.WheelY = -wheel.Y * render.WheelScrollStep * r.scaleFactor
+	shift := input.Modifiers&render.ModShift != 0
+
+	// Pointer events target the topmost element under the mouse and bubble up from there.
+	hits := r.HitTest(mousePos.X, mousePos.Y)
+	r.events.ProcessPointer(r.elements, hits, input)
+	var target *render.RenderElement
+	if len(hits) > 0 {
+		target = hits[0]
+	}
+	if textInput := r.events.TextInput(); input.Pressed && textInput != nil && textInput.El == target {
+		textInput.SetCaretFromPoint(mousePos.X, r.fontFor(target), r.fontSize(target), r.scaleFactor, shift, r.animator.Now())
+	}
+
+	currentMouseCursor := rl.MouseCursorDefault
+	if pointerTarget := render.PointerTarget(target); pointerTarget != nil {
+		currentMouseCursor = rl.MouseCursorPointingHand
+		if pointerTarget.Header.Type == krb.ElemTypeInput {
+			currentMouseCursor = rl.MouseCursorIBeam
 		}
+	}
+	rl.SetMouseCursor(currentMouseCursor) // Set the cursor once at the end
 
-		elementBounds := rl.NewRectangle(el.RenderX, el.RenderY, el.RenderW, el.RenderH)
-		isMouseHoveringThisElement := rl.CheckCollisionPointRec(mousePos, elementBounds)
+	r.processKeyboardInput(shift)
+}
 
-		if isTabButton {
-			log.Printf("DEBUG PollEvents: Tab Button '%s', Hover Result: %t", el.SourceElementName, isMouseHoveringThisElement)
-		}
+// heldModifiers returns the modifier keys held down this frame.
+func heldModifiers() render.Modifiers {
+	var mods render.Modifiers
+	if rl.IsKeyDown(rl.KeyLeftShift) || rl.IsKeyDown(rl.KeyRightShift) {
+		mods |= render.ModShift
+	}
+	if rl.IsKeyDown(rl.KeyLeftControl) || rl.IsKeyDown(rl.KeyRightControl) {
+		mods |= render.ModCtrl
+	}
+	if rl.IsKeyDown(rl.KeyLeftAlt) || rl.IsKeyDown(rl.KeyRightAlt) {
+		mods |= render.ModAlt
+	}
+	if rl.IsKeyDown(rl.KeyLeftSuper) || rl.IsKeyDown(rl.KeyRightSuper) {
+		mods |= render.ModSuper
+	}
+	return mods
+}
 
-		if isMouseHoveringThisElement {
-			// An element (interactive or not) is under the mouse.
-			// If it's interactive, it's our current best candidate for interaction.
-			if el.IsInteractive {
-				// Set the cursor to pointing hand only if we haven't already set it
-				// for another interactive element "on top" of this one (which wouldn't
-				// happen with this loop structure, but good for clarity).
-				if !hoveredInteractiveElementThisFrame {
-					currentMouseCursor = rl.MouseCursorPointingHand
-					hoveredInteractiveElementThisFrame = true // Mark that an interactive element is handling hover
-					if isTabButton {
-						log.Printf("DEBUG PollEvents: Tab Button '%s' set cursor to PointingHand.", el.SourceElementName)
-					}
-				}
+// processKeyboardInput passes this frame's typed characters and keys to the
+// event dispatcher, which moves focus on Tab, edits the focused Input and
+// activates other focused elements on Enter and Space.
+func (r *RaylibRenderer) processKeyboardInput(shift bool) {
+	var typed []rune
+	for char := rl.GetCharPressed(); char > 0; char = rl.GetCharPressed() {
+		typed = append(typed, rune(char))
+	}
+	r.events.ProcessText(string(typed))
 
-				// Process click ONLY for this topmost interactive element found so far
-				if isMouseButtonClicked && !clickHandledThisFrame {
-					if isTabButton {
-						log.Printf("DEBUG PollEvents: Tab Button '%s' CLICK DETECTED.", el.SourceElementName)
-					}
-
-					eventWasProcessedByCustomHandler := false
-					// Check for custom component event handling first
-					componentID, isCustomInstance := GetCustomPropertyValue(el, componentNameConventionKey, r.docRef)
-					if isCustomInstance && componentID != "" {
-						if customHandler, handlerExists := r.customHandlers[componentID]; handlerExists {
-							if eventInterface, implementsEvent := customHandler.(render.CustomEventHandler); implementsEvent {
-								handled, err := eventInterface.HandleEvent(el, krb.EventTypeClick, r) // Pass renderer instance
-								if err != nil {
-									log.Printf("ERROR PollEvents: Custom click handler for '%s' [%s] returned error: %v",
-										componentID, el.SourceElementName, err)
-								}
-								if handled {
-									eventWasProcessedByCustomHandler = true
-									clickHandledThisFrame = true
-								}
-							}
-						}
-					}
-
-					// If not handled by custom, try standard KRB event handlers
-					if !eventWasProcessedByCustomHandler && len(el.EventHandlers) > 0 {
-						for _, eventInfo := range el.EventHandlers {
-							if eventInfo.EventType == krb.EventTypeClick {
-								goHandlerFunc, found := r.eventHandlerMap[eventInfo.HandlerName]
-								if found {
-									log.Printf("INFO: Click on '%s', executing handler '%s'", el.SourceElementName, eventInfo.HandlerName)
-									goHandlerFunc()
-									clickHandledThisFrame = true // Mark click as handled
-								} else {
-									log.Printf("Warn PollEvents: Standard KRB click handler named '%s' (for %s) is not registered.",
-										eventInfo.HandlerName, el.SourceElementName)
-								}
-								break // Assuming one click action per element for this event type
-							}
-						}
-					}
-				}
-				// Since we found an interactive element under the mouse, and we're iterating
-				// from "latest added / potentially topmost child" to "earliest added / root",
-				// this is the one that should get the interaction.
-				// We can break the loop.
-				break
-			}
-			// If the element under the mouse is NOT interactive, we do nothing with it
-			// regarding cursor or clicks. We continue the loop, because there might be
-			// an interactive element "behind" this non-interactive one (in terms of r.elements order)
-			// that is also under the mouse pointer (e.g. a small button on a large non-interactive panel).
-			// The `hoveredInteractiveElementThisFrame` flag will ensure that if a *later* (in reverse iteration,
-			// so visually "behind") interactive element is found, the cursor remains `PointingHand`.
+	for _, mapping := range inputKeys {
+		if rl.IsKeyPressed(mapping.rlKey) || rl.IsKeyPressedRepeat(mapping.rlKey) {
+			r.events.ProcessKey(mapping.key, shift)
 		}
 	}
-	rl.SetMouseCursor(currentMouseCursor) // Set the cursor once at the end
 }
 
+// RegisterEventHandler registers a handler that takes no event details. See
+// RegisterEventFunc for handlers that need the target element or input state.
 func (r *RaylibRenderer) RegisterEventHandler(name string, handler func()) {
+	if handler == nil {
+		log.Printf("WARN RegisterEventHandler: Attempted to register nil handler for name '%s'.", name)
+		return
+	}
+	r.RegisterEventFunc(name, func(*render.Event) { handler() })
+}
+
+// RegisterEventFunc registers handler under name for every KRB event whose
+// callback is name. The handler gets the event's target, type and input state.
+func (r *RaylibRenderer) RegisterEventFunc(name string, handler render.EventFunc) {
 	if name == "" {
-		log.Println("WARN RegisterEventHandler: Attempted to register handler with empty name.")
+		log.Println("WARN RegisterEventFunc: Attempted to register handler with empty name.")
 		return
 	}
 	if handler == nil {
-		log.Printf("WARN RegisterEventHandler: Attempted to register nil handler for name '%s'.", name)
+		log.Printf("WARN RegisterEventFunc: Attempted to register nil handler for name '%s'.", name)
 		return
 	}
 	if _, exists := r.eventHandlerMap[name]; exists {
-		log.Printf("INFO RegisterEventHandler: Overwriting existing handler for event name '%s'", name)
+		log.Printf("INFO RegisterEventFunc: Overwriting existing handler for event name '%s'", name)
 	}
 	r.eventHandlerMap[name] = handler
 	log.Printf("Registered event handler for '%s'", name)
 }
 
+// BindList shows the rows of source in the List element with KRY id
+// elementID, see render.Tree.BindList.
+func (r *RaylibRenderer) BindList(elementID string, source render.ListDataSource) (*render.ListView, error) {
+	if r.tree == nil {
+		return nil, fmt.Errorf("BindList: no tree has been prepared")
+	}
+	el := r.tree.ElementByID(elementID)
+	if el == nil {
+		return nil, fmt.Errorf("BindList: no element with id '%s'", elementID)
+	}
+	return r.tree.BindList(el, source)
+}
+
 func (r *RaylibRenderer) RegisterCustomComponent(identifier string, handler render.CustomComponentHandler) error {
 	if identifier == "" {
 		return fmt.Errorf("RegisterCustomComponent: identifier cannot be empty")
@@ -310,9 +400,10 @@ func (r *RaylibRenderer) LoadAllTextures() error {
 	log.Println("LoadAllTextures: Starting...")
 	errCount := 0
 	r.performTextureLoading(&errCount)
+	r.performFontLoading(&errCount)
 	log.Printf("LoadAllTextures: Complete. Encountered %d errors.", errCount)
 	if errCount > 0 {
-		return fmt.Errorf("encountered %d errors during texture loading", errCount)
+		return fmt.Errorf("encountered %d errors during texture and font loading", errCount)
 	}
 	return nil
 }
@@ -348,6 +439,7 @@ func (r *RaylibRenderer) performTextureLoading(errorCounter *int) {
 
 		if loadedTex, exists := r.loadedTextures[resIndex]; exists {
 			el.Texture = loadedTex
+			el.TextureWidth, el.TextureHeight = loadedTex.Width, loadedTex.Height
 			el.TextureLoaded = (loadedTex.ID > 0)
 			if !el.TextureLoaded {
 				log.Printf("Warn performTextureLoading: Cached texture for resource index %d was invalid. Re-attempting load.", resIndex)
@@ -361,7 +453,7 @@ func (r *RaylibRenderer) performTextureLoading(errorCounter *int) {
 		loadedOk := false
 
 		if res.Format == krb.ResFormatExternal {
-			resourceName, nameOk := getStringValueByIdx(r.docRef, res.NameIndex)
+			resourceName, nameOk := render.StringAt(r.docRef, res.NameIndex)
 			if !nameOk {
 				log.Printf("Error performTextureLoading: Could not get resource name for external resource index: %d", res.NameIndex)
 				if errorCounter != nil {
@@ -438,6 +530,7 @@ func (r *RaylibRenderer) performTextureLoading(errorCounter *int) {
 
 		if loadedOk {
 			el.Texture = texture
+			el.TextureWidth, el.TextureHeight = texture.Width, texture.Height
 			el.TextureLoaded = true
 			r.loadedTextures[resIndex] = texture
 		} else {
@@ -455,6 +548,26 @@ func (r *RaylibRenderer) DrawFrame(roots []*render.RenderElement) {
 			r.renderElementRecursiveWithCustomDraw(root, r.scaleFactor)
 		}
 	}
+	if el := r.events.FocusRingTarget(); el != nil {
+		r.drawFocusRing(el, r.scaleFactor)
+	}
+}
+
+// drawFocusRing outlines el with render.FocusRingColor, on top of everything
+// drawn before.
+func (r *RaylibRenderer) drawFocusRing(el *render.RenderElement, scale float32) {
+	w := int(scaledI32(render.FocusRingWidth, scale))
+	x, y := int(el.RenderX), int(el.RenderY)
+	width, height := int(el.RenderW), int(el.RenderH)
+	top, bottom := clampOpposingBorders(w, w, height)
+	left, right := clampOpposingBorders(w, w, width)
+	if radii := render.BorderRadii(el, scale); !radii.IsZero() {
+		box := render.Rect{X: float32(x), Y: float32(y), W: float32(width), H: float32(height)}
+		inner := render.Rect{X: box.X + float32(left), Y: box.Y + float32(top), W: box.W - float32(left+right), H: box.H - float32(top+bottom)}
+		r.drawRoundedBorders(box, inner, radii, radii.Inset(float32(top), float32(right), float32(bottom), float32(left)), render.FocusRingColor)
+		return
+	}
+	r.drawBorders(x, y, width, height, top, right, bottom, left, render.FocusRingColor)
 }
 
 func (r *RaylibRenderer) ApplyCustomComponentLayoutAdjustments() {
@@ -466,7 +579,7 @@ func (r *RaylibRenderer) ApplyCustomComponentLayoutAdjustments() {
 		if el == nil {
 			continue
 		}
-		componentIdentifier, found := GetCustomPropertyValue(el, componentNameConventionKey, r.docRef)
+		componentIdentifier, found := GetCustomPropertyValue(el, render.ComponentNameKey, r.docRef)
 		if found && componentIdentifier != "" {
 			handler, handlerFound := r.customHandlers[componentIdentifier]
 			if handlerFound {
@@ -491,7 +604,7 @@ func (r *RaylibRenderer) renderElementRecursiveWithCustomDraw(el *render.RenderE
 	foundName := false
 
 	if r.docRef != nil {
-		componentIdentifier, foundName = GetCustomPropertyValue(el, componentNameConventionKey, r.docRef)
+		componentIdentifier, foundName = GetCustomPropertyValue(el, render.ComponentNameKey, r.docRef)
 	}
 
 	if foundName && componentIdentifier != "" {
@@ -510,9 +623,7 @@ func (r *RaylibRenderer) renderElementRecursiveWithCustomDraw(el *render.RenderE
 		r.renderStandardElement(el, scale) // Changed name to avoid confusion
 	} else {
 		// If custom draw handles its own children, this loop might be skipped based on CustomDrawer's contract.
-		for _, child := range el.Children {
-			r.renderElementRecursiveWithCustomDraw(child, scale)
-		}
+		r.drawChildren(el, scale)
 	}
 }
 
@@ -525,18 +636,17 @@ func (r *RaylibRenderer) renderStandardElement(el *render.RenderElement, scale f
 	renderXf, renderYf, renderWf, renderHf := el.RenderX, el.RenderY, el.RenderW, el.RenderH
 
 	if renderWf <= 0 || renderHf <= 0 {
-		for _, child := range el.Children {
-			r.renderElementRecursiveWithCustomDraw(child, scale)
-		}
+		r.drawChildren(el, scale)
 		return
 	}
 
 	renderX, renderY := int32(renderXf), int32(renderYf)
 	renderW, renderH := int32(renderWf), int32(renderHf)
 
-	effectiveBgColor := el.BgColor
-	effectiveFgColor := el.FgColor
-	borderColor := el.BorderColor
+	opacity := render.EffectiveOpacity(el)
+	effectiveBgColor := render.ApplyOpacity(el.BgColor, opacity)
+	effectiveFgColor := render.ApplyOpacity(el.FgColor, opacity)
+	borderColor := render.ApplyOpacity(el.BorderColor, opacity)
 
 	// Simplified active/inactive style handling (assumes color changes mainly)
 	if (el.Header.Type == krb.ElemTypeButton) && (el.ActiveStyleNameIndex != 0 || el.InactiveStyleNameIndex != 0) {
@@ -549,8 +659,10 @@ func (r *RaylibRenderer) renderStandardElement(el *render.RenderElement, scale f
 		// Let's assume ReResolveElementVisuals handles this correctly through style application.
 	}
 
+	box := render.Rect{X: float32(renderX), Y: float32(renderY), W: float32(renderW), H: float32(renderH)}
+	radii := render.BorderRadii(el, scale)
 	if effectiveBgColor.A > 0 {
-		rl.DrawRectangle(renderX, renderY, renderW, renderH, effectiveBgColor)
+		r.fillRoundedRect(box, radii, effectiveBgColor)
 	}
 
 	topBorder := scaledI32(el.BorderWidths[0], scale)
@@ -559,8 +671,19 @@ func (r *RaylibRenderer) renderStandardElement(el *render.RenderElement, scale f
 	leftBorder := scaledI32(el.BorderWidths[3], scale)
 	clampedTop, clampedBottom := clampOpposingBorders(int(topBorder), int(bottomBorder), int(renderH))
 	clampedLeft, clampedRight := clampOpposingBorders(int(leftBorder), int(rightBorder), int(renderW))
-	drawBorders(int(renderX), int(renderY), int(renderW), int(renderH),
-		clampedTop, clampedRight, clampedBottom, clampedLeft, borderColor)
+	innerBox := render.Rect{
+		X: box.X + float32(clampedLeft),
+		Y: box.Y + float32(clampedTop),
+		W: box.W - float32(clampedLeft+clampedRight),
+		H: box.H - float32(clampedTop+clampedBottom),
+	}
+	innerRadii := radii.Inset(float32(clampedTop), float32(clampedRight), float32(clampedBottom), float32(clampedLeft))
+	if radii.IsZero() {
+		r.drawBorders(int(renderX), int(renderY), int(renderW), int(renderH),
+			clampedTop, clampedRight, clampedBottom, clampedLeft, borderColor)
+	} else if clampedTop+clampedRight+clampedBottom+clampedLeft > 0 {
+		r.drawRoundedBorders(box, innerBox, radii, innerRadii, borderColor)
+	}
 
 	paddingTop := scaledI32(el.Padding[0], scale)
 	paddingRight := scaledI32(el.Padding[1], scale)
@@ -578,16 +701,80 @@ func (r *RaylibRenderer) renderStandardElement(el *render.RenderElement, scale f
 	contentHeight := maxI32(0, int32(contentHeight_f32))
 
 	if contentWidth > 0 && contentHeight > 0 {
-		rl.BeginScissorMode(contentX, contentY, contentWidth, contentHeight)
+		r.pushScissor(render.Rect{X: float32(contentX), Y: float32(contentY), W: float32(contentWidth), H: float32(contentHeight)})
+		// Content, like an image filling a button, stays within the rounded corners.
+		rounded := r.pushRoundedClip(innerBox, innerRadii)
 		// Use el.ResolvedFontSize for text rendering
 		scaledResolvedFontSize := MaxF(1.0, el.ResolvedFontSize*scale) // Use resolved font size
 		r.drawContent(el, int(contentX), int(contentY), int(contentWidth), int(contentHeight), scale, effectiveFgColor, scaledResolvedFontSize)
-		rl.EndScissorMode()
+		if rounded {
+			r.popRoundedClip()
+		}
+		r.popScissor()
 	}
 
-	for _, child := range el.Children {
+	r.drawChildren(el, scale)
+}
+
+// drawChildren draws el's children in paint order, clipped to el's padding box,
+// with its rounded corners, if el clips its children.
+func (r *RaylibRenderer) drawChildren(el *render.RenderElement, scale float32) {
+	clips := render.ClipsChildren(el)
+	rounded := false
+	if clips {
+		r.pushScissor(render.PaddingBox(el, scale))
+		rounded = r.pushRoundedClip(render.PaddingBox(el, scale), render.PaddingBoxRadii(el, scale))
+	}
+	for _, child := range render.PaintOrder(el.Children) {
 		r.renderElementRecursiveWithCustomDraw(child, scale)
 	}
+	if render.Scrolls(el) {
+		r.drawScrollbars(el)
+	}
+	if rounded {
+		r.popRoundedClip()
+	}
+	if clips {
+		r.popScissor()
+	}
+}
+
+// drawScrollbars draws the scrollbars of a scrolling element over its content.
+func (r *RaylibRenderer) drawScrollbars(el *render.RenderElement) {
+	opacity := render.EffectiveOpacity(el)
+	trackColor := render.ApplyOpacity(el.ScrollbarTrackColor, opacity)
+	thumbColor := render.ApplyOpacity(el.ScrollbarColor, opacity)
+	s := &el.Scroll
+	for _, bar := range [][2]render.Rect{{s.TrackV, s.ThumbV}, {s.TrackH, s.ThumbH}} {
+		track, thumb := bar[0], bar[1]
+		if track.W <= 0 || track.H <= 0 {
+			continue
+		}
+		if trackColor.A > 0 {
+			r.fillRect(int32(track.X), int32(track.Y), int32(track.W), int32(track.H), trackColor)
+		}
+		r.fillRect(int32(thumb.X), int32(thumb.Y), int32(thumb.W), int32(thumb.H), thumbColor)
+	}
+}
+
+// pushScissor restricts drawing to the part of rect inside the current scissor.
+func (r *RaylibRenderer) pushScissor(rect render.Rect) {
+	if n := len(r.scissors); n > 0 {
+		rect = rect.Intersect(r.scissors[n-1])
+	}
+	r.scissors = append(r.scissors, rect)
+	rl.BeginScissorMode(int32(rect.X), int32(rect.Y), int32(rect.W), int32(rect.H))
+}
+
+// popScissor restores the scissor that was active before the last pushScissor.
+func (r *RaylibRenderer) popScissor() {
+	r.scissors = r.scissors[:len(r.scissors)-1]
+	if n := len(r.scissors); n > 0 {
+		rect := r.scissors[n-1]
+		rl.BeginScissorMode(int32(rect.X), int32(rect.Y), int32(rect.W), int32(rect.H))
+		return
+	}
+	rl.EndScissorMode()
 }
 
 // drawContent now takes scaledResolvedFontSize
@@ -598,51 +785,96 @@ func (r *RaylibRenderer) drawContent(el *render.RenderElement, cx, cy, cw, ch in
 			fontSize = 1
 		}
 
-		textWidthMeasured := rl.MeasureText(el.Text, fontSize)
-		textHeightMeasured := fontSize
+		textFont := r.fontFor(el)
+		lines := render.WrapText(textFont, el.Text, float32(fontSize), float32(cw), int(el.MaxLines))
+		lineHeight := render.TextLineHeight(el, textFont, float32(fontSize), scale)
+
+		// The block of lines is centered vertically; each line is aligned on its own.
+		blockTop := float32(cy) + (float32(ch)-lineHeight*float32(len(lines)))/2
+		for i, line := range lines {
+			textWidthMeasured := int32(textFont.MeasureText(line, float32(fontSize)))
 
-		textDrawX := int32(cx)
-		textDrawY := int32(cy + (ch-int(textHeightMeasured))/2)
+			textDrawX := int32(cx)
+			textDrawY := int32(blockTop + lineHeight*float32(i) + (lineHeight-float32(fontSize))/2)
 
-		switch el.TextAlignment {
-		case krb.LayoutAlignCenter:
-			textDrawX = int32(cx + (cw-int(textWidthMeasured))/2)
-		case krb.LayoutAlignEnd:
-			textDrawX = int32(cx + cw - int(textWidthMeasured))
+			switch el.TextAlignment {
+			case krb.LayoutAlignCenter:
+				textDrawX = int32(cx + (cw-int(textWidthMeasured))/2)
+			case krb.LayoutAlignEnd:
+				textDrawX = int32(cx + cw - int(textWidthMeasured))
+			}
+			textFont.DrawText(line, float32(textDrawX), float32(textDrawY), float32(fontSize), effectiveFgColor)
 		}
-		rl.DrawText(el.Text, textDrawX, textDrawY, fontSize, effectiveFgColor)
+	}
+
+	if el.Header.Type == krb.ElemTypeInput {
+		r.drawInput(el, cx, cy, cw, ch, scale, effectiveFgColor, scaledResolvedFontSize)
 	}
 
 	isImageElement := (el.Header.Type == krb.ElemTypeImage || el.Header.Type == krb.ElemTypeButton)
-	if isImageElement && el.TextureLoaded && el.Texture.ID > 0 {
-		texWidth := float32(el.Texture.Width)
-		texHeight := float32(el.Texture.Height)
-		sourceRec := rl.NewRectangle(0, 0, texWidth, texHeight)
-		destRec := rl.NewRectangle(float32(cx), float32(cy), float32(cw), float32(ch))
-		if destRec.Width > 0 && destRec.Height > 0 && sourceRec.Width > 0 && sourceRec.Height > 0 {
-			rl.DrawTexturePro(el.Texture, sourceRec, destRec, rl.NewVector2(0, 0), 0.0, rl.White)
+	texture, hasTexture := el.Texture.(rl.Texture2D)
+	if isImageElement && el.TextureLoaded && hasTexture && texture.ID > 0 {
+		destRec := render.Rect{X: float32(cx), Y: float32(cy), W: float32(cw), H: float32(ch)}
+		if destRec.W > 0 && destRec.H > 0 && texture.Width > 0 && texture.Height > 0 {
+			tint := render.ApplyOpacity(rl.White, render.EffectiveOpacity(el))
+			r.drawTexture(texture, destRec, tint)
 		}
 	}
 }
 
-func drawBorders(x, y, w, h, top, right, bottom, left int, color rl.Color) {
+// drawInput draws the single line of an Input's text, scrolled to keep the
+// caret visible, with the selection highlight and blinking caret if it has focus.
+func (r *RaylibRenderer) drawInput(el *render.RenderElement, cx, cy, cw, ch int, scale float32, effectiveFgColor rl.Color, scaledResolvedFontSize float32) {
+	fontSize := int32(scaledResolvedFontSize)
+	if fontSize < 1 {
+		fontSize = 1
+	}
+	textFont := r.fontFor(el)
+	textDrawX := float32(cx)
+	textDrawY := float32(cy + (ch-int(fontSize))/2)
+
+	in := r.events.TextInput()
+	if in == nil || in.El != el {
+		textFont.DrawText(el.Text, textDrawX, textDrawY, float32(fontSize), effectiveFgColor)
+		return
+	}
+	in.ScrollToCaret(textFont, float32(fontSize), float32(cw))
+	textDrawX -= float32(int32(in.ScrollX))
+
+	selStartX, selEndX := in.SelectionX(textFont, float32(fontSize))
+	if selEndX > selStartX {
+		r.fillRect(int32(textDrawX+selStartX), int32(textDrawY), int32(selEndX-selStartX), fontSize, render.SelectionColor(effectiveFgColor))
+	}
+	textFont.DrawText(el.Text, textDrawX, textDrawY, float32(fontSize), effectiveFgColor)
+	if in.CaretVisible(r.animator.Now()) {
+		caretWidth := int32(MaxF(1, scale))
+		r.fillRect(int32(textDrawX+in.CaretX(textFont, float32(fontSize))), int32(textDrawY), caretWidth, fontSize, effectiveFgColor)
+	}
+}
+
+// fontSize returns the whole-pixel size el's text is drawn at.
+func (r *RaylibRenderer) fontSize(el *render.RenderElement) float32 {
+	return float32(int32(MaxF(1.0, el.ResolvedFontSize*r.scaleFactor)))
+}
+
+func (r *RaylibRenderer) drawBorders(x, y, w, h, top, right, bottom, left int, color rl.Color) {
 	if color.A == 0 {
 		return
 	}
 	if top > 0 {
-		rl.DrawRectangle(int32(x), int32(y), int32(w), int32(top), color)
+		r.fillRect(int32(x), int32(y), int32(w), int32(top), color)
 	}
 	if bottom > 0 {
-		rl.DrawRectangle(int32(x), int32(y+h-bottom), int32(w), int32(bottom), color)
+		r.fillRect(int32(x), int32(y+h-bottom), int32(w), int32(bottom), color)
 	}
 	sideY := y + top
 	sideH := h - top - bottom
 	if sideH > 0 {
 		if left > 0 {
-			rl.DrawRectangle(int32(x), int32(sideY), int32(left), int32(sideH), color)
+			r.fillRect(int32(x), int32(sideY), int32(left), int32(sideH), color)
 		}
 		if right > 0 {
-			rl.DrawRectangle(int32(x+w-right), int32(sideY), int32(right), int32(sideH), color)
+			r.fillRect(int32(x+w-right), int32(sideY), int32(right), int32(sideH), color)
 		}
 	}
 }
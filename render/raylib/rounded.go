@@ -0,0 +1,227 @@
+// render/raylib/rounded.go
+package raylib
+
+import (
+	"math"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+
+	"github.com/kryonlabs/kryon-go-runtime/render"
+)
+
+// vertex is a polygon corner in window pixels, with the texture coordinates
+// drawn there when the polygon is textured.
+type vertex struct {
+	x, y, u, v float32
+}
+
+// cornerSegments returns the number of straight segments rounded corners of
+// radii are drawn with, enough for the largest to look smooth.
+func cornerSegments(radii render.CornerRadii) int {
+	largest := float32(0)
+	for _, corner := range radii {
+		largest = max(largest, corner.X, corner.Y)
+	}
+	return min(max(int(math.Sqrt(float64(largest))*2), 2), 24)
+}
+
+// cornerStartAngles are the angles, in degrees with y pointing down, at which
+// roundedOutline starts each corner: Top-left, Top-right, Bottom-right and
+// Bottom-left. Every corner then turns 90 degrees back.
+var cornerStartAngles = [4]float64{270, 360, 90, 180}
+
+// roundedOutline returns the outline of box with its corners rounded by radii,
+// counter-clockwise on screen, the winding raylib draws, from the top end of
+// the top-left corner. Every corner has segments+1 points, so outlines of
+// boxes with different radii pair up point by point. Texture coordinates map
+// box to the whole texture.
+func roundedOutline(box render.Rect, radii render.CornerRadii, segments int) []vertex {
+	points := make([]vertex, 0, 4*(segments+1))
+	for _, corner := range [4]int{0, 3, 2, 1} {
+		radius := radii[corner]
+		if radius.X <= 0 || radius.Y <= 0 {
+			radius = render.Corner{}
+		}
+		rounded := radii
+		rounded[corner] = radius
+		cx, cy := rounded.Center(box, corner)
+		for i := 0; i <= segments; i++ {
+			angle := (cornerStartAngles[corner] - 90*float64(i)/float64(segments)) * math.Pi / 180
+			x := cx + radius.X*float32(math.Cos(angle))
+			y := cy + radius.Y*float32(math.Sin(angle))
+			points = append(points, boxVertex(box, x, y))
+		}
+	}
+	return points
+}
+
+// rectOutline returns the outline of box in the winding of roundedOutline.
+func rectOutline(box render.Rect) []vertex {
+	return []vertex{
+		boxVertex(box, box.X, box.Y),
+		boxVertex(box, box.X, box.Y+box.H),
+		boxVertex(box, box.X+box.W, box.Y+box.H),
+		boxVertex(box, box.X+box.W, box.Y),
+	}
+}
+
+// boxVertex returns the vertex at (x, y), with texture coordinates mapping box
+// to the whole texture.
+func boxVertex(box render.Rect, x, y float32) vertex {
+	p := vertex{x: x, y: y}
+	if box.W > 0 && box.H > 0 {
+		p.u, p.v = (x-box.X)/box.W, (y-box.Y)/box.H
+	}
+	return p
+}
+
+// clipPolygon returns the part of polygon inside the convex polygon clip, both
+// wound like roundedOutline. Texture coordinates are interpolated along the
+// cut edges.
+func clipPolygon(polygon, clip []vertex) []vertex {
+	for i := range clip {
+		if len(polygon) == 0 {
+			break
+		}
+		a, b := clip[i], clip[(i+1)%len(clip)]
+		// Outlines wound counter-clockwise on screen, with y pointing down,
+		// have their inside where this is not positive.
+		side := func(p vertex) float32 {
+			return (b.x-a.x)*(p.y-a.y) - (b.y-a.y)*(p.x-a.x)
+		}
+		input := polygon
+		polygon = make([]vertex, 0, len(input)+1)
+		for j, current := range input {
+			previous := input[(j+len(input)-1)%len(input)]
+			currentSide, previousSide := side(current), side(previous)
+			if (currentSide <= 0) != (previousSide <= 0) {
+				t := previousSide / (previousSide - currentSide)
+				polygon = append(polygon, vertex{
+					x: previous.x + (current.x-previous.x)*t,
+					y: previous.y + (current.y-previous.y)*t,
+					u: previous.u + (current.u-previous.u)*t,
+					v: previous.v + (current.v-previous.v)*t,
+				})
+			}
+			if currentSide <= 0 {
+				polygon = append(polygon, current)
+			}
+		}
+	}
+	return polygon
+}
+
+// signedArea returns twice the area of polygon, negative if it is wound like
+// roundedOutline.
+func signedArea(polygon []vertex) float32 {
+	area := float32(0)
+	for i, p := range polygon {
+		q := polygon[(i+1)%len(polygon)]
+		area += p.x*q.y - q.x*p.y
+	}
+	return area
+}
+
+// pushRoundedClip restricts the fills and images drawn until the matching
+// popRoundedClip to box with its corners rounded by radii, within any rounded
+// clip already active. Text is clipped by the scissor only. It reports
+// whether it pushed a clip, which it does not for square corners.
+func (r *RaylibRenderer) pushRoundedClip(box render.Rect, radii render.CornerRadii) bool {
+	if radii.IsZero() {
+		return false
+	}
+	outline := roundedOutline(box, radii, cornerSegments(radii))
+	if n := len(r.roundedClips); n > 0 {
+		outline = clipPolygon(outline, r.roundedClips[n-1])
+	}
+	r.roundedClips = append(r.roundedClips, outline)
+	return true
+}
+
+// popRoundedClip restores the rounded clip active before the last pushRoundedClip.
+func (r *RaylibRenderer) popRoundedClip() {
+	r.roundedClips = r.roundedClips[:len(r.roundedClips)-1]
+}
+
+// fillPolygon fills the convex polygon, wound like roundedOutline, with c or,
+// with a texture, with the texture tinted by c, cut to the active rounded clip.
+func (r *RaylibRenderer) fillPolygon(polygon []vertex, c rl.Color, texture *rl.Texture2D) {
+	if n := len(r.roundedClips); n > 0 {
+		polygon = clipPolygon(polygon, r.roundedClips[n-1])
+	}
+	if len(polygon) < 3 || c.A == 0 {
+		return
+	}
+	rl.CheckRenderBatchLimit(int32(3 * (len(polygon) - 2)))
+	if texture != nil {
+		rl.SetTexture(texture.ID)
+	}
+	rl.Begin(rl.Triangles)
+	rl.Color4ub(c.R, c.G, c.B, c.A)
+	for i := 1; i+1 < len(polygon); i++ {
+		for _, p := range [3]vertex{polygon[0], polygon[i], polygon[i+1]} {
+			rl.TexCoord2f(p.u, p.v)
+			rl.Vertex2f(p.x, p.y)
+		}
+	}
+	rl.End()
+	if texture != nil {
+		rl.SetTexture(0)
+	}
+}
+
+// fillRect fills a rectangle with c, cut to the active rounded clip.
+func (r *RaylibRenderer) fillRect(x, y, w, h int32, c rl.Color) {
+	if len(r.roundedClips) == 0 {
+		rl.DrawRectangle(x, y, w, h, c)
+		return
+	}
+	if w > 0 && h > 0 {
+		r.fillPolygon(rectOutline(render.Rect{X: float32(x), Y: float32(y), W: float32(w), H: float32(h)}), c, nil)
+	}
+}
+
+// fillRoundedRect fills box with its corners rounded by radii.
+func (r *RaylibRenderer) fillRoundedRect(box render.Rect, radii render.CornerRadii, c rl.Color) {
+	if radii.IsZero() {
+		r.fillRect(int32(box.X), int32(box.Y), int32(box.W), int32(box.H), c)
+		return
+	}
+	r.fillPolygon(roundedOutline(box, radii, cornerSegments(radii)), c, nil)
+}
+
+// drawRoundedBorders fills the ring between outer and inner, with their
+// corners rounded by outerRadii and innerRadii; inner lies within outer.
+func (r *RaylibRenderer) drawRoundedBorders(outer, inner render.Rect, outerRadii, innerRadii render.CornerRadii, c rl.Color) {
+	if c.A == 0 {
+		return
+	}
+	segments := cornerSegments(outerRadii)
+	outerPoints := roundedOutline(outer, outerRadii, segments)
+	innerPoints := roundedOutline(inner, innerRadii, segments)
+	// The ring is drawn as the quads between matching points of the outlines.
+	for i := range outerPoints {
+		next := (i + 1) % len(outerPoints)
+		quad := []vertex{outerPoints[i], outerPoints[next], innerPoints[next], innerPoints[i]}
+		area := signedArea(quad)
+		if area == 0 {
+			continue
+		}
+		if area > 0 {
+			quad[1], quad[3] = quad[3], quad[1]
+		}
+		r.fillPolygon(quad, c, nil)
+	}
+}
+
+// drawTexture draws texture stretched over dest, tinted by tint, cut to the
+// active rounded clip.
+func (r *RaylibRenderer) drawTexture(texture rl.Texture2D, dest render.Rect, tint rl.Color) {
+	if len(r.roundedClips) == 0 {
+		sourceRec := rl.NewRectangle(0, 0, float32(texture.Width), float32(texture.Height))
+		destRec := rl.NewRectangle(dest.X, dest.Y, dest.W, dest.H)
+		rl.DrawTexturePro(texture, sourceRec, destRec, rl.NewVector2(0, 0), 0.0, tint)
+		return
+	}
+	r.fillPolygon(rectOutline(dest), tint, &texture)
+}
@@ -2,8 +2,9 @@
 package render
 
 import (
+	"image/color"
+
 	"github.com/kryonlabs/kryon-go-runtime/krb"
-	rl "github.com/gen2brain/raylib-go/raylib"
 )
 
 const (
@@ -18,35 +19,58 @@ type EventCallbackInfo struct {
 }
 
 type RenderElement struct {
-	Header               krb.ElementHeader
-	OriginalIndex        int
-	Parent               *RenderElement
-	Children             []*RenderElement
-	BgColor              rl.Color
-	FgColor              rl.Color
-	BorderColor          rl.Color
-	BorderWidths         [4]uint8 // Top, Right, Bottom, Left
-	Padding              [4]uint8 // Top, Right, Bottom, Left
-	ResolvedFontSize     float32  // Stores the actual font size after style, direct props, and inheritance. 0.0 means "unset".
-	TextAlignment        uint8    // Corresponds to krb.LayoutAlignStart, Center, End
-	Text                 string
-	ResourceIndex        uint8 // Index into KRB Resource Table
-	Texture              rl.Texture2D
-	TextureLoaded        bool
-	RenderX              float32
-	RenderY              float32
-	RenderW              float32
-	RenderH              float32
-	IntrinsicW           int // Can be used by layout for initial content size estimation
-	IntrinsicH           int // Can be used by layout for initial content size estimation
-	IsVisible            bool
-	IsInteractive        bool // True if element type is Button, Input, or other interactive standard types
-	IsActive             bool // General purpose active state flag, can be used by event handlers or custom logic
-	ActiveStyleNameIndex uint8 // KRB String Table index for the name of an "active" style (optional)
-	InactiveStyleNameIndex uint8 // KRB String Table index for the name of an "inactive/base" style (optional)
-	EventHandlers        []EventCallbackInfo
-	DocRef               *krb.Document // Reference to the parsed KRB document
-	SourceElementName    string        // Debug name, usually from KRY id or component name
+	Header                      krb.ElementHeader
+	OriginalIndex               int
+	Parent                      *RenderElement
+	Children                    []*RenderElement
+	BgColor                     color.RGBA
+	FgColor                     color.RGBA
+	BorderColor                 color.RGBA
+	BorderWidths                [4]uint8  // Top, Right, Bottom, Left
+	BorderRadius                [4]uint16 // Top-left, Top-right, Bottom-right, Bottom-left corner radii in unscaled pixels
+	Padding                     [4]uint8  // Top, Right, Bottom, Left
+	Margin                      [4]uint8  // Top, Right, Bottom, Left; space kept clear around the border box in the parent's layout
+	ResolvedFontSize            float32   // Stores the actual font size after style, direct props, and inheritance. 0.0 means "unset".
+	TextAlignment               uint8     // Corresponds to krb.LayoutAlignStart, Center, End
+	Text                        string
+	ResourceIndex               uint8       // Index into KRB Resource Table
+	FontResourceIndex           uint8       // Index of the element's ResTypeFont resource; InvalidResourceIndex selects the backend's default font
+	LineHeight                  float32     // Distance between lines of text in unscaled pixels; 0 uses LineHeightRatio or the font's line height
+	LineHeightRatio             float32     // Distance between lines of text as a multiple of the font size; 0 means unset
+	MaxLines                    uint8       // Text beyond this many lines is cut off with an ellipsis; 0 means unlimited
+	ZIndex                      int         // Siblings with a higher ZIndex are drawn, and hit-tested, on top
+	Overflow                    uint8       // krb.OverflowVisible, Hidden or Scroll; anything but Visible clips children
+	Scroll                      ScrollState // Scroll position and extent if Overflow is krb.OverflowScroll
+	ScrollbarWidth              uint8       // Unscaled pixels
+	ScrollbarColor              color.RGBA  // Scrollbar thumb
+	ScrollbarTrackColor         color.RGBA  // Scrollbar track, transparent by default
+	List                        *ListView   // Rows of a krb.ElemTypeList bound to a data source; nil for other elements
+	GridColumns                 []GridTrack // Column tracks of a krb.ElemTypeGrid
+	GridRows                    []GridTrack // Row tracks of a krb.ElemTypeGrid; rows beyond them fit their content
+	GridCell                    GridCell    // Placement in a parent grid
+	Flex                        Flex        // Main-axis sizing in a parent's flow
+	Texture                     any         // Backend handle of the loaded image (rl.Texture2D for raylib, image.Image for software), valid when TextureLoaded
+	TextureLoaded               bool
+	TextureWidth                int32 // Natural size of the loaded image in pixels, valid when TextureLoaded
+	TextureHeight               int32
+	RenderX                     float32
+	RenderY                     float32
+	RenderW                     float32
+	RenderH                     float32
+	IntrinsicW                  int // Can be used by layout for initial content size estimation
+	IntrinsicH                  int // Can be used by layout for initial content size estimation
+	IsVisible                   bool
+	IsInteractive               bool  // True if element type is Button, Input, or other interactive standard types
+	Focusable                   bool  // Interactive, or given a TabIndexKey; can take keyboard focus
+	TabIndex                    int   // From TabIndexKey: negative leaves the element out of Tab order, positive moves it first
+	IsActive                    bool  // General purpose active state flag, can be used by event handlers or custom logic
+	ActiveStyleNameIndex        uint8 // KRB String Table index for the name of an "active" style (optional)
+	InactiveStyleNameIndex      uint8 // KRB String Table index for the name of an "inactive/base" style (optional)
+	EventHandlers               []EventCallbackInfo
+	AnimationRefs               []krb.AnimationRef // Animations attached to this element, fired by their Trigger
+	Opacity                     float32            // 0.0-1.0, multiplied with ancestors' opacity when drawing. Initialized to 1.0.
+	DocRef                      *krb.Document      // Reference to the parsed KRB document
+	SourceElementName           string             // Debug name, usually from KRY id or component name
 	IsExpandedAsNestedComponent bool
 }
 
@@ -55,12 +79,12 @@ type WindowConfig struct {
 	Height             int
 	Title              string
 	Resizable          bool
-	ScaleFactor        float32  // Global UI scale factor
-	DefaultBg          rl.Color // Window clear color
-	DefaultFgColor     rl.Color // Root default foreground/text color for inheritance
-	DefaultBorderColor rl.Color // Default for borders if width is set but color isn't
-	DefaultFontSize    float32  // Root default font size for inheritance
-	// DefaultFontFamily string // Future: if font families are supported
+	ScaleFactor        float32    // Global UI scale factor
+	DefaultBg          color.RGBA // Window clear color
+	DefaultFgColor     color.RGBA // Root default foreground/text color for inheritance
+	DefaultBorderColor color.RGBA // Default for borders if width is set but color isn't
+	DefaultFontSize    float32    // Root default font size for inheritance
+	DefaultFontFamily  string     // Name of the font resource inherited by all elements; "" selects the backend's default font
 }
 
 // Renderer defines the core interface that all Kryon rendering backends must implement.
@@ -73,15 +97,17 @@ type Renderer interface {
 	ShouldClose() bool
 
 	// --- Frame Lifecycle (Refactored) ---
-	BeginFrame()                                   // Prepares for drawing (e.g., BeginDrawing, ClearBackground)
-	UpdateLayout(roots []*RenderElement)           // Calculates all element positions and sizes
-	PollEventsAndProcessInteractions()             // Handles input, triggers callbacks based on fresh layout
-	DrawFrame(roots []*RenderElement)              // Draws the UI using the computed layout
-	EndFrame()                                     // Finalizes frame drawing (e.g., EndDrawing)
+	BeginFrame()                         // Prepares for drawing (e.g., BeginDrawing, ClearBackground)
+	UpdateLayout(roots []*RenderElement) // Calculates all element positions and sizes
+	PollEventsAndProcessInteractions()   // Handles input, triggers callbacks based on fresh layout
+	DrawFrame(roots []*RenderElement)    // Draws the UI using the computed layout
+	EndFrame()                           // Finalizes frame drawing (e.g., EndDrawing)
 
 	// --- Event and Component Registration ---
 	RegisterEventHandler(name string, handler func())
+	RegisterEventFunc(name string, handler EventFunc) // Like RegisterEventHandler, with the event's details
 	RegisterCustomComponent(identifier string, handler CustomComponentHandler) error
+	BindList(elementID string, source ListDataSource) (*ListView, error) // Shows source's rows in a List element
 
 	// --- Resource Management ---
 	LoadAllTextures() error // Loads all image resources referenced in the KRB
@@ -95,6 +121,8 @@ type Renderer interface {
 	)
 	// Allows runtime changes to an element's style to be reflected visually.
 	ReResolveElementVisuals(el *RenderElement)
+	// Returns the elements at window coordinates (x, y), topmost first.
+	HitTest(x, y float32) []*RenderElement
 }
 
 // CustomDrawer interface allows a custom component to take over its own drawing logic.
@@ -121,7 +149,7 @@ type CustomEventHandler interface {
 	// - handled: If true, indicates the event was fully handled by this custom handler,
 	//            and standard KRB event callbacks for this event on this element might be skipped.
 	// - err: Any error encountered.
-    HandleEvent(el *RenderElement, eventType krb.EventType, rendererInstance Renderer) (handled bool, err error)
+	HandleEvent(el *RenderElement, eventType krb.EventType, rendererInstance Renderer) (handled bool, err error)
 }
 
 // CustomComponentHandler defines an interface for Go code that provides specialized behavior
@@ -147,9 +175,9 @@ func DefaultWindowConfig() WindowConfig {
 		Title:              "Kryon Application",
 		Resizable:          true,
 		ScaleFactor:        1.0,
-		DefaultBg:          rl.NewColor(30, 30, 30, 255), // Dark Gray
-		DefaultFgColor:     rl.RayWhite,                   // White text
-		DefaultBorderColor: rl.Gray,                       // Neutral gray
-		DefaultFontSize:    BaseFontSize,                  // Use the defined constant
+		DefaultBg:          color.RGBA{30, 30, 30, 255},    // Dark Gray
+		DefaultFgColor:     color.RGBA{245, 245, 245, 255}, // White text
+		DefaultBorderColor: color.RGBA{130, 130, 130, 255}, // Neutral gray
+		DefaultFontSize:    BaseFontSize,                   // Use the defined constant
 	}
-}
\ No newline at end of file
+}
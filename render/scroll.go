@@ -0,0 +1,330 @@
+// render/scroll.go
+package render
+
+import (
+	"image/color"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/kryonlabs/kryon-go-runtime/krb"
+)
+
+// Custom properties styling the scrollbars of an element whose overflow
+// scrolls. Colors are "#RGB", "#RRGGBB" or "#RRGGBBAA"; the width is in
+// unscaled pixels.
+const (
+	ScrollbarWidthKey      = "scrollbarWidth"
+	ScrollbarColorKey      = "scrollbarColor"
+	ScrollbarTrackColorKey = "scrollbarTrackColor"
+)
+
+const (
+	DefaultScrollbarWidth = 8  // Unscaled pixels
+	WheelScrollStep       = 40 // Unscaled pixels scrolled per mouse wheel notch
+	DragScrollThreshold   = 8  // Window pixels a press has to move before it drags the content
+)
+
+var DefaultScrollbarColor = color.RGBA{R: 0x90, G: 0x90, B: 0x90, A: 0xB0}
+
+// ScrollState is the scroll position of an element whose overflow scrolls,
+// together with the geometry of its last layout. All values are window pixels.
+//
+// Layout places the element's children as if it were not scrolled and then
+// moves all its descendants by the offset, so drawing and hit-testing need no
+// further adjustment.
+type ScrollState struct {
+	X, Y       float32 // Offset of the content; kept between 0 and MaxX, MaxY
+	MaxX, MaxY float32 // Largest offsets; 0 if the content fits the viewport
+	Viewport   Rect    // Padding box the content is clipped to
+
+	// Scrollbar tracks and thumbs, drawn over the content along the right and
+	// bottom edges. They are empty if the content fits in that direction.
+	TrackV, ThumbV Rect
+	TrackH, ThumbH Rect
+}
+
+// defaultOverflow returns the overflow of an element of type elemType that
+// sets none: krb.OverflowScroll for scrollables, krb.OverflowVisible otherwise.
+func defaultOverflow(elemType krb.ElementType) uint8 {
+	if elemType == krb.ElemTypeScrollable || elemType == krb.ElemTypeList {
+		return krb.OverflowScroll
+	}
+	return krb.OverflowVisible
+}
+
+// Scrolls reports whether el's content can be scrolled.
+func Scrolls(el *RenderElement) bool {
+	return el != nil && el.Overflow == krb.OverflowScroll
+}
+
+// resolveElementScrollbar applies the scrollbar custom properties.
+func resolveElementScrollbar(doc *krb.Document, el *RenderElement) {
+	el.ScrollbarWidth = DefaultScrollbarWidth
+	el.ScrollbarColor = DefaultScrollbarColor
+	el.ScrollbarTrackColor = color.RGBA{}
+	if value, found := GetCustomPropertyValue(el, ScrollbarWidthKey, doc); found {
+		if width, err := strconv.ParseUint(strings.TrimSpace(value), 10, 8); err == nil {
+			el.ScrollbarWidth = uint8(width)
+		} else {
+			log.Printf("WARN resolveElementScrollbar: Invalid %s '%s' on '%s'.", ScrollbarWidthKey, value, el.SourceElementName)
+		}
+	}
+	for _, c := range []struct {
+		key string
+		dst *color.RGBA
+	}{
+		{ScrollbarColorKey, &el.ScrollbarColor},
+		{ScrollbarTrackColorKey, &el.ScrollbarTrackColor},
+	} {
+		value, found := GetCustomPropertyValue(el, c.key, doc)
+		if !found {
+			continue
+		}
+		if parsed, ok := ParseHexColor(value); ok {
+			*c.dst = parsed
+		} else {
+			log.Printf("WARN resolveElementScrollbar: Invalid %s '%s' on '%s'.", c.key, value, el.SourceElementName)
+		}
+	}
+}
+
+// ApplyScrolling moves the descendants of every scrolling element under roots
+// by its scroll offset and updates its ScrollState. Layout calls it once all
+// elements are placed.
+func ApplyScrolling(roots []*RenderElement, scale float32) {
+	for _, root := range roots {
+		applyScrolling(root, scale)
+	}
+}
+
+func applyScrolling(el *RenderElement, scale float32) {
+	if el == nil || !el.IsVisible {
+		return
+	}
+	if Scrolls(el) {
+		layoutScroll(el, scale)
+		shiftDescendants(el, -el.Scroll.X, -el.Scroll.Y)
+	}
+	for _, child := range el.Children {
+		applyScrolling(child, scale)
+	}
+}
+
+// layoutScroll measures el's content, which is not yet moved by the scroll
+// offset, and sets up its scroll extent and scrollbars.
+func layoutScroll(el *RenderElement, scale float32) {
+	s := &el.Scroll
+	s.Viewport = PaddingBox(el, scale)
+	padRight := scaledRound(el.Padding[1], scale)
+	padBottom := scaledRound(el.Padding[2], scale)
+
+	contentW, contentH := s.Viewport.W, s.Viewport.H
+	if el.List != nil { // Only the rows in view are laid out
+		contentH = max(contentH, scaledRound(el.Padding[0], scale)+el.List.contentHeight+padBottom)
+	}
+	for _, child := range el.Children {
+		if child == nil || !child.IsVisible {
+			continue
+		}
+		marginRight, marginBottom := float32(child.Margin[1])*scale, float32(child.Margin[2])*scale
+		contentW = max(contentW, child.RenderX+child.RenderW+marginRight+padRight-s.Viewport.X)
+		contentH = max(contentH, child.RenderY+child.RenderH+marginBottom+padBottom-s.Viewport.Y)
+	}
+	s.MaxX = contentW - s.Viewport.W
+	s.MaxY = contentH - s.Viewport.H
+	s.X = min(max(s.X, 0), s.MaxX)
+	s.Y = min(max(s.Y, 0), s.MaxY)
+
+	barWidth := min(scaledRound(el.ScrollbarWidth, scale), s.Viewport.W, s.Viewport.H)
+	s.TrackV, s.ThumbV, s.TrackH, s.ThumbH = Rect{}, Rect{}, Rect{}, Rect{}
+	vertical, horizontal := s.MaxY > 0 && barWidth > 0, s.MaxX > 0 && barWidth > 0
+	corner := float32(0) // Where both scrollbars meet, neither extends into the corner
+	if vertical && horizontal {
+		corner = barWidth
+	}
+	if vertical {
+		s.TrackV = Rect{X: s.Viewport.X + s.Viewport.W - barWidth, Y: s.Viewport.Y, W: barWidth, H: s.Viewport.H - corner}
+		s.ThumbV = Rect{X: s.TrackV.X, W: barWidth, H: max(s.TrackV.H*s.Viewport.H/contentH, min(2*barWidth, s.TrackV.H))}
+	}
+	if horizontal {
+		s.TrackH = Rect{X: s.Viewport.X, Y: s.Viewport.Y + s.Viewport.H - barWidth, W: s.Viewport.W - corner, H: barWidth}
+		s.ThumbH = Rect{Y: s.TrackH.Y, H: barWidth, W: max(s.TrackH.W*s.Viewport.W/contentW, min(2*barWidth, s.TrackH.W))}
+	}
+	s.placeThumbs()
+}
+
+// placeThumbs positions the scrollbar thumbs along their tracks for the
+// current offset.
+func (s *ScrollState) placeThumbs() {
+	if s.MaxY > 0 {
+		s.ThumbV.Y = s.TrackV.Y + (s.TrackV.H-s.ThumbV.H)*s.Y/s.MaxY
+	}
+	if s.MaxX > 0 {
+		s.ThumbH.X = s.TrackH.X + (s.TrackH.W-s.ThumbH.W)*s.X/s.MaxX
+	}
+}
+
+// ScrollBy scrolls el's content by dx, dy window pixels, as far as its extent
+// allows, and moves its descendants accordingly. It reports whether the
+// offset changed.
+func ScrollBy(el *RenderElement, dx, dy float32) bool {
+	if !Scrolls(el) {
+		return false
+	}
+	s := &el.Scroll
+	x := min(max(s.X+dx, 0), max(s.MaxX, 0))
+	y := min(max(s.Y+dy, 0), max(s.MaxY, 0))
+	if x == s.X && y == s.Y {
+		return false
+	}
+	if el.List != nil {
+		s.X, s.Y = x, y
+		el.List.scrolled() // The rows in view change
+	} else {
+		shiftDescendants(el, s.X-x, s.Y-y)
+		s.X, s.Y = x, y
+	}
+	s.placeThumbs()
+	return true
+}
+
+// ScrollTo scrolls el's content to the offset x, y, see ScrollBy.
+func ScrollTo(el *RenderElement, x, y float32) bool {
+	if !Scrolls(el) {
+		return false
+	}
+	return ScrollBy(el, x-el.Scroll.X, y-el.Scroll.Y)
+}
+
+// ScrollIntoView scrolls every scrolling ancestor of el, innermost first, just
+// far enough to bring el into its viewport.
+func ScrollIntoView(el *RenderElement) {
+	if el == nil {
+		return
+	}
+	for ancestor := el.Parent; ancestor != nil; ancestor = ancestor.Parent {
+		if !Scrolls(ancestor) {
+			continue
+		}
+		v := ancestor.Scroll.Viewport
+		ScrollBy(ancestor,
+			scrollDeltaToShow(el.RenderX, el.RenderW, v.X, v.W),
+			scrollDeltaToShow(el.RenderY, el.RenderH, v.Y, v.H))
+	}
+}
+
+// scrollDeltaToShow returns how far to scroll along one axis to bring the
+// span pos..pos+size into view..view+viewSize, preferring its start.
+func scrollDeltaToShow(pos, size, view, viewSize float32) float32 {
+	switch {
+	case pos < view:
+		return pos - view
+	case pos+size > view+viewSize:
+		return min(pos+size-(view+viewSize), pos-view)
+	}
+	return 0
+}
+
+// scrollTarget returns el or its nearest ancestor that can still scroll by
+// dx, dy, so scrolling reaches outer regions once inner ones hit their end.
+func scrollTarget(el *RenderElement, dx, dy float32) *RenderElement {
+	for ; el != nil; el = el.Parent {
+		if !Scrolls(el) {
+			continue
+		}
+		s := &el.Scroll
+		if (dy < 0 && s.Y > 0) || (dy > 0 && s.Y < s.MaxY) || (dx < 0 && s.X > 0) || (dx > 0 && s.X < s.MaxX) {
+			return el
+		}
+	}
+	return nil
+}
+
+// shiftDescendants moves everything laid out inside el by dx, dy.
+func shiftDescendants(el *RenderElement, dx, dy float32) {
+	if dx == 0 && dy == 0 {
+		return
+	}
+	for _, child := range el.Children {
+		if child == nil {
+			continue
+		}
+		child.RenderX += dx
+		child.RenderY += dy
+		if Scrolls(child) {
+			s := &child.Scroll
+			for _, r := range []*Rect{&s.Viewport, &s.TrackV, &s.ThumbV, &s.TrackH, &s.ThumbH} {
+				r.X += dx
+				r.Y += dy
+			}
+		}
+		shiftDescendants(child, dx, dy)
+	}
+}
+
+// scrollDrag is a press that scrolls an element: on its scrollbar, or on its
+// content once the pointer moved DragScrollThreshold away.
+type scrollDrag struct {
+	el                   *RenderElement
+	vertical, horizontal bool // Dragging a scrollbar thumb; content drags set neither
+	startX, startY       float32
+	startScrollX         float32
+	startScrollY         float32
+	active               bool // The press became a drag; it no longer clicks
+}
+
+// beginScrollbarDrag starts dragging a scrollbar of a scrolling element around
+// target if (x, y) is on one. A press on the track jumps there first.
+func beginScrollbarDrag(target *RenderElement, x, y float32) *scrollDrag {
+	for el := target; el != nil; el = el.Parent {
+		if !Scrolls(el) {
+			continue
+		}
+		s := &el.Scroll
+		switch {
+		case s.TrackV.Contains(x, y):
+			if !s.ThumbV.Contains(x, y) {
+				ScrollBy(el, 0, (y-s.ThumbV.Y-s.ThumbV.H/2)*s.MaxY/max(s.TrackV.H-s.ThumbV.H, 1))
+			}
+			return &scrollDrag{el: el, vertical: true, startX: x, startY: y, startScrollX: s.X, startScrollY: s.Y, active: true}
+		case s.TrackH.Contains(x, y):
+			if !s.ThumbH.Contains(x, y) {
+				ScrollBy(el, (x-s.ThumbH.X-s.ThumbH.W/2)*s.MaxX/max(s.TrackH.W-s.ThumbH.W, 1), 0)
+			}
+			return &scrollDrag{el: el, horizontal: true, startX: x, startY: y, startScrollX: s.X, startScrollY: s.Y, active: true}
+		}
+	}
+	return nil
+}
+
+// beginContentDrag prepares a press on target to drag the content of its
+// nearest ancestor that has something to scroll.
+func beginContentDrag(target *RenderElement, x, y float32) *scrollDrag {
+	for el := target; el != nil; el = el.Parent {
+		if Scrolls(el) && (el.Scroll.MaxX > 0 || el.Scroll.MaxY > 0) {
+			return &scrollDrag{el: el, startX: x, startY: y, startScrollX: el.Scroll.X, startScrollY: el.Scroll.Y}
+		}
+	}
+	return nil
+}
+
+// update scrolls for the pointer having moved to (x, y). It reports whether
+// the press is a drag.
+func (g *scrollDrag) update(x, y float32) bool {
+	dx, dy := x-g.startX, y-g.startY
+	s := &g.el.Scroll
+	switch {
+	case g.vertical:
+		ScrollTo(g.el, s.X, g.startScrollY+dy*s.MaxY/max(s.TrackV.H-s.ThumbV.H, 1))
+	case g.horizontal:
+		ScrollTo(g.el, g.startScrollX+dx*s.MaxX/max(s.TrackH.W-s.ThumbH.W, 1), s.Y)
+	default:
+		if !g.active && dx*dx+dy*dy < DragScrollThreshold*DragScrollThreshold {
+			return false
+		}
+		g.active = true
+		ScrollTo(g.el, g.startScrollX-dx, g.startScrollY-dy)
+	}
+	return g.active
+}
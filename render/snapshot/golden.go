@@ -0,0 +1,111 @@
+// render/snapshot/golden.go
+package snapshot
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"os"
+	"strings"
+)
+
+// UpdateEnv is the environment variable that, when set to a non-empty value,
+// makes MatchGolden and Assert (re)write golden files instead of comparing.
+const UpdateEnv = "KRYON_UPDATE_SNAPSHOTS"
+
+// MismatchError is returned by MatchGolden when a frame differs from its golden
+// image. The actual frame and a diff image are written next to the golden file.
+type MismatchError struct {
+	GoldenPath string
+	ActualPath string
+	DiffPath   string
+	DiffPixels int
+	Allowed    int
+}
+
+func (e *MismatchError) Error() string {
+	return fmt.Sprintf("snapshot: %d pixels differ from %s (allowed %d); actual frame written to %s, diff to %s",
+		e.DiffPixels, e.GoldenPath, e.Allowed, e.ActualPath, e.DiffPath)
+}
+
+// MatchGolden renders the KRB file at krbPath and compares the frame with the
+// PNG at goldenPath.
+func MatchGolden(krbPath, goldenPath string, opts Options) error {
+	frame, err := Render(krbPath, opts)
+	if err != nil {
+		return err
+	}
+	return MatchImage(frame, goldenPath, opts)
+}
+
+// MatchImage compares frame with the PNG at goldenPath using the tolerance in
+// opts. On failure it writes <golden>.actual.png and <golden>.diff.png and
+// returns a *MismatchError. If UpdateEnv is set, frame replaces the golden file.
+func MatchImage(frame image.Image, goldenPath string, opts Options) error {
+	if os.Getenv(UpdateEnv) != "" {
+		if err := SavePNG(goldenPath, frame); err != nil {
+			return fmt.Errorf("snapshot: failed to update golden file: %w", err)
+		}
+		return nil
+	}
+
+	want, err := LoadPNG(goldenPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("snapshot: golden file %s does not exist; run with %s=1 to create it", goldenPath, UpdateEnv)
+	}
+	if err != nil {
+		return err
+	}
+
+	base := strings.TrimSuffix(goldenPath, ".png")
+	actualPath, diffPath := base+".actual.png", base+".diff.png"
+
+	result, err := Compare(frame, want, opts.Tolerance)
+	if err != nil {
+		if saveErr := SavePNG(actualPath, frame); saveErr != nil {
+			return fmt.Errorf("%w (writing actual frame also failed: %v)", err, saveErr)
+		}
+		return fmt.Errorf("%w; actual frame written to %s", err, actualPath)
+	}
+	if result.DiffPixels <= opts.MaxDiffPixels {
+		// Remove artifacts left over from an earlier failing run.
+		os.Remove(actualPath)
+		os.Remove(diffPath)
+		return nil
+	}
+
+	if err := SavePNG(actualPath, frame); err != nil {
+		return fmt.Errorf("snapshot: failed to write actual frame: %w", err)
+	}
+	if err := SavePNG(diffPath, result.Diff); err != nil {
+		return fmt.Errorf("snapshot: failed to write diff image: %w", err)
+	}
+	return &MismatchError{
+		GoldenPath: goldenPath,
+		ActualPath: actualPath,
+		DiffPath:   diffPath,
+		DiffPixels: result.DiffPixels,
+		Allowed:    opts.MaxDiffPixels,
+	}
+}
+
+// TB is the subset of testing.TB used by Assert.
+type TB interface {
+	Helper()
+	Fatalf(format string, args ...any)
+}
+
+// Assert is a test helper that fails t when the KRB file at krbPath no longer
+// renders like the golden PNG at goldenPath:
+//
+//	func TestLoginScreen(t *testing.T) {
+//		snapshot.Assert(t, "testdata/login.krb", "testdata/login.png", snapshot.Options{Width: 400, Height: 300, Tolerance: 2})
+//	}
+//
+// Run the tests with KRYON_UPDATE_SNAPSHOTS=1 to accept the new rendering.
+func Assert(t TB, krbPath, goldenPath string, opts Options) {
+	t.Helper()
+	if err := MatchGolden(krbPath, goldenPath, opts); err != nil {
+		t.Fatalf("%v", err)
+	}
+}
@@ -0,0 +1,163 @@
+// render/snapshot/snapshot.go
+
+// Package snapshot renders KRB files offscreen with the software backend and
+// compares the frames against golden PNG images, so layout and styling
+// regressions are caught without opening a window.
+package snapshot
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"os"
+
+	"github.com/kryonlabs/kryon-go-runtime/krb"
+	"github.com/kryonlabs/kryon-go-runtime/render"
+	"github.com/kryonlabs/kryon-go-runtime/render/software"
+)
+
+// Options controls how a KRB file is rendered and compared. Zero values keep
+// the window configuration stored in the KRB file.
+type Options struct {
+	Width       int     // Canvas width in pixels
+	Height      int     // Canvas height in pixels
+	ScaleFactor float32 // UI scale factor
+
+	// Tolerance is the largest per-channel difference (0-255) at which two
+	// pixels still count as equal.
+	Tolerance uint8
+	// MaxDiffPixels is the number of differing pixels allowed before a
+	// comparison fails.
+	MaxDiffPixels int
+
+	// Setup, if set, runs after the render tree is prepared and before the
+	// first layout, e.g. to register custom components or change styles.
+	Setup func(r *software.SoftwareRenderer, roots []*render.RenderElement) error
+}
+
+// Render loads the KRB file at krbPath and returns a single frame drawn with
+// the software renderer.
+func Render(krbPath string, opts Options) (*image.RGBA, error) {
+	f, err := os.Open(krbPath)
+	if err != nil {
+		return nil, fmt.Errorf("snapshot: %w", err)
+	}
+	defer f.Close()
+
+	doc, err := krb.ReadDocument(f)
+	if err != nil {
+		return nil, fmt.Errorf("snapshot: failed to parse '%s': %w", krbPath, err)
+	}
+	return RenderDocument(doc, krbPath, opts)
+}
+
+// RenderDocument draws a single frame of an already parsed document.
+// krbPath is used to resolve external resources.
+func RenderDocument(doc *krb.Document, krbPath string, opts Options) (*image.RGBA, error) {
+	r := software.NewSoftwareRenderer()
+	defer r.Cleanup()
+
+	roots, config, err := r.PrepareTree(doc, krbPath)
+	if err != nil {
+		return nil, fmt.Errorf("snapshot: %w", err)
+	}
+	if opts.Width > 0 {
+		config.Width = opts.Width
+	}
+	if opts.Height > 0 {
+		config.Height = opts.Height
+	}
+	if opts.ScaleFactor > 0 {
+		config.ScaleFactor = opts.ScaleFactor
+	}
+	if err := r.Init(config); err != nil {
+		return nil, fmt.Errorf("snapshot: %w", err)
+	}
+	if err := r.LoadAllTextures(); err != nil {
+		return nil, fmt.Errorf("snapshot: %w", err)
+	}
+	if opts.Setup != nil {
+		if err := opts.Setup(r, roots); err != nil {
+			return nil, fmt.Errorf("snapshot: setup failed: %w", err)
+		}
+	}
+
+	r.UpdateLayout(roots)
+	r.BeginFrame()
+	r.DrawFrame(roots)
+	r.EndFrame()
+
+	frame := image.NewRGBA(r.Image().Bounds())
+	draw.Draw(frame, frame.Bounds(), r.Image(), image.Point{}, draw.Src)
+	return frame, nil
+}
+
+// Result describes the outcome of Compare.
+type Result struct {
+	DiffPixels int         // Number of pixels outside the tolerance
+	Diff       *image.RGBA // Visual diff: differing pixels red over a faded copy of the expected image
+}
+
+// Compare checks got against want pixel by pixel. Both images must have the
+// same size.
+func Compare(got, want image.Image, tolerance uint8) (Result, error) {
+	if got.Bounds().Size() != want.Bounds().Size() {
+		return Result{}, fmt.Errorf("snapshot: image size %v does not match expected %v", got.Bounds().Size(), want.Bounds().Size())
+	}
+	size := want.Bounds().Size()
+	gotMin, wantMin := got.Bounds().Min, want.Bounds().Min
+
+	result := Result{Diff: image.NewRGBA(image.Rect(0, 0, size.X, size.Y))}
+	for y := 0; y < size.Y; y++ {
+		for x := 0; x < size.X; x++ {
+			g := color.NRGBAModel.Convert(got.At(gotMin.X+x, gotMin.Y+y)).(color.NRGBA)
+			w := color.NRGBAModel.Convert(want.At(wantMin.X+x, wantMin.Y+y)).(color.NRGBA)
+			if channelDiff(g.R, w.R) > tolerance || channelDiff(g.G, w.G) > tolerance ||
+				channelDiff(g.B, w.B) > tolerance || channelDiff(g.A, w.A) > tolerance {
+				result.DiffPixels++
+				result.Diff.SetRGBA(x, y, color.RGBA{255, 0, 0, 255})
+				continue
+			}
+			gray := uint8((uint32(w.R)*299 + uint32(w.G)*587 + uint32(w.B)*114) / 1000)
+			faded := 192 + gray/4
+			result.Diff.SetRGBA(x, y, color.RGBA{faded, faded, faded, 255})
+		}
+	}
+	return result, nil
+}
+
+func channelDiff(a, b uint8) uint8 {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
+
+// LoadPNG reads a PNG image from path.
+func LoadPNG(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	img, err := png.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("snapshot: failed to decode '%s': %w", path, err)
+	}
+	return img, nil
+}
+
+// SavePNG writes img to path as a PNG file.
+func SavePNG(path string, img image.Image) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return fmt.Errorf("snapshot: failed to encode '%s': %w", path, err)
+	}
+	return f.Close()
+}
@@ -0,0 +1,74 @@
+package snapshot
+
+import (
+	"errors"
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// goldenOptions absorbs rounding differences between platforms while still
+// failing on any visible change.
+var goldenOptions = Options{Tolerance: 2, MaxDiffPixels: 8}
+
+func TestExampleGoldens(t *testing.T) {
+	for _, tc := range []struct{ krbPath, goldenPath string }{
+		{"../../examples/button/button.krb", "testdata/button.png"},
+		{"../../examples/tabbar/tab_bar.krb", "testdata/tabbar.png"},
+	} {
+		t.Run(filepath.Base(tc.goldenPath), func(t *testing.T) {
+			Assert(t, tc.krbPath, tc.goldenPath, goldenOptions)
+		})
+	}
+}
+
+func TestMatchImageReportsMismatch(t *testing.T) {
+	t.Setenv(UpdateEnv, "")
+	frame, err := Render("../../examples/button/button.krb", Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	goldenPath := filepath.Join(t.TempDir(), "button.png")
+	if err := SavePNG(goldenPath, frame); err != nil {
+		t.Fatal(err)
+	}
+	if err := MatchImage(frame, goldenPath, Options{}); err != nil {
+		t.Fatalf("frame does not match its own golden: %v", err)
+	}
+
+	for x := 0; x < 3; x++ {
+		frame.SetRGBA(x, 0, color.RGBA{1, 2, 3, 255})
+	}
+	err = MatchImage(frame, goldenPath, Options{MaxDiffPixels: 2})
+	var mismatch *MismatchError
+	if !errors.As(err, &mismatch) {
+		t.Fatalf("MatchImage error = %v, want a *MismatchError", err)
+	}
+	if mismatch.DiffPixels != 3 {
+		t.Errorf("DiffPixels = %d, want 3", mismatch.DiffPixels)
+	}
+	for _, path := range []string{mismatch.ActualPath, mismatch.DiffPath} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("mismatch artifact: %v", err)
+		}
+	}
+	if err := MatchImage(frame, goldenPath, Options{MaxDiffPixels: 3}); err != nil {
+		t.Errorf("3 differing pixels with MaxDiffPixels 3: %v", err)
+	}
+}
+
+func TestMatchImageSizeMismatch(t *testing.T) {
+	t.Setenv(UpdateEnv, "")
+	goldenPath := filepath.Join(t.TempDir(), "button.png")
+	frame, err := Render("../../examples/button/button.krb", Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := SavePNG(goldenPath, frame); err != nil {
+		t.Fatal(err)
+	}
+	if err := MatchGolden("../../examples/button/button.krb", goldenPath, Options{Width: frame.Bounds().Dx() / 2}); err == nil {
+		t.Error("a frame of a different size matched the golden")
+	}
+}
@@ -0,0 +1,200 @@
+package software
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/kryonlabs/kryon-go-runtime/krb"
+	"github.com/kryonlabs/kryon-go-runtime/render"
+)
+
+// eventLog records the KRB event types delivered to the handlers it registers.
+type eventLog []krb.EventType
+
+func (l *eventLog) take() []krb.EventType {
+	got := *l
+	*l = nil
+	return got
+}
+
+func TestEventTypesFromPointer(t *testing.T) {
+	types := []krb.EventType{
+		krb.EventTypeClick, krb.EventTypePress, krb.EventTypeRelease, krb.EventTypeLongPress,
+		krb.EventTypeHover, krb.EventTypeFocus, krb.EventTypeBlur,
+	}
+	b := krb.NewBuilder()
+	button := windowApp(b, 300, 100).AddChild(krb.ElemTypeButton).Text("button").Size(100, 40)
+	for _, eventType := range types {
+		button.Event(eventType, fmt.Sprintf("on%d", eventType))
+	}
+	r, _ := prepare(t, buildDocument(t, b), "test.krb")
+	clock := render.NewManualClock(time.Unix(0, 0))
+	r.SetClock(clock)
+	var log eventLog
+	for _, eventType := range types {
+		eventType := eventType
+		r.RegisterEventHandler(fmt.Sprintf("on%d", eventType), func() { log = append(log, eventType) })
+	}
+
+	steps := []struct {
+		name   string
+		action func()
+		want   []krb.EventType
+	}{
+		{"enter", func() { r.SetMousePosition(10, 10) }, []krb.EventType{krb.EventTypeHover}},
+		{"click", func() { r.Click(10, 10) },
+			[]krb.EventType{krb.EventTypePress, krb.EventTypeFocus, krb.EventTypeRelease, krb.EventTypeClick}},
+		{"press", func() { r.MouseDown(10, 10) }, []krb.EventType{krb.EventTypePress}},
+		{"hold", func() { clock.Advance(render.DefaultLongPressThreshold + time.Millisecond) }, []krb.EventType{krb.EventTypeLongPress}},
+		// A long press replaces the click of the same press.
+		{"release", func() { r.MouseUp(10, 10) }, []krb.EventType{krb.EventTypeRelease}},
+		{"click outside", func() { r.Click(250, 80) }, []krb.EventType{krb.EventTypeHover, krb.EventTypeBlur}},
+	}
+	for _, step := range steps {
+		step.action()
+		r.PollEventsAndProcessInteractions()
+		if got := log.take(); !reflect.DeepEqual(got, step.want) {
+			t.Errorf("%s: got events %v, want %v", step.name, got, step.want)
+		}
+	}
+}
+
+func TestCustomEventDispatch(t *testing.T) {
+	b := krb.NewBuilder()
+	windowApp(b, 100, 100).AddChild(krb.ElemTypeContainer).Size(10, 10).Event(krb.EventTypeCustom, "custom")
+	r, _ := prepare(t, buildDocument(t, b), "test.krb")
+	fired := 0
+	r.RegisterEventHandler("custom", func() { fired++ })
+
+	el := r.GetRenderTree()[1]
+	if !r.Events().Dispatch(el, krb.EventTypeCustom) || fired != 1 {
+		t.Errorf("dispatching EventTypeCustom ran the handler %d times, want 1", fired)
+	}
+	if r.Events().Dispatch(el, krb.EventTypeSubmit) {
+		t.Error("Dispatch reported a handler for an event type the element does not bind")
+	}
+}
+
+func TestEventPayload(t *testing.T) {
+	b := krb.NewBuilder()
+	windowApp(b, 300, 100).AddChild(krb.ElemTypeInput).ID("name").Size(100, 30).
+		Event(krb.EventTypeChange, "record").Event(krb.EventTypeHover, "record").Event(krb.EventTypeClick, "record")
+	r, _ := prepare(t, buildDocument(t, b), "test.krb")
+	var events []render.Event
+	r.RegisterEventFunc("record", func(ev *render.Event) { events = append(events, *ev) })
+
+	r.SetModifiers(render.ModCtrl)
+	r.Click(5, 6)
+	r.TypeText("ab")
+	r.PollEventsAndProcessInteractions()
+	r.SetMousePosition(200, 90)
+	r.PollEventsAndProcessInteractions()
+
+	if len(events) != 4 {
+		t.Fatalf("got %d events, want hover, click, change and hover", len(events))
+	}
+	enter, click, change, leave := events[0], events[1], events[2], events[3]
+	if enter.Type != krb.EventTypeHover || !enter.Hovered {
+		t.Errorf("first event = type %d hovered %v, want hover entering", enter.Type, enter.Hovered)
+	}
+	if got := render.ElementID(enter.Target); got != "name" {
+		t.Errorf("hover target = %q, want \"name\"", got)
+	}
+	if enter.X != 5 || enter.Y != 6 || enter.Modifiers != render.ModCtrl {
+		t.Errorf("hover at (%v,%v) mods %d, want (5,6) mods %d", enter.X, enter.Y, enter.Modifiers, render.ModCtrl)
+	}
+	if click.Type != krb.EventTypeClick || click.Button != 1 {
+		t.Errorf("second event = type %d button %d, want click with button 1", click.Type, click.Button)
+	}
+	if change.Type != krb.EventTypeChange || change.Value != "ab" {
+		t.Errorf("third event = type %d value %q, want change with \"ab\"", change.Type, change.Value)
+	}
+	if leave.Type != krb.EventTypeHover || leave.Hovered {
+		t.Errorf("last event = type %d hovered %v, want hover leaving", leave.Type, leave.Hovered)
+	}
+}
+
+// dispatchStep is one handler invocation seen by TestEventPropagation.
+type dispatchStep struct {
+	handler, target, current string
+	phase                    render.EventPhase
+}
+
+func TestEventPropagation(t *testing.T) {
+	b := krb.NewBuilder()
+	list := windowApp(b, 300, 200).AddChild(krb.ElemTypeContainer).ID("list").Layout(krb.LayoutDirColumn).OnClick("listClick")
+	list.AddChild(krb.ElemTypeText).ID("row1").Text("row one")
+	list.AddChild(krb.ElemTypeText).ID("row2").Text("row two")
+	list.AddChild(krb.ElemTypeButton).ID("btn").Text("button").OnClick("btnClick")
+	r, _ := prepare(t, buildDocument(t, b), "test.krb")
+	els := r.GetRenderTree()
+
+	var steps []dispatchStep
+	record := func(handler string) func(*render.Event) {
+		return func(ev *render.Event) {
+			steps = append(steps, dispatchStep{handler, render.ElementID(ev.Target), render.ElementID(ev.CurrentTarget), ev.Phase})
+		}
+	}
+	r.RegisterEventFunc("listClick", record("list"))
+	stop := false
+	r.RegisterEventFunc("btnClick", func(ev *render.Event) {
+		record("btn")(ev)
+		if stop {
+			ev.StopPropagation()
+		}
+	})
+	r.Events().AddListener(els[0], krb.EventTypeClick, true, record("app"))
+
+	click := func(el *render.RenderElement) []dispatchStep {
+		steps = nil
+		r.Click(5, el.RenderY+2)
+		r.PollEventsAndProcessInteractions()
+		return steps
+	}
+
+	// A click on a plain row reaches the list through the bubble phase.
+	want := []dispatchStep{
+		{"app", "row2", "", render.PhaseCapture},
+		{"list", "row2", "list", render.PhaseBubble},
+	}
+	if got := click(els[3]); !reflect.DeepEqual(got, want) {
+		t.Errorf("click on row2:\n got %v\nwant %v", got, want)
+	}
+
+	want = []dispatchStep{
+		{"app", "btn", "", render.PhaseCapture},
+		{"btn", "btn", "btn", render.PhaseAtTarget},
+		{"list", "btn", "list", render.PhaseBubble},
+	}
+	if got := click(els[4]); !reflect.DeepEqual(got, want) {
+		t.Errorf("click on btn:\n got %v\nwant %v", got, want)
+	}
+	if got := render.ElementID(r.FocusedElement()); got != "btn" {
+		t.Errorf("focused element = %q, want \"btn\"", got)
+	}
+
+	stop = true
+	if got := click(els[4]); !reflect.DeepEqual(got, want[:2]) {
+		t.Errorf("click on btn with StopPropagation:\n got %v\nwant %v", got, want[:2])
+	}
+}
+
+func TestPreventDefaultPressKeepsFocus(t *testing.T) {
+	b := krb.NewBuilder()
+	app := windowApp(b, 300, 100)
+	app.AddChild(krb.ElemTypeButton).ID("first").Text("first").Size(100, 40)
+	app.AddChild(krb.ElemTypeButton).ID("second").Text("second").Size(100, 40).Event(krb.EventTypePress, "press")
+	r, _ := prepare(t, buildDocument(t, b), "test.krb")
+	els := r.GetRenderTree()
+	r.RegisterEventFunc("press", func(ev *render.Event) { ev.PreventDefault() })
+
+	r.Click(els[1].RenderX+2, els[1].RenderY+2)
+	r.PollEventsAndProcessInteractions()
+	r.Click(els[2].RenderX+2, els[2].RenderY+2)
+	r.PollEventsAndProcessInteractions()
+	if got := render.ElementID(r.FocusedElement()); got != "first" {
+		t.Errorf("focused element = %q, want \"first\" after a prevented press", got)
+	}
+}
@@ -0,0 +1,74 @@
+package software
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/kryonlabs/kryon-go-runtime/krb"
+	"github.com/kryonlabs/kryon-go-runtime/render"
+)
+
+func TestFocusNavigation(t *testing.T) {
+	b := krb.NewBuilder()
+	col := windowApp(b, 300, 200).AddChild(krb.ElemTypeContainer).Layout(krb.LayoutDirColumn)
+	col.AddChild(krb.ElemTypeButton).ID("b1").Text("one").Size(100, 30).
+		Event(krb.EventTypeClick, "record").Event(krb.EventTypeFocus, "record").Event(krb.EventTypeBlur, "record")
+	col.AddChild(krb.ElemTypeInput).ID("in").Size(100, 30)
+	col.AddChild(krb.ElemTypeButton).ID("b2").Text("two").Size(100, 30).
+		Event(krb.EventTypeClick, "record").CustomString(render.TabIndexKey, "1")
+	col.AddChild(krb.ElemTypeContainer).ID("card").Size(100, 30).CustomString(render.TabIndexKey, "0")
+	col.AddChild(krb.ElemTypeButton).ID("skip").Text("skip").Size(100, 30).CustomString(render.TabIndexKey, "-1")
+	r, roots := prepare(t, buildDocument(t, b), "test.krb")
+	var events []string
+	r.RegisterEventFunc("record", func(ev *render.Event) {
+		events = append(events, fmt.Sprintf("%d:%s", ev.Type, render.ElementID(ev.Target)))
+	})
+
+	// Positive tab indices come first, then tab index 0 and focusable
+	// elements in document order; -1 is skipped.
+	wantOrder := []string{"b2", "b1", "in", "card"}
+	if got := hitIDs(render.FocusOrder(roots)); !reflect.DeepEqual(got, wantOrder) {
+		t.Errorf("FocusOrder = %v, want %v", got, wantOrder)
+	}
+
+	steps := []struct {
+		key        render.Key
+		shift      bool
+		focused    string
+		wantEvents []string
+	}{
+		{render.KeyTab, false, "b2", nil},
+		{render.KeyTab, false, "b1", []string{"6:b1"}},
+		{render.KeyTab, false, "in", []string{"7:b1"}},
+		{render.KeyTab, false, "card", nil},
+		{render.KeyTab, true, "in", nil},
+		{render.KeyTab, false, "card", nil},
+		{render.KeyTab, false, "b2", nil},
+		{render.KeySpace, false, "b2", []string{"1:b2"}},
+		{render.KeyEnter, false, "b2", []string{"1:b2"}},
+	}
+	for i, step := range steps {
+		events = nil
+		r.PressKey(step.key, step.shift)
+		r.PollEventsAndProcessInteractions()
+		if got := render.ElementID(r.FocusedElement()); got != step.focused {
+			t.Errorf("step %d: focused %q, want %q", i, got, step.focused)
+		}
+		if !reflect.DeepEqual(events, step.wantEvents) {
+			t.Errorf("step %d: events %v, want %v", i, events, step.wantEvents)
+		}
+	}
+	if r.Events().FocusRingTarget() == nil {
+		t.Error("keyboard focus did not show the focus ring")
+	}
+
+	r.Click(10, 10)
+	r.PollEventsAndProcessInteractions()
+	if got := render.ElementID(r.FocusedElement()); got != "b1" {
+		t.Errorf("focused %q after click, want \"b1\"", got)
+	}
+	if r.Events().FocusRingTarget() != nil {
+		t.Error("pointer focus showed the focus ring")
+	}
+}
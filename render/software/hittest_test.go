@@ -0,0 +1,56 @@
+package software
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/kryonlabs/kryon-go-runtime/krb"
+	"github.com/kryonlabs/kryon-go-runtime/render"
+)
+
+func hitIDs(els []*render.RenderElement) []string {
+	ids := make([]string, 0, len(els))
+	for _, el := range els {
+		ids = append(ids, render.ElementID(el))
+	}
+	return ids
+}
+
+func TestHitTest(t *testing.T) {
+	b := krb.NewBuilder()
+	app := windowApp(b, 300, 200).ID("app")
+	box := app.AddChild(krb.ElemTypeContainer).ID("box").Size(100, 50).
+		Property(krb.EnumProperty(krb.PropIDOverflow, krb.OverflowHidden))
+	box.AddChild(krb.ElemTypeContainer).ID("big").Size(200, 100)
+	app.AddChild(krb.ElemTypeContainer).ID("a").Pos(150, 100).Size(60, 60).Layout(krb.LayoutAbsoluteBit).
+		Property(krb.ShortProperty(krb.PropIDZIndex, 5))
+	app.AddChild(krb.ElemTypeContainer).ID("b").Pos(180, 120).Size(60, 60).Layout(krb.LayoutAbsoluteBit)
+	// 0xFFFF is z-index -1, which paints below its earlier siblings.
+	app.AddChild(krb.ElemTypeContainer).ID("neg").Pos(0, 140).Size(60, 60).Layout(krb.LayoutAbsoluteBit).
+		Property(krb.ShortProperty(krb.PropIDZIndex, 0xFFFF))
+	r, _ := prepare(t, buildDocument(t, b), "test.krb")
+
+	tests := []struct {
+		name string
+		x, y float32
+		want []string
+	}{
+		{"nested", 10, 10, []string{"big", "box", "app"}},
+		{"clipped by overflow", 150, 80, []string{"app"}},
+		{"higher z-index on top", 190, 130, []string{"a", "b", "app"}},
+		{"single sibling", 155, 105, []string{"a", "app"}},
+		{"negative z-index", 10, 150, []string{"neg", "app"}},
+		{"background only", 290, 190, []string{"app"}},
+		{"outside window", 400, 10, []string{}},
+	}
+	for _, tt := range tests {
+		if got := hitIDs(r.HitTest(tt.x, tt.y)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: HitTest(%v, %v) = %v, want %v", tt.name, tt.x, tt.y, got, tt.want)
+		}
+	}
+
+	r.GetRenderTree()[1].IsVisible = false
+	if got := hitIDs(r.HitTest(10, 10)); !reflect.DeepEqual(got, []string{"app"}) {
+		t.Errorf("HitTest under a hidden container = %v, want [app]", got)
+	}
+}
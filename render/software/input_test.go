@@ -0,0 +1,53 @@
+package software
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kryonlabs/kryon-go-runtime/krb"
+	"github.com/kryonlabs/kryon-go-runtime/render"
+)
+
+func TestInputEditingAndEvents(t *testing.T) {
+	b := krb.NewBuilder()
+	windowApp(b, 300, 100).AddChild(krb.ElemTypeInput).ID("name").Text("hello").
+		Event(krb.EventTypeChange, "changed").Event(krb.EventTypeSubmit, "submitted").
+		Property(krb.EdgeInsetsProperty(krb.PropIDPadding, 4, 4, 4, 4), krb.ByteProperty(krb.PropIDBorderWidth, 1))
+	r, roots := prepare(t, buildDocument(t, b), "test.krb")
+	r.SetClock(render.NewManualClock(time.Unix(0, 0)))
+	changes, submits := 0, 0
+	r.RegisterEventHandler("changed", func() { changes++ })
+	r.RegisterEventHandler("submitted", func() { submits++ })
+
+	in := r.GetRenderTree()[1]
+	if in.RenderW <= 0 || in.RenderH <= 0 {
+		t.Fatalf("input was not laid out: %vx%v", in.RenderW, in.RenderH)
+	}
+	r.Click(in.RenderX+10, in.RenderY+5)
+	r.PollEventsAndProcessInteractions()
+	if r.FocusedElement() != in {
+		t.Fatal("clicking the input did not focus it")
+	}
+
+	r.PressKey(render.KeyEnd, false)
+	r.TypeText(" world")
+	r.PressKey(render.KeyLeft, true)
+	r.PressKey(render.KeyLeft, true)
+	r.PressKey(render.KeyBackspace, false)
+	r.PressKey(render.KeyHome, false)
+	r.TypeText(">")
+	r.PressKey(render.KeyEnter, false)
+	r.PollEventsAndProcessInteractions()
+	if in.Text != ">hello wor" {
+		t.Errorf("edited text = %q, want \">hello wor\"", in.Text)
+	}
+	if changes != 3 || submits != 1 {
+		t.Errorf("got %d change and %d submit events, want 3 and 1", changes, submits)
+	}
+
+	// Drawing must cope with text wider than the box.
+	r.TypeText(" and a very long tail of text that overflows the input")
+	r.PollEventsAndProcessInteractions()
+	r.UpdateLayout(roots)
+	drawOnce(r, roots)
+}
@@ -0,0 +1,137 @@
+package software
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/kryonlabs/kryon-go-runtime/krb"
+	"github.com/kryonlabs/kryon-go-runtime/render"
+)
+
+// logSource is a ListDataSource of n generated log lines.
+type logSource struct{ n int }
+
+func (s logSource) Count() int { return s.n }
+
+func (s logSource) Item(i int) render.ListItem {
+	return render.ListItem{"lvl": "INFO", "msg": fmt.Sprintf("log line %d", i)}
+}
+
+func (s logSource) BindRow(i int, row *render.RenderElement) {
+	row.BgColor.R = uint8(i % 2)
+}
+
+// templateElement encodes an element header followed by its properties,
+// events and child offsets, as stored in component template data.
+func templateElement(typ krb.ElementType, id uint8, w, h uint16, layout uint8, props [][]byte, events [][2]byte, children []uint16) []byte {
+	le16 := func(v uint16) []byte { return []byte{byte(v), byte(v >> 8)} }
+	out := []byte{byte(typ), id, 0, 0, 0, 0}
+	out = append(out, le16(w)...)
+	out = append(out, le16(h)...)
+	out = append(out, layout, 0, byte(len(props)), byte(len(children)), byte(len(events)), 0, 0)
+	for _, p := range props {
+		out = append(out, p...)
+	}
+	for _, e := range events {
+		out = append(out, e[0], e[1])
+	}
+	for _, c := range children {
+		out = append(out, le16(c)...)
+	}
+	return out
+}
+
+func TestListView(t *testing.T) {
+	b := krb.NewBuilder()
+	windowApp(b, 300, 200).AddChild(krb.ElemTypeList).ID("logs").Size(250, 150).
+		CustomString(render.ListItemComponentKey, "LogRow")
+	nameIdx := b.String("LogRow")
+	rowID, lvlID, msgID, onClick := b.String("row"), b.String("lvl"), b.String("msg"), b.String("rowClick")
+	placeholder := b.String("x")
+	doc := buildDocument(t, b)
+
+	text := []byte{byte(krb.PropIDTextContent), byte(krb.ValTypeString), 1, placeholder}
+	lvl := templateElement(krb.ElemTypeText, lvlID, 50, 20, 0, [][]byte{text}, nil, nil)
+	msg := templateElement(krb.ElemTypeText, msgID, 150, 20, 0, [][]byte{text}, [][2]byte{{byte(krb.EventTypeClick), onClick}}, nil)
+	rootLen := uint16(krb.ElementHeaderSize + 2*2)
+	root := templateElement(krb.ElemTypeContainer, rowID, 0, 24, krb.LayoutDirRow, nil, nil,
+		[]uint16{rootLen, rootLen + uint16(len(lvl))})
+	doc.ComponentDefinitions = append(doc.ComponentDefinitions, krb.KrbComponentDefinition{
+		NameIndex:               nameIdx,
+		RootElementTemplateData: append(append(root, lvl...), msg...),
+	})
+
+	r := NewSoftwareRenderer()
+	roots, cfg, err := r.PrepareTree(doc, "test.krb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Init(cfg)
+	lv, err := r.BindList("logs", logSource{n: 50000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	clicked := -1
+	r.RegisterEventFunc("rowClick", func(ev *render.Event) {
+		if i, ok := lv.RowIndex(ev.Target); ok {
+			clicked = i
+		}
+	})
+	r.UpdateLayout(roots)
+
+	if got := lv.RowHeight(); got != 24 {
+		t.Fatalf("RowHeight = %v, want 24", got)
+	}
+	if got, want := lv.El.Scroll.MaxY, float32(50000*24-150); got != want {
+		t.Errorf("scroll range = %v, want %v", got, want)
+	}
+	if n := len(lv.El.Children); n == 0 || n > 10 {
+		t.Errorf("list holds %d row elements, want only the visible rows", n)
+	}
+
+	r.SetMousePosition(100, 30)
+	r.ScrollWheel(0, 3)
+	r.UpdateLayout(roots)
+	r.PollEventsAndProcessInteractions()
+	r.UpdateLayout(roots)
+	first := lv.El.Children[0]
+	if got := first.Children[1].Text; got != "log line 4" || first.RenderY != -24 {
+		t.Errorf("first row = %q at y %v, want \"log line 4\" at -24", got, first.RenderY)
+	}
+	if first.BgColor.R != 0 || lv.El.Children[1].BgColor.R != 1 {
+		t.Error("BindRow was not applied to the recycled rows")
+	}
+
+	// y 30 is 150 px into the content: row 6.
+	r.Click(100, 30)
+	r.PollEventsAndProcessInteractions()
+	if clicked != 6 {
+		t.Errorf("click resolved to row %d, want 6", clicked)
+	}
+}
+
+func TestBindListTruncatedTemplate(t *testing.T) {
+	b := krb.NewBuilder()
+	b.AddElement(krb.ElemTypeApp).AddChild(krb.ElemTypeList).ID("logs").Size(250, 150).
+		CustomString(render.ListItemComponentKey, "LogRow")
+	nameIdx := b.String("LogRow")
+	doc := buildDocument(t, b)
+	child := templateElement(krb.ElemTypeText, 0, 50, 20, 0, nil, nil, nil)
+	root := templateElement(krb.ElemTypeContainer, 0, 0, 24, krb.LayoutDirRow, nil, nil, []uint16{krb.ElementHeaderSize + 2})
+	doc.ComponentDefinitions = append(doc.ComponentDefinitions, krb.KrbComponentDefinition{
+		NameIndex:               nameIdx,
+		RootElementTemplateData: append(root, child[:5]...),
+	})
+
+	r := NewSoftwareRenderer()
+	_, cfg, err := r.PrepareTree(doc, "test.krb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Init(cfg)
+	_, err = r.BindList("logs", logSource{n: 5})
+	if err == nil || !strings.Contains(err.Error(), "truncated") {
+		t.Fatalf("BindList error = %v, want a truncated template error", err)
+	}
+}
@@ -0,0 +1,51 @@
+package software
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/kryonlabs/kryon-go-runtime/krb"
+)
+
+func TestRoundedCorners(t *testing.T) {
+	b := krb.NewBuilder()
+	app := windowApp(b, 300, 200).Layout(krb.LayoutDirColumn)
+	app.AddChild(krb.ElemTypeContainer).ID("box").Size(120, 40).Property(
+		krb.ByteProperty(krb.PropIDBorderRadius, 12), krb.ColorProperty(krb.PropIDBgColor, 255, 0, 0, 255),
+		krb.ByteProperty(krb.PropIDBorderWidth, 3), krb.ColorProperty(krb.PropIDBorderColor, 0, 0, 255, 255))
+	card := app.AddChild(krb.ElemTypeContainer).ID("card").Size(200, 120).Property(
+		krb.EdgeInsetsProperty(krb.PropIDBorderRadius, 30, 0, 0, 0),
+		krb.EnumProperty(krb.PropIDOverflow, krb.OverflowHidden))
+	card.AddChild(krb.ElemTypeContainer).ID("header").Size(200, 40).
+		Property(krb.ColorProperty(krb.PropIDBgColor, 0, 255, 0, 255))
+	r, roots := prepare(t, buildDocument(t, b), "test.krb")
+	drawOnce(r, roots)
+	img := r.Image()
+
+	black, red, green, blue := color.RGBA{0, 0, 0, 255}, color.RGBA{255, 0, 0, 255},
+		color.RGBA{0, 255, 0, 255}, color.RGBA{0, 0, 255, 255}
+	pixels := []struct {
+		name string
+		x, y int
+		want color.RGBA
+	}{
+		{"outside the box's top-left corner", 0, 0, black},
+		{"outside the box's bottom-right corner", 118, 38, black},
+		{"box border", 1, 20, blue},
+		{"box background", 60, 20, red},
+		{"header clipped by the card's corner", 2, 42, black},
+		{"header", 100, 60, green},
+	}
+	for _, p := range pixels {
+		if got := img.RGBAAt(p.x, p.y); got != p.want {
+			t.Errorf("%s: pixel (%d,%d) = %v, want %v", p.name, p.x, p.y, got, p.want)
+		}
+	}
+
+	if hits := hitIDs(r.HitTest(1, 1)); len(hits) != 1 {
+		t.Errorf("HitTest in the box's cut-off corner = %v, want only the app", hits)
+	}
+	if hits := hitIDs(r.HitTest(2, 42)); len(hits) != 1 {
+		t.Errorf("HitTest in the card's cut-off corner = %v, want only the app", hits)
+	}
+}
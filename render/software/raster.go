@@ -0,0 +1,198 @@
+// render/software/raster.go
+package software
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+
+	xdraw "golang.org/x/image/draw"
+
+	"github.com/kryonlabs/kryon-go-runtime/render"
+)
+
+// KRB colors are straight (non-premultiplied) RGBA, while image.RGBA stores
+// premultiplied values, so every source color goes through color.NRGBA.
+
+func (r *SoftwareRenderer) fillRect(x, y, w, h int, c color.RGBA) {
+	if c.A == 0 || w <= 0 || h <= 0 {
+		return
+	}
+	rect := image.Rect(x, y, x+w, y+h).Intersect(r.clip)
+	if rect.Empty() {
+		return
+	}
+	op := draw.Over
+	if c.A == 255 {
+		op = draw.Src
+	}
+	draw.Draw(r.canvas, rect, image.NewUniform(color.NRGBA(c)), image.Point{}, op)
+}
+
+func (r *SoftwareRenderer) drawBorders(x, y, w, h, top, right, bottom, left int, c color.RGBA) {
+	if c.A == 0 {
+		return
+	}
+	if top > 0 {
+		r.fillRect(x, y, w, top, c)
+	}
+	if bottom > 0 {
+		r.fillRect(x, y+h-bottom, w, bottom, c)
+	}
+	sideY := y + top
+	sideH := h - top - bottom
+	if sideH > 0 {
+		if left > 0 {
+			r.fillRect(x, sideY, left, sideH, c)
+		}
+		if right > 0 {
+			r.fillRect(x+w-right, sideY, right, sideH, c)
+		}
+	}
+}
+
+// coverageSamples is the number of samples per pixel, along each axis, that
+// antialias rounded corners.
+const coverageSamples = 4
+
+// roundedCoverage returns the fraction of the pixel at (px, py) inside box with
+// its corners rounded by radii.
+func roundedCoverage(box render.Rect, radii render.CornerRadii, px, py int) float32 {
+	x, y := float32(px), float32(py)
+	first, last := float32(0.5/coverageSamples), float32(1-0.5/coverageSamples)
+	// The shape is convex, so a pixel whose outermost samples are all inside is covered.
+	if render.RoundedContains(box, radii, x+first, y+first) && render.RoundedContains(box, radii, x+last, y+first) &&
+		render.RoundedContains(box, radii, x+first, y+last) && render.RoundedContains(box, radii, x+last, y+last) {
+		return 1
+	}
+	inside := 0
+	for i := 0; i < coverageSamples; i++ {
+		for j := 0; j < coverageSamples; j++ {
+			if render.RoundedContains(box, radii, x+(float32(i)+0.5)/coverageSamples, y+(float32(j)+0.5)/coverageSamples) {
+				inside++
+			}
+		}
+	}
+	return float32(inside) / (coverageSamples * coverageSamples)
+}
+
+// fillShape blends c over the pixels of bounds in proportion to their coverage.
+func (r *SoftwareRenderer) fillShape(bounds render.Rect, c color.RGBA, coverage func(px, py int) float32) {
+	if c.A == 0 {
+		return
+	}
+	rect := image.Rect(int(bounds.X), int(bounds.Y), int(math.Ceil(float64(bounds.X+bounds.W))), int(math.Ceil(float64(bounds.Y+bounds.H)))).Intersect(r.clip)
+	if rect.Empty() {
+		return
+	}
+	mask := image.NewAlpha(rect)
+	for py := rect.Min.Y; py < rect.Max.Y; py++ {
+		for px := rect.Min.X; px < rect.Max.X; px++ {
+			if a := coverage(px, py); a > 0 {
+				mask.SetAlpha(px, py, color.Alpha{A: uint8(min(a, 1)*255 + 0.5)})
+			}
+		}
+	}
+	draw.DrawMask(r.canvas, rect, image.NewUniform(color.NRGBA(c)), image.Point{}, mask, rect.Min, draw.Over)
+}
+
+// fillRoundedRect fills box with its corners rounded by radii.
+func (r *SoftwareRenderer) fillRoundedRect(box render.Rect, radii render.CornerRadii, c color.RGBA) {
+	if radii.IsZero() {
+		r.fillRect(int(box.X), int(box.Y), int(box.W), int(box.H), c)
+		return
+	}
+	r.fillShape(box, c, func(px, py int) float32 {
+		return roundedCoverage(box, radii, px, py)
+	})
+}
+
+// drawRoundedBorders fills the ring between outer and inner, with their
+// corners rounded by outerRadii and innerRadii; inner lies within outer.
+func (r *SoftwareRenderer) drawRoundedBorders(outer, inner render.Rect, outerRadii, innerRadii render.CornerRadii, c color.RGBA) {
+	r.fillShape(outer, c, func(px, py int) float32 {
+		// Both are sampled at the same points, so this is the ring's coverage.
+		return roundedCoverage(outer, outerRadii, px, py) - roundedCoverage(inner, innerRadii, px, py)
+	})
+}
+
+// clipRounded cuts off everything drawn, until the returned function is
+// called, outside box with its corners rounded by radii. The clip rectangle
+// should already lie within box; clipRounded saves the pixels of the corners
+// and blends them back over whatever was drawn outside the rounded edge.
+func (r *SoftwareRenderer) clipRounded(box render.Rect, radii render.CornerRadii) (restore func()) {
+	var saved []*image.RGBA
+	for i, corner := range radii {
+		if corner.X <= 0 || corner.Y <= 0 {
+			continue
+		}
+		x0, y0 := box.X, box.Y
+		if i == 1 || i == 2 {
+			x0 = box.X + box.W - corner.X
+		}
+		if i == 2 || i == 3 {
+			y0 = box.Y + box.H - corner.Y
+		}
+		rect := image.Rect(int(x0), int(y0), int(math.Ceil(float64(x0+corner.X))), int(math.Ceil(float64(y0+corner.Y)))).Intersect(r.clip)
+		if rect.Empty() {
+			continue
+		}
+		pixels := image.NewRGBA(rect)
+		draw.Draw(pixels, rect, r.canvas, rect.Min, draw.Src)
+		saved = append(saved, pixels)
+	}
+	return func() {
+		for _, pixels := range saved {
+			bounds := pixels.Bounds()
+			for py := bounds.Min.Y; py < bounds.Max.Y; py++ {
+				for px := bounds.Min.X; px < bounds.Max.X; px++ {
+					a := roundedCoverage(box, radii, px, py)
+					if a >= 1 {
+						continue
+					}
+					drawn := r.canvas.Pix[r.canvas.PixOffset(px, py):][:4]
+					before := pixels.Pix[pixels.PixOffset(px, py):][:4]
+					for k := range drawn {
+						drawn[k] = uint8(float32(drawn[k])*a + float32(before[k])*(1-a) + 0.5)
+					}
+				}
+			}
+		}
+	}
+}
+
+// drawImage scales src into the destination rectangle with bilinear filtering.
+// opacity (0.0-1.0) fades the image like the raylib backend's texture tint.
+func (r *SoftwareRenderer) drawImage(src image.Image, x, y, w, h int, opacity float32) {
+	if src == nil || w <= 0 || h <= 0 || opacity <= 0 {
+		return
+	}
+	dst, ok := r.canvas.SubImage(r.clip).(*image.RGBA)
+	if !ok || dst.Bounds().Empty() {
+		return
+	}
+	var opts *xdraw.Options
+	if opacity < 1 {
+		opts = &xdraw.Options{DstMask: image.NewUniform(color.Alpha{A: uint8(opacity*255 + 0.5)})}
+	}
+	xdraw.ApproxBiLinear.Scale(dst, image.Rect(x, y, x+w, y+h), src, src.Bounds(), xdraw.Over, opts)
+}
+
+func clampOpposingBorders(borderA, borderB, totalSize int) (int, int) {
+	if totalSize <= 0 {
+		return 0, 0
+	}
+	if borderA < 0 {
+		borderA = 0
+	}
+	if borderB < 0 {
+		borderB = 0
+	}
+	if borderA+borderB > totalSize {
+		sum := float32(borderA + borderB)
+		borderA = int(float32(borderA) / sum * float32(totalSize))
+		borderB = totalSize - borderA
+	}
+	return borderA, borderB
+}
@@ -0,0 +1,114 @@
+// render/software/resources.go
+package software
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	_ "image/gif" // Register decoders for image.Decode
+	_ "image/jpeg"
+	_ "image/png"
+	"log"
+
+	"github.com/kryonlabs/kryon-go-runtime/krb"
+	"github.com/kryonlabs/kryon-go-runtime/render"
+)
+
+// LoadAllTextures decodes every image resource referenced by an element and
+// parses every font resource an element's text uses. PNG, JPEG and GIF images
+// and TTF/OTF fonts are supported, both as external files and inline data.
+func (r *SoftwareRenderer) LoadAllTextures() error {
+	if r.docRef == nil {
+		return fmt.Errorf("cannot load textures, KRB document reference is nil")
+	}
+
+	errCount := 0
+	for i := range r.elements {
+		el := &r.elements[i]
+		if usesFontResource(el) {
+			if _, err := r.loadFont(el.FontResourceIndex); err != nil {
+				log.Printf("Error LoadAllTextures: Font for elem %s (GlobalIdx %d): %v", el.SourceElementName, el.OriginalIndex, err)
+				errCount++
+			}
+		}
+
+		needsTexture := (el.Header.Type == krb.ElemTypeImage || el.Header.Type == krb.ElemTypeButton) &&
+			el.ResourceIndex != render.InvalidResourceIndex
+		if !needsTexture {
+			continue
+		}
+
+		img, err := r.loadImage(el.ResourceIndex)
+		if err != nil {
+			log.Printf("Error LoadAllTextures: Elem %s (GlobalIdx %d): %v", el.SourceElementName, el.OriginalIndex, err)
+			errCount++
+			el.TextureLoaded = false
+			continue
+		}
+		bounds := img.Bounds()
+		el.Texture = img
+		el.TextureWidth, el.TextureHeight = int32(bounds.Dx()), int32(bounds.Dy())
+		el.TextureLoaded = true
+	}
+
+	if errCount > 0 {
+		return fmt.Errorf("encountered %d errors during texture and font loading", errCount)
+	}
+	return nil
+}
+
+// loadImage returns the decoded image for a resource, decoding it on first use.
+func (r *SoftwareRenderer) loadImage(resIndex uint8) (image.Image, error) {
+	if img, ok := r.images[resIndex]; ok {
+		return img, nil
+	}
+	data, err := render.ResourceData(r.docRef, r.krbFileDir, resIndex)
+	if err != nil {
+		return nil, err
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image resource %d: %w", resIndex, err)
+	}
+	r.images[resIndex] = img
+	return img, nil
+}
+
+// usesFontResource reports whether el draws text with a font resource.
+func usesFontResource(el *render.RenderElement) bool {
+	isTextBearing := el.Header.Type == krb.ElemTypeText || el.Header.Type == krb.ElemTypeButton || el.Header.Type == krb.ElemTypeInput
+	return isTextBearing && el.FontResourceIndex != render.InvalidResourceIndex
+}
+
+// loadFont returns the font for a ResTypeFont resource, parsing it on first use.
+func (r *SoftwareRenderer) loadFont(resIndex uint8) (*render.TTFFontProvider, error) {
+	if f, ok := r.fontResources[resIndex]; ok {
+		return f, nil
+	}
+	data, err := render.ResourceData(r.docRef, r.krbFileDir, resIndex)
+	if err != nil {
+		return nil, err
+	}
+	f, err := render.NewTTFFontProvider(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse font resource %d: %w", resIndex, err)
+	}
+	r.fontResources[resIndex] = f
+	return f, nil
+}
+
+// fontFor returns the font el's text is measured and drawn with: its font
+// resource if that loaded, the default font otherwise.
+func (r *SoftwareRenderer) fontFor(el *render.RenderElement) *render.TTFFontProvider {
+	if el != nil && el.FontResourceIndex != render.InvalidResourceIndex {
+		if f, ok := r.fontResources[el.FontResourceIndex]; ok {
+			return f
+		}
+	}
+	return r.fonts
+}
+
+// FontFor implements render.FontSet.
+func (r *SoftwareRenderer) FontFor(el *render.RenderElement) render.FontProvider {
+	return r.fontFor(el)
+}
@@ -0,0 +1,55 @@
+package software
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/image/font/gofont/gomono"
+
+	"github.com/kryonlabs/kryon-go-runtime/krb"
+	"github.com/kryonlabs/kryon-go-runtime/render"
+)
+
+func TestFontResourcesSelectFontPerElement(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "fonts"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "fonts", "GoMono.ttf"), gomono.TTF, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	b := krb.NewBuilder()
+	mono := b.AddExternalResource(krb.ResTypeFont, "fonts/GoMono.ttf")
+	app := windowApp(b, 300, 100)
+	app.AddChild(krb.ElemTypeText).ID("default").Text("iiiii")
+	app.AddChild(krb.ElemTypeText).ID("byName").Text("iiiii").Property(b.StringProperty(krb.PropIDFontFamily, "gomono"))
+	app.AddChild(krb.ElemTypeText).ID("byIndex").Text("iiiii").Property(krb.ResourceProperty(krb.PropIDFontFamily, mono))
+	app.AddChild(krb.ElemTypeText).ID("custom").Text("iiiii").CustomString(render.FontFamilyKey, "GoMono")
+	r, _ := prepare(t, buildDocument(t, b), filepath.Join(dir, "app.krb"))
+
+	monoFont, err := render.NewTTFFontProvider(gomono.TTF)
+	if err != nil {
+		t.Fatal(err)
+	}
+	monoWidth := monoFont.MeasureText("iiiii", render.BaseFontSize)
+	for _, el := range r.GetRenderTree()[1:] {
+		id := el.SourceElementName
+		if id == "default" {
+			if el.FontResourceIndex != render.InvalidResourceIndex {
+				t.Errorf("%s: FontResourceIndex = %d, want none", id, el.FontResourceIndex)
+			}
+			if el.RenderW >= monoWidth {
+				t.Errorf("%s: proportional text is %v wide, not narrower than monospaced %v", id, el.RenderW, monoWidth)
+			}
+			continue
+		}
+		if el.FontResourceIndex != mono {
+			t.Errorf("%s: FontResourceIndex = %d, want %d", id, el.FontResourceIndex, mono)
+		}
+		if el.RenderW != monoWidth {
+			t.Errorf("%s: text is %v wide, want the monospaced %v", id, el.RenderW, monoWidth)
+		}
+	}
+}
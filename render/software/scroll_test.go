@@ -0,0 +1,103 @@
+package software
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/kryonlabs/kryon-go-runtime/krb"
+	"github.com/kryonlabs/kryon-go-runtime/render"
+)
+
+func TestScrollable(t *testing.T) {
+	b := krb.NewBuilder()
+	sc := windowApp(b, 300, 200).AddChild(krb.ElemTypeScrollable).ID("sc").Size(200, 120).Layout(krb.LayoutDirColumn)
+	for i := 0; i < 10; i++ {
+		sc.AddChild(krb.ElemTypeButton).ID(fmt.Sprintf("b%d", i)).Text(fmt.Sprintf("row %d", i)).Size(180, 30).
+			Event(krb.EventTypeClick, "click")
+		if i == 5 {
+			inner := sc.AddChild(krb.ElemTypeContainer).ID("inner").Size(150, 40).Layout(krb.LayoutDirRow).
+				Property(krb.EnumProperty(krb.PropIDOverflow, krb.OverflowScroll))
+			for j := 0; j < 5; j++ {
+				inner.AddChild(krb.ElemTypeText).Text(fmt.Sprintf("c%d", j)).Size(60, 30)
+			}
+		}
+	}
+	r, roots := prepare(t, buildDocument(t, b), "test.krb")
+	var clicks []string
+	r.RegisterEventFunc("click", func(ev *render.Event) { clicks = append(clicks, render.ElementID(ev.Target)) })
+	var scEl, innerEl *render.RenderElement
+	for _, el := range r.GetRenderTree() {
+		switch render.ElementID(el) {
+		case "sc":
+			scEl = el
+		case "inner":
+			innerEl = el
+		}
+	}
+	frame := func() {
+		r.UpdateLayout(roots)
+		r.PollEventsAndProcessInteractions()
+	}
+	expectScroll := func(step string, want float32, topID string) {
+		t.Helper()
+		if math.Abs(float64(scEl.Scroll.Y-want)) > 0.01 {
+			t.Errorf("%s: scroll offset %v, want %v", step, scEl.Scroll.Y, want)
+		}
+		if hits := r.HitTest(50, 50); len(hits) == 0 || render.ElementID(hits[0]) != topID {
+			t.Errorf("%s: HitTest(50, 50) = %v, want %s on top", step, hitIDs(hits), topID)
+		}
+	}
+
+	// 9 rows of 30 plus the 40 high inner region and one more row, in a
+	// 120 high viewport.
+	if scEl.Scroll.MaxY != 220 || innerEl.Scroll.MaxX != 150 {
+		t.Fatalf("scroll ranges = %v and %v, want 220 and 150", scEl.Scroll.MaxY, innerEl.Scroll.MaxX)
+	}
+	if got, want := scEl.Scroll.ThumbV, (render.Rect{X: 192, Y: 0, W: 8, H: 120 * 120 / 340.0}); got != want {
+		t.Errorf("vertical thumb = %+v, want %+v", got, want)
+	}
+	expectScroll("initial", 0, "b1")
+
+	r.SetMousePosition(50, 50)
+	r.ScrollWheel(0, 1)
+	frame()
+	expectScroll("wheel", render.WheelScrollStep, "b3")
+	r.ScrollWheel(0, 100)
+	frame()
+	expectScroll("wheel past the end", 220, "b7")
+	r.ScrollWheel(0, -100)
+	frame()
+	expectScroll("wheel back", 0, "b1")
+
+	// Dragging the content scrolls it and swallows the click.
+	r.MouseDown(50, 100)
+	frame()
+	r.SetMousePosition(50, 60)
+	frame()
+	r.MouseUp(50, 60)
+	frame()
+	expectScroll("content drag", 40, "b3")
+	if len(clicks) != 0 {
+		t.Errorf("content drag fired clicks on %v", clicks)
+	}
+
+	// Dragging the thumb scrolls by the content to track ratio.
+	thumb := scEl.Scroll.ThumbV
+	r.MouseDown(thumb.X+2, thumb.Y+2)
+	frame()
+	r.SetMousePosition(thumb.X+2, thumb.Y+30)
+	frame()
+	r.MouseUp(thumb.X+2, thumb.Y+30)
+	frame()
+	expectScroll("thumb drag", 40+28*220/(120-thumb.H), "b5")
+
+	// A horizontal wheel over the nested region scrolls only that region.
+	r.SetMousePosition(innerEl.RenderX+10, innerEl.RenderY+10)
+	r.ScrollWheel(1, 0)
+	frame()
+	if innerEl.Scroll.X != render.WheelScrollStep || scEl.Scroll.X != 0 {
+		t.Errorf("horizontal wheel scrolled inner to %v and outer to %v, want %v and 0",
+			innerEl.Scroll.X, scEl.Scroll.X, render.WheelScrollStep)
+	}
+}
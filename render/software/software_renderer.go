@@ -0,0 +1,650 @@
+// render/software/software_renderer.go
+package software
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"log"
+	"math"
+	"os"
+
+	"github.com/kryonlabs/kryon-go-runtime/krb"
+	"github.com/kryonlabs/kryon-go-runtime/render"
+	"github.com/kryonlabs/kryon-go-runtime/render/layout"
+)
+
+// SoftwareRenderer is a headless render.Renderer that rasterizes frames into an
+// image.RGBA. It needs no GPU or display, so it can render KRB files on CI
+// machines, produce PNG snapshots and drive interaction tests through the
+// simulated pointer (SetMousePosition, Click).
+type SoftwareRenderer struct {
+	config          render.WindowConfig
+	tree            *render.Tree
+	elements        []render.RenderElement // Stores all elements, including expanded ones
+	roots           []*render.RenderElement
+	krbFileDir      string
+	scaleFactor     float32
+	docRef          *krb.Document
+	eventHandlerMap map[string]render.EventFunc
+	customHandlers  map[string]render.CustomComponentHandler
+
+	canvas *image.RGBA
+	clip   image.Rectangle // Drawing is restricted to this rectangle (the scissor)
+	images map[uint8]image.Image
+	fonts  *render.TTFFontProvider // Default font for text without a font resource
+
+	fontResources map[uint8]*render.TTFFontProvider // Parsed ResTypeFont resources by resource index
+
+	animator              *render.Animator
+	loadAnimationsPending bool
+	events                *render.EventDispatcher
+
+	mouseX, mouseY  float32
+	modifiers       render.Modifiers
+	mousePressed    bool    // The simulated button went down since the last poll
+	mouseReleased   bool    // The simulated button went up since the last poll
+	wheelX, wheelY  float32 // Wheel notches turned since the last poll
+	pendingKeyboard []keyboardInput
+	closeRequested  bool
+}
+
+// keyboardInput is a queued TypeText (text != "") or PressKey call.
+type keyboardInput struct {
+	text  string
+	key   render.Key
+	shift bool
+}
+
+func NewSoftwareRenderer() *SoftwareRenderer {
+	r := &SoftwareRenderer{
+		scaleFactor:     1.0,
+		eventHandlerMap: make(map[string]render.EventFunc),
+		customHandlers:  make(map[string]render.CustomComponentHandler),
+		images:          make(map[uint8]image.Image),
+		fonts:           render.NewBundledFontProvider(),
+		fontResources:   make(map[uint8]*render.TTFFontProvider),
+
+		animator: render.NewAnimator(render.SystemClock{}),
+	}
+	r.events = render.NewEventDispatcher(r, r.animator, r.eventHandlerMap, r.customHandlers)
+	return r
+}
+
+// SetClock replaces the time source driving KRB animations (see render.ManualClock).
+func (r *SoftwareRenderer) SetClock(clock render.Clock) {
+	r.animator.SetClock(clock)
+}
+
+// SetFontProvider replaces the default font, used to measure and draw text of
+// elements without a font resource. The renderer starts with
+// render.NewBundledFontProvider.
+func (r *SoftwareRenderer) SetFontProvider(fonts *render.TTFFontProvider) {
+	if fonts == nil {
+		log.Println("WARN SetFontProvider: Ignoring nil font provider.")
+		return
+	}
+	r.fonts = fonts
+}
+
+// FontProvider returns the default font. See FontFor for the font of an element.
+func (r *SoftwareRenderer) FontProvider() render.FontProvider {
+	return r.fonts
+}
+
+// Animator exposes the animation runtime, e.g. to fire triggers from custom handlers.
+func (r *SoftwareRenderer) Animator() *render.Animator {
+	return r.animator
+}
+
+// Events exposes the event dispatcher, e.g. to fire EventTypeCustom or to
+// change the long-press threshold.
+func (r *SoftwareRenderer) Events() *render.EventDispatcher {
+	return r.events
+}
+
+// Image returns the canvas holding the most recently drawn frame. The image is
+// reused between frames; copy it to keep a frame.
+func (r *SoftwareRenderer) Image() *image.RGBA {
+	return r.canvas
+}
+
+// SavePNG writes the current frame to path as a PNG file.
+func (r *SoftwareRenderer) SavePNG(path string) error {
+	if r.canvas == nil {
+		return fmt.Errorf("SavePNG: renderer is not initialized")
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("SavePNG: %w", err)
+	}
+	if err := png.Encode(f, r.canvas); err != nil {
+		f.Close()
+		return fmt.Errorf("SavePNG: failed to encode '%s': %w", path, err)
+	}
+	return f.Close()
+}
+
+// SetMousePosition moves the simulated pointer. Hover state is updated by the
+// next PollEventsAndProcessInteractions call.
+func (r *SoftwareRenderer) SetMousePosition(x, y float32) {
+	r.mouseX, r.mouseY = x, y
+}
+
+// SetModifiers sets the simulated modifier keys held from the next
+// PollEventsAndProcessInteractions call on.
+func (r *SoftwareRenderer) SetModifiers(mods render.Modifiers) {
+	r.modifiers = mods
+}
+
+// MouseDown moves the simulated pointer to (x, y) and presses the left button.
+// The press is dispatched by the next PollEventsAndProcessInteractions call.
+func (r *SoftwareRenderer) MouseDown(x, y float32) {
+	r.SetMousePosition(x, y)
+	r.mousePressed = true
+}
+
+// MouseUp moves the simulated pointer to (x, y) and releases the left button.
+// The release is dispatched by the next PollEventsAndProcessInteractions call.
+func (r *SoftwareRenderer) MouseUp(x, y float32) {
+	r.SetMousePosition(x, y)
+	r.mouseReleased = true
+}
+
+// ScrollWheel turns the simulated mouse wheel by dx, dy notches at the current
+// pointer position; positive values scroll the content right and down. The
+// scroll is applied by the next PollEventsAndProcessInteractions call.
+func (r *SoftwareRenderer) ScrollWheel(dx, dy float32) {
+	r.wheelX += dx
+	r.wheelY += dy
+}
+
+// Click presses and releases the left button at (x, y). Both are dispatched by
+// the next PollEventsAndProcessInteractions call.
+func (r *SoftwareRenderer) Click(x, y float32) {
+	r.MouseDown(x, y)
+	r.mouseReleased = true
+}
+
+// TypeText queues text as typed on a keyboard. The next
+// PollEventsAndProcessInteractions call inserts it into the focused Input.
+func (r *SoftwareRenderer) TypeText(text string) {
+	if text != "" {
+		r.pendingKeyboard = append(r.pendingKeyboard, keyboardInput{text: text})
+	}
+}
+
+// PressKey queues a press of an editing or navigation key, with or without
+// shift held. Like TypeText, it is applied by the next PollEventsAndProcessInteractions call.
+func (r *SoftwareRenderer) PressKey(key render.Key, shift bool) {
+	r.pendingKeyboard = append(r.pendingKeyboard, keyboardInput{key: key, shift: shift})
+}
+
+// Close makes ShouldClose report true, ending a main loop driven by this renderer.
+func (r *SoftwareRenderer) Close() {
+	r.closeRequested = true
+}
+
+func (r *SoftwareRenderer) Init(config render.WindowConfig) error {
+	if config.Width <= 0 || config.Height <= 0 {
+		return fmt.Errorf("SoftwareRenderer Init: invalid canvas size %dx%d", config.Width, config.Height)
+	}
+	r.config = config
+	r.scaleFactor = float32(math.Max(1.0, float64(config.ScaleFactor)))
+	r.canvas = image.NewRGBA(image.Rect(0, 0, config.Width, config.Height))
+	r.clip = r.canvas.Bounds()
+	r.closeRequested = false
+
+	log.Printf("SoftwareRenderer Init: Canvas %dx%d. UI Scale: %.2f.", config.Width, config.Height, r.scaleFactor)
+	return nil
+}
+
+func (r *SoftwareRenderer) PrepareTree(
+	doc *krb.Document,
+	krbFilePath string,
+) ([]*render.RenderElement, render.WindowConfig, error) {
+
+	tree, err := render.BuildTree(doc, krbFilePath)
+	if err != nil {
+		return nil, r.config, fmt.Errorf("PrepareTree: %w", err)
+	}
+	r.tree = tree
+	r.docRef = tree.Doc
+	r.elements = tree.Elements
+	r.roots = tree.Roots
+	r.config = tree.Config
+	r.scaleFactor = tree.ScaleFactor
+	r.krbFileDir = tree.ResourceDir
+
+	r.animator.Reset()
+	r.events.Reset()
+	r.pendingKeyboard = nil
+	r.loadAnimationsPending = true
+
+	return r.roots, r.config, nil
+}
+
+func (r *SoftwareRenderer) GetRenderTree() []*render.RenderElement {
+	if len(r.elements) == 0 {
+		return nil
+	}
+	pointers := make([]*render.RenderElement, len(r.elements))
+	for i := range r.elements {
+		pointers[i] = &r.elements[i]
+	}
+	return pointers
+}
+
+func (r *SoftwareRenderer) Cleanup() {
+	r.images = make(map[uint8]image.Image)
+	r.fonts.Close()
+	for resIndex, f := range r.fontResources {
+		f.Close()
+		delete(r.fontResources, resIndex)
+	}
+}
+
+func (r *SoftwareRenderer) ShouldClose() bool {
+	return r.closeRequested
+}
+
+func (r *SoftwareRenderer) BeginFrame() {
+	if r.canvas == nil {
+		return
+	}
+	r.clip = r.canvas.Bounds()
+	bg := r.config.DefaultBg
+	bg.A = 255 // The window background is always opaque
+	r.fillRect(0, 0, r.config.Width, r.config.Height, bg)
+}
+
+func (r *SoftwareRenderer) EndFrame() {}
+
+// UpdateLayout calculates all element positions and sizes for the canvas size.
+func (r *SoftwareRenderer) UpdateLayout(roots []*render.RenderElement) {
+	r.roots = roots
+	r.events.SetRoots(roots)
+	r.layoutEngine().LayoutRoots(r.roots, float32(r.config.Width), float32(r.config.Height))
+	r.ApplyCustomComponentLayoutAdjustments()
+	r.updateAnimations()
+}
+
+// updateAnimations starts pending load animations and applies all animation
+// values on top of the freshly computed layout.
+func (r *SoftwareRenderer) updateAnimations() {
+	r.animator.SetScale(r.scaleFactor)
+	r.animator.SetRelayout(r.layoutEngine().LayoutContent)
+	if r.loadAnimationsPending {
+		r.loadAnimationsPending = false
+		for i := range r.elements {
+			if render.HasTrigger(&r.elements[i], krb.AnimTriggerLoad) {
+				r.animator.Fire(&r.elements[i], krb.AnimTriggerLoad)
+			}
+		}
+	}
+	r.animator.Apply()
+}
+
+func (r *SoftwareRenderer) ApplyCustomComponentLayoutAdjustments() {
+	if r.docRef == nil || len(r.customHandlers) == 0 || len(r.elements) == 0 {
+		return
+	}
+	for i := range r.elements {
+		el := &r.elements[i]
+		componentIdentifier, found := render.GetCustomPropertyValue(el, render.ComponentNameKey, r.docRef)
+		if found && componentIdentifier != "" {
+			if handler, handlerFound := r.customHandlers[componentIdentifier]; handlerFound {
+				if err := handler.HandleLayoutAdjustment(el, r.docRef, r); err != nil {
+					log.Printf("ERROR ApplyCustomComponentLayoutAdjustments: Custom layout handler for '%s' [%s] failed: %v",
+						componentIdentifier, el.SourceElementName, err)
+				}
+			}
+		}
+	}
+}
+
+func (r *SoftwareRenderer) PerformLayoutChildrenOfElement(
+	parent *render.RenderElement,
+	parentClientOriginX, parentClientOriginY,
+	availableClientWidth, availableClientHeight float32,
+) {
+	r.layoutEngine().PerformLayoutChildren(parent, parentClientOriginX, parentClientOriginY, availableClientWidth, availableClientHeight)
+}
+
+// layoutEngine returns the shared layout engine, measuring text with the
+// same fonts drawText uses.
+func (r *SoftwareRenderer) layoutEngine() *layout.Engine {
+	return layout.New(r.docRef, r.scaleFactor, r)
+}
+
+// ReResolveElementVisuals re-applies el's current style, direct properties and
+// inherited values so runtime style changes become visible.
+func (r *SoftwareRenderer) ReResolveElementVisuals(el *render.RenderElement) {
+	if r.tree == nil {
+		log.Printf("WARN ReResolveElementVisuals: No tree has been prepared.")
+		return
+	}
+	r.tree.ReResolveElementVisuals(el)
+}
+
+// RegisterEventHandler registers a handler that takes no event details. See
+// RegisterEventFunc for handlers that need the target element or input state.
+func (r *SoftwareRenderer) RegisterEventHandler(name string, handler func()) {
+	if handler == nil {
+		log.Printf("WARN RegisterEventHandler: Attempted to register nil handler for name '%s'.", name)
+		return
+	}
+	r.RegisterEventFunc(name, func(*render.Event) { handler() })
+}
+
+// RegisterEventFunc registers handler under name for every KRB event whose
+// callback is name. The handler gets the event's target, type and input state.
+func (r *SoftwareRenderer) RegisterEventFunc(name string, handler render.EventFunc) {
+	if name == "" {
+		log.Println("WARN RegisterEventFunc: Attempted to register handler with empty name.")
+		return
+	}
+	if handler == nil {
+		log.Printf("WARN RegisterEventFunc: Attempted to register nil handler for name '%s'.", name)
+		return
+	}
+	if _, exists := r.eventHandlerMap[name]; exists {
+		log.Printf("INFO RegisterEventFunc: Overwriting existing handler for event name '%s'", name)
+	}
+	r.eventHandlerMap[name] = handler
+}
+
+// BindList shows the rows of source in the List element with KRY id
+// elementID, see render.Tree.BindList.
+func (r *SoftwareRenderer) BindList(elementID string, source render.ListDataSource) (*render.ListView, error) {
+	if r.tree == nil {
+		return nil, fmt.Errorf("BindList: no tree has been prepared")
+	}
+	el := r.tree.ElementByID(elementID)
+	if el == nil {
+		return nil, fmt.Errorf("BindList: no element with id '%s'", elementID)
+	}
+	return r.tree.BindList(el, source)
+}
+
+func (r *SoftwareRenderer) RegisterCustomComponent(identifier string, handler render.CustomComponentHandler) error {
+	if identifier == "" {
+		return fmt.Errorf("RegisterCustomComponent: identifier cannot be empty")
+	}
+	if handler == nil {
+		return fmt.Errorf("RegisterCustomComponent: handler cannot be nil for identifier '%s'", identifier)
+	}
+	if _, exists := r.customHandlers[identifier]; exists {
+		log.Printf("INFO RegisterCustomComponent: Overwriting existing custom component handler for identifier '%s'", identifier)
+	}
+	r.customHandlers[identifier] = handler
+	return nil
+}
+
+func (r *SoftwareRenderer) GetKrbFileDir() string { return r.krbFileDir }
+
+// PollEventsAndProcessInteractions applies the simulated pointer and keyboard
+// state through the event dispatcher, exactly like the raylib backend does
+// with real input: hover follows the pointer, queued button presses and
+// releases are dispatched to the element under it and queued keyboard input
+// edits the focused Input.
+func (r *SoftwareRenderer) PollEventsAndProcessInteractions() {
+	input := render.PointerInput{
+		X:         r.mouseX,
+		Y:         r.mouseY,
+		Pressed:   r.mousePressed,
+		Released:  r.mouseReleased,
+		Modifiers: r.modifiers,
+		WheelX:    r.wheelX * render.WheelScrollStep * r.scaleFactor,
+		WheelY:    r.wheelY * render.WheelScrollStep * r.scaleFactor,
+	}
+	r.mousePressed, r.mouseReleased = false, false
+	r.wheelX, r.wheelY = 0, 0
+
+	hits := r.HitTest(r.mouseX, r.mouseY)
+	r.events.ProcessPointer(r.elements, hits, input)
+	var target *render.RenderElement
+	if len(hits) > 0 {
+		target = hits[0]
+	}
+	if textInput := r.events.TextInput(); input.Pressed && textInput != nil && textInput.El == target {
+		textInput.SetCaretFromPoint(r.mouseX, r.fontFor(target), r.fontSize(target), r.scaleFactor, false, r.animator.Now())
+	}
+
+	pending := r.pendingKeyboard
+	r.pendingKeyboard = nil
+	for _, keyboard := range pending {
+		if keyboard.text != "" {
+			r.events.ProcessText(keyboard.text)
+		} else {
+			r.events.ProcessKey(keyboard.key, keyboard.shift)
+		}
+	}
+}
+
+// FocusedElement returns the element with keyboard focus, or nil.
+func (r *SoftwareRenderer) FocusedElement() *render.RenderElement {
+	return r.events.Focused()
+}
+
+// HitTest returns the elements at canvas coordinates (x, y), topmost first, in
+// the order they were drawn by the last DrawFrame.
+func (r *SoftwareRenderer) HitTest(x, y float32) []*render.RenderElement {
+	return render.HitTest(r.roots, x, y, r.scaleFactor)
+}
+
+// DrawFrame rasterizes the UI using the layout computed by UpdateLayout.
+func (r *SoftwareRenderer) DrawFrame(roots []*render.RenderElement) {
+	if r.canvas == nil {
+		log.Println("Error DrawFrame: SoftwareRenderer is not initialized.")
+		return
+	}
+	r.roots = roots
+	for _, root := range r.roots {
+		if root != nil {
+			r.renderElementRecursiveWithCustomDraw(root, r.scaleFactor)
+		}
+	}
+	if el := r.events.FocusRingTarget(); el != nil {
+		r.drawFocusRing(el, r.scaleFactor)
+	}
+}
+
+// drawFocusRing outlines el with render.FocusRingColor, on top of everything
+// drawn before.
+func (r *SoftwareRenderer) drawFocusRing(el *render.RenderElement, scale float32) {
+	w := int(scaledI32(render.FocusRingWidth, scale))
+	x, y := int(el.RenderX), int(el.RenderY)
+	width, height := int(el.RenderW), int(el.RenderH)
+	top, bottom := clampOpposingBorders(w, w, height)
+	left, right := clampOpposingBorders(w, w, width)
+	if radii := render.BorderRadii(el, scale); !radii.IsZero() {
+		box := render.Rect{X: float32(x), Y: float32(y), W: float32(width), H: float32(height)}
+		inner := render.Rect{X: box.X + float32(left), Y: box.Y + float32(top), W: box.W - float32(left+right), H: box.H - float32(top+bottom)}
+		r.drawRoundedBorders(box, inner, radii, radii.Inset(float32(top), float32(right), float32(bottom), float32(left)), render.FocusRingColor)
+		return
+	}
+	r.drawBorders(x, y, width, height, top, right, bottom, left, render.FocusRingColor)
+}
+
+func (r *SoftwareRenderer) renderElementRecursiveWithCustomDraw(el *render.RenderElement, scale float32) {
+	if el == nil || !el.IsVisible {
+		return
+	}
+
+	skipStandardDraw := false
+	if componentIdentifier, foundName := render.GetCustomPropertyValue(el, render.ComponentNameKey, r.docRef); foundName && componentIdentifier != "" {
+		if handler, foundHandler := r.customHandlers[componentIdentifier]; foundHandler {
+			if drawer, ok := handler.(render.CustomDrawer); ok {
+				var drawErr error
+				skipStandardDraw, drawErr = drawer.Draw(el, scale, r)
+				if drawErr != nil {
+					log.Printf("ERROR renderElementRecursiveWithCustomDraw: Custom Draw handler for component '%s' [%s] failed: %v",
+						componentIdentifier, el.SourceElementName, drawErr)
+				}
+			}
+		}
+	}
+
+	if !skipStandardDraw {
+		r.renderStandardElement(el, scale)
+	} else {
+		r.drawChildren(el, scale)
+	}
+}
+
+func (r *SoftwareRenderer) renderStandardElement(el *render.RenderElement, scale float32) {
+	renderXf, renderYf, renderWf, renderHf := el.RenderX, el.RenderY, el.RenderW, el.RenderH
+
+	if renderWf <= 0 || renderHf <= 0 {
+		r.drawChildren(el, scale)
+		return
+	}
+
+	renderX, renderY := int(renderXf), int(renderYf)
+	renderW, renderH := int(renderWf), int(renderHf)
+
+	opacity := render.EffectiveOpacity(el)
+	effectiveBgColor := render.ApplyOpacity(el.BgColor, opacity)
+	effectiveFgColor := render.ApplyOpacity(el.FgColor, opacity)
+	borderColor := render.ApplyOpacity(el.BorderColor, opacity)
+
+	box := render.Rect{X: float32(renderX), Y: float32(renderY), W: float32(renderW), H: float32(renderH)}
+	radii := render.BorderRadii(el, scale)
+	r.fillRoundedRect(box, radii, effectiveBgColor)
+
+	clampedTop, clampedBottom := clampOpposingBorders(int(scaledI32(el.BorderWidths[0], scale)), int(scaledI32(el.BorderWidths[2], scale)), renderH)
+	clampedLeft, clampedRight := clampOpposingBorders(int(scaledI32(el.BorderWidths[3], scale)), int(scaledI32(el.BorderWidths[1], scale)), renderW)
+	innerBox := render.Rect{
+		X: box.X + float32(clampedLeft),
+		Y: box.Y + float32(clampedTop),
+		W: box.W - float32(clampedLeft+clampedRight),
+		H: box.H - float32(clampedTop+clampedBottom),
+	}
+	innerRadii := radii.Inset(float32(clampedTop), float32(clampedRight), float32(clampedBottom), float32(clampedLeft))
+	if radii.IsZero() {
+		r.drawBorders(renderX, renderY, renderW, renderH, clampedTop, clampedRight, clampedBottom, clampedLeft, borderColor)
+	} else if clampedTop+clampedRight+clampedBottom+clampedLeft > 0 {
+		r.drawRoundedBorders(box, innerBox, radii, innerRadii, borderColor)
+	}
+
+	paddingTop := scaledI32(el.Padding[0], scale)
+	paddingRight := scaledI32(el.Padding[1], scale)
+	paddingBottom := scaledI32(el.Padding[2], scale)
+	paddingLeft := scaledI32(el.Padding[3], scale)
+
+	contentX := int(renderXf + float32(clampedLeft) + float32(paddingLeft))
+	contentY := int(renderYf + float32(clampedTop) + float32(paddingTop))
+	contentWidth := int(maxI32(0, int32(renderWf-float32(clampedLeft+clampedRight)-float32(paddingLeft+paddingRight))))
+	contentHeight := int(maxI32(0, int32(renderHf-float32(clampedTop+clampedBottom)-float32(paddingTop+paddingBottom))))
+
+	if contentWidth > 0 && contentHeight > 0 {
+		savedClip := r.clip
+		r.clip = image.Rect(contentX, contentY, contentX+contentWidth, contentY+contentHeight).Intersect(savedClip)
+		// Content, like an image filling a button, stays within the rounded corners.
+		restore := r.clipRounded(innerBox, innerRadii)
+		scaledResolvedFontSize := maxF(1.0, el.ResolvedFontSize*scale)
+		r.drawContent(el, contentX, contentY, contentWidth, contentHeight, effectiveFgColor, scaledResolvedFontSize, opacity)
+		restore()
+		r.clip = savedClip
+	}
+
+	r.drawChildren(el, scale)
+}
+
+// drawChildren draws el's children in paint order, clipped to el's padding box,
+// with its rounded corners, if el clips its children.
+func (r *SoftwareRenderer) drawChildren(el *render.RenderElement, scale float32) {
+	savedClip := r.clip
+	restore := func() {}
+	if render.ClipsChildren(el) {
+		box := render.PaddingBox(el, scale)
+		r.clip = image.Rect(int(box.X), int(box.Y), int(box.X+box.W), int(box.Y+box.H)).Intersect(savedClip)
+		restore = r.clipRounded(box, render.PaddingBoxRadii(el, scale))
+	}
+	for _, child := range render.PaintOrder(el.Children) {
+		r.renderElementRecursiveWithCustomDraw(child, scale)
+	}
+	if render.Scrolls(el) {
+		r.drawScrollbars(el)
+	}
+	restore()
+	r.clip = savedClip
+}
+
+// drawScrollbars draws the scrollbars of a scrolling element over its content.
+func (r *SoftwareRenderer) drawScrollbars(el *render.RenderElement) {
+	opacity := render.EffectiveOpacity(el)
+	trackColor := render.ApplyOpacity(el.ScrollbarTrackColor, opacity)
+	thumbColor := render.ApplyOpacity(el.ScrollbarColor, opacity)
+	s := &el.Scroll
+	for _, bar := range [][2]render.Rect{{s.TrackV, s.ThumbV}, {s.TrackH, s.ThumbH}} {
+		track, thumb := bar[0], bar[1]
+		if track.W <= 0 || track.H <= 0 {
+			continue
+		}
+		r.fillRect(int(track.X), int(track.Y), int(track.W), int(track.H), trackColor)
+		r.fillRect(int(thumb.X), int(thumb.Y), int(thumb.W), int(thumb.H), thumbColor)
+	}
+}
+
+func (r *SoftwareRenderer) drawContent(el *render.RenderElement, cx, cy, cw, ch int, effectiveFgColor color.RGBA, scaledResolvedFontSize float32, opacity float32) {
+	if (el.Header.Type == krb.ElemTypeText || el.Header.Type == krb.ElemTypeButton) && el.Text != "" {
+		fontSize := int(scaledResolvedFontSize)
+		if fontSize < 1 {
+			fontSize = 1
+		}
+
+		textFont := r.fontFor(el)
+		lines := render.WrapText(textFont, el.Text, float32(fontSize), float32(cw), int(el.MaxLines))
+		lineHeight := render.TextLineHeight(el, textFont, float32(fontSize), r.scaleFactor)
+
+		// The block of lines is centered vertically; each line is aligned on its own.
+		blockTop := float32(cy) + (float32(ch)-lineHeight*float32(len(lines)))/2
+		for i, line := range lines {
+			textWidthMeasured := int(textFont.MeasureText(line, float32(fontSize)))
+			textDrawX := cx
+			textDrawY := int(blockTop + lineHeight*float32(i) + (lineHeight-float32(fontSize))/2)
+
+			switch el.TextAlignment {
+			case krb.LayoutAlignCenter:
+				textDrawX = cx + (cw-textWidthMeasured)/2
+			case krb.LayoutAlignEnd:
+				textDrawX = cx + cw - textWidthMeasured
+			}
+			r.drawText(textFont, line, textDrawX, textDrawY, fontSize, effectiveFgColor)
+		}
+	}
+
+	if el.Header.Type == krb.ElemTypeInput {
+		r.drawInput(el, cx, cy, cw, ch, effectiveFgColor, scaledResolvedFontSize)
+	}
+
+	isImageElement := (el.Header.Type == krb.ElemTypeImage || el.Header.Type == krb.ElemTypeButton)
+	if isImageElement && el.TextureLoaded {
+		if img, ok := el.Texture.(image.Image); ok {
+			r.drawImage(img, cx, cy, cw, ch, opacity)
+		}
+	}
+}
+
+// --- Math Utilities ---
+
+func scaledI32(value uint8, scale float32) int32 {
+	return int32(math.Round(float64(value) * float64(scale)))
+}
+
+func maxF(a, b float32) float32 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func maxI32(a, b int32) int32 {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,147 @@
+package software
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"testing"
+
+	"github.com/kryonlabs/kryon-go-runtime/krb"
+	"github.com/kryonlabs/kryon-go-runtime/render"
+)
+
+// prepare builds the render tree of doc and lays it out.
+func prepare(t *testing.T, doc *krb.Document, krbFilePath string) (*SoftwareRenderer, []*render.RenderElement) {
+	t.Helper()
+	r := NewSoftwareRenderer()
+	roots, cfg, err := r.PrepareTree(doc, krbFilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Init(cfg); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.LoadAllTextures(); err != nil {
+		t.Fatal(err)
+	}
+	r.UpdateLayout(roots)
+	return r, roots
+}
+
+// drawOnce renders one frame.
+func drawOnce(r *SoftwareRenderer, roots []*render.RenderElement) {
+	r.BeginFrame()
+	r.DrawFrame(roots)
+	r.EndFrame()
+}
+
+func buildDocument(t *testing.T, b *krb.Builder) *krb.Document {
+	t.Helper()
+	doc, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+func windowApp(b *krb.Builder, width, height uint16) *krb.ElementBuilder {
+	return b.AddElement(krb.ElemTypeApp).Property(
+		krb.ShortProperty(krb.PropIDWindowWidth, width),
+		krb.ShortProperty(krb.PropIDWindowHeight, height),
+		krb.ColorProperty(krb.PropIDBgColor, 0, 0, 0, 255),
+	)
+}
+
+func TestSoftwareRendererButtonExample(t *testing.T) {
+	const path = "../../examples/button/button.krb"
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err := krb.ReadDocument(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, roots := prepare(t, doc, path)
+	clicks := 0
+	r.RegisterEventHandler("handleButtonClick", func() { clicks++ })
+	drawOnce(r, roots)
+
+	if got := r.Image().Bounds().Size(); got.X != r.config.Width || got.Y != r.config.Height {
+		t.Errorf("canvas is %v, want the %dx%d window", got, r.config.Width, r.config.Height)
+	}
+	var button *render.RenderElement
+	for _, el := range r.GetRenderTree() {
+		if el.Header.Type == krb.ElemTypeButton {
+			button = el
+		}
+	}
+	if button == nil || button.RenderW <= 0 || button.RenderH <= 0 {
+		t.Fatalf("button was not laid out: %+v", button)
+	}
+	// Sample inside the border, away from the centered label.
+	x, y := int(button.RenderX)+4, int(button.RenderY+button.RenderH/2)
+	if got := r.Image().RGBAAt(x, y); got != button.BgColor {
+		t.Errorf("pixel (%d,%d) inside the button is %v, want its background %v", x, y, got, button.BgColor)
+	}
+
+	r.Click(button.RenderX+button.RenderW/2, button.RenderY+button.RenderH/2)
+	r.PollEventsAndProcessInteractions()
+	if clicks != 1 {
+		t.Errorf("click handler ran %d times, want 1", clicks)
+	}
+}
+
+func TestSoftwareRendererBackgroundsAndOpacity(t *testing.T) {
+	b := krb.NewBuilder()
+	app := windowApp(b, 100, 100)
+	app.AddChild(krb.ElemTypeContainer).Layout(krb.LayoutAbsoluteBit).Pos(10, 10).Size(20, 20).
+		Property(krb.ColorProperty(krb.PropIDBgColor, 255, 0, 0, 255))
+	app.AddChild(krb.ElemTypeContainer).Layout(krb.LayoutAbsoluteBit).Pos(50, 50).Size(20, 20).
+		Property(krb.ColorProperty(krb.PropIDBgColor, 0, 0, 255, 255))
+	r, roots := prepare(t, buildDocument(t, b), "test.krb")
+	r.GetRenderTree()[2].Opacity = 0.5
+	drawOnce(r, roots)
+
+	img := r.Image()
+	if got, want := img.RGBAAt(15, 15), (color.RGBA{255, 0, 0, 255}); got != want {
+		t.Errorf("opaque box pixel = %v, want %v", got, want)
+	}
+	if got, want := img.RGBAAt(5, 5), (color.RGBA{0, 0, 0, 255}); got != want {
+		t.Errorf("window pixel = %v, want %v", got, want)
+	}
+	if got := img.RGBAAt(55, 55); got.B < 120 || got.B > 136 || got.R != 0 {
+		t.Errorf("half-transparent box pixel = %v, want blue at about half intensity", got)
+	}
+}
+
+func TestSoftwareRendererInlineImage(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	for i := range src.Pix {
+		src.Pix[i] = 255
+	}
+	src.SetRGBA(0, 0, color.RGBA{255, 0, 0, 255})
+	var encoded bytes.Buffer
+	if err := png.Encode(&encoded, src); err != nil {
+		t.Fatal(err)
+	}
+
+	b := krb.NewBuilder()
+	res := b.AddInlineResource(krb.ResTypeImage, "pixels", encoded.Bytes())
+	windowApp(b, 100, 100).AddChild(krb.ElemTypeImage).Size(40, 40).Property(krb.ResourceProperty(krb.PropIDImageSource, res))
+	r, roots := prepare(t, buildDocument(t, b), "test.krb")
+	drawOnce(r, roots)
+
+	img := r.Image()
+	if got, want := img.RGBAAt(1, 1), (color.RGBA{255, 0, 0, 255}); got != want {
+		t.Errorf("top-left image pixel = %v, want %v", got, want)
+	}
+	if got, want := img.RGBAAt(38, 38), (color.RGBA{255, 255, 255, 255}); got != want {
+		t.Errorf("bottom-right image pixel = %v, want %v", got, want)
+	}
+	if got, want := img.RGBAAt(60, 60), (color.RGBA{0, 0, 0, 255}); got != want {
+		t.Errorf("pixel outside the image = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,74 @@
+// render/software/text.go
+package software
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+
+	"github.com/kryonlabs/kryon-go-runtime/render"
+)
+
+// drawText draws a single line of text in textFont whose line box, fontSize
+// pixels tall, starts at (x, y). Glyphs are clipped to the current clip rectangle.
+func (r *SoftwareRenderer) drawText(textFont *render.TTFFontProvider, text string, x, y int, fontSize int, c color.RGBA) {
+	face := textFont.Face(fontSize)
+	if face == nil || c.A == 0 {
+		return
+	}
+	dst, ok := r.canvas.SubImage(r.clip).(*image.RGBA)
+	if !ok || dst.Bounds().Empty() {
+		return
+	}
+	// Center the font's ascent+descent box within the fontSize-tall line box.
+	metrics := face.Metrics()
+	ascent := metrics.Ascent.Ceil()
+	extent := ascent + metrics.Descent.Ceil()
+	baseline := y + ascent - int(math.Round(float64(extent-fontSize)/2))
+
+	d := &font.Drawer{
+		Dst:  dst,
+		Src:  image.NewUniform(color.NRGBA(c)),
+		Face: face,
+		Dot:  fixed.P(x, baseline),
+	}
+	d.DrawString(text)
+}
+
+// drawInput draws the single line of an Input's text, scrolled to keep the
+// caret visible, with the selection highlight and blinking caret if it has focus.
+func (r *SoftwareRenderer) drawInput(el *render.RenderElement, cx, cy, cw, ch int, effectiveFgColor color.RGBA, scaledResolvedFontSize float32) {
+	fontSize := int(scaledResolvedFontSize)
+	if fontSize < 1 {
+		fontSize = 1
+	}
+	textFont := r.fontFor(el)
+	textDrawX := cx
+	textDrawY := cy + (ch-fontSize)/2
+
+	in := r.events.TextInput()
+	if in == nil || in.El != el {
+		r.drawText(textFont, el.Text, textDrawX, textDrawY, fontSize, effectiveFgColor)
+		return
+	}
+	in.ScrollToCaret(textFont, float32(fontSize), float32(cw))
+	textDrawX -= int(in.ScrollX)
+
+	selStartX, selEndX := in.SelectionX(textFont, float32(fontSize))
+	if selEndX > selStartX {
+		r.fillRect(textDrawX+int(selStartX), textDrawY, int(selEndX)-int(selStartX), fontSize, render.SelectionColor(effectiveFgColor))
+	}
+	r.drawText(textFont, el.Text, textDrawX, textDrawY, fontSize, effectiveFgColor)
+	if in.CaretVisible(r.animator.Now()) {
+		caretWidth := int(maxF(1, r.scaleFactor))
+		r.fillRect(textDrawX+int(in.CaretX(textFont, float32(fontSize))), textDrawY, caretWidth, fontSize, effectiveFgColor)
+	}
+}
+
+// fontSize returns the whole-pixel size el's text is drawn at.
+func (r *SoftwareRenderer) fontSize(el *render.RenderElement) float32 {
+	return float32(int(maxF(1.0, el.ResolvedFontSize*r.scaleFactor)))
+}
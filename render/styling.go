@@ -0,0 +1,687 @@
+// render/styling.go
+package render
+
+import (
+	"image/color"
+	"log" // For debug logging
+
+	"github.com/kryonlabs/kryon-go-runtime/krb"
+)
+
+// --- Methods for Applying Properties to WindowConfig ---
+
+func (t *Tree) applyStylePropertiesToWindowConfig(
+	props []krb.Property,
+	doc *krb.Document,
+	config *WindowConfig,
+) {
+	if doc == nil || config == nil {
+		return
+	}
+	for _, prop := range props {
+		switch prop.ID {
+		case krb.PropIDBgColor:
+			if c, ok := ColorValue(&prop, doc.Header.Flags); ok {
+				config.DefaultBg = c
+			}
+		case krb.PropIDFgColor:
+			if c, ok := ColorValue(&prop, doc.Header.Flags); ok {
+				config.DefaultFgColor = c
+			}
+		case krb.PropIDBorderColor:
+			if c, ok := ColorValue(&prop, doc.Header.Flags); ok {
+				config.DefaultBorderColor = c
+			}
+		case krb.PropIDFontSize:
+			if fsRaw, ok := ShortValue(&prop); ok && fsRaw > 0 {
+				config.DefaultFontSize = float32(fsRaw)
+			}
+		case krb.PropIDFontFamily:
+			if family, ok := fontFamilyName(&prop, doc); ok {
+				config.DefaultFontFamily = family
+			}
+		}
+	}
+}
+
+func (t *Tree) applyDirectPropertiesToWindowConfig(
+	props []krb.Property,
+	doc *krb.Document,
+	config *WindowConfig,
+) {
+	if config == nil || doc == nil {
+		return
+	}
+	for _, prop := range props {
+		switch prop.ID {
+		case krb.PropIDWindowWidth:
+			if w, ok := ShortValue(&prop); ok && w > 0 {
+				config.Width = int(w)
+			}
+		case krb.PropIDWindowHeight:
+			if h, ok := ShortValue(&prop); ok && h > 0 {
+				config.Height = int(h)
+			}
+		case krb.PropIDWindowTitle:
+			if strIdx, ok := ByteValue(&prop); ok {
+				if s, strOk := StringAt(doc, strIdx); strOk {
+					config.Title = s
+				}
+			}
+		case krb.PropIDResizable:
+			if rVal, ok := ByteValue(&prop); ok {
+				config.Resizable = (rVal != 0)
+			}
+		case krb.PropIDScaleFactor:
+			if sfRaw, ok := ShortValue(&prop); ok && sfRaw > 0 {
+				config.ScaleFactor = float32(sfRaw) / 256.0
+			}
+		case krb.PropIDBgColor:
+			if c, ok := ColorValue(&prop, doc.Header.Flags); ok {
+				config.DefaultBg = c
+			}
+		case krb.PropIDFgColor:
+			if c, ok := ColorValue(&prop, doc.Header.Flags); ok {
+				config.DefaultFgColor = c
+			}
+		case krb.PropIDBorderColor:
+			if c, ok := ColorValue(&prop, doc.Header.Flags); ok {
+				config.DefaultBorderColor = c
+			}
+		case krb.PropIDFontSize:
+			if fsRaw, ok := ShortValue(&prop); ok && fsRaw > 0 {
+				config.DefaultFontSize = float32(fsRaw)
+			}
+		case krb.PropIDFontFamily:
+			if family, ok := fontFamilyName(&prop, doc); ok {
+				config.DefaultFontFamily = family
+			}
+		}
+	}
+}
+
+// --- Methods for Applying Properties to RenderElement ---
+
+func (t *Tree) applyStylePropertiesToElement(
+	props []krb.Property,
+	doc *krb.Document,
+	el *RenderElement,
+) {
+	if doc == nil || el == nil {
+		return
+	}
+	for _, prop := range props {
+		switch prop.ID {
+		case krb.PropIDBgColor:
+			if c, ok := ColorValue(&prop, doc.Header.Flags); ok {
+				el.BgColor = c
+			}
+		case krb.PropIDFgColor:
+			if c, ok := ColorValue(&prop, doc.Header.Flags); ok {
+				el.FgColor = c
+			}
+		case krb.PropIDBorderColor:
+			if c, ok := ColorValue(&prop, doc.Header.Flags); ok {
+				el.BorderColor = c
+			}
+		case krb.PropIDBorderWidth:
+			if bw, ok := ByteValue(&prop); ok {
+				el.BorderWidths = [4]uint8{bw, bw, bw, bw}
+			} else if edges, okEdges := EdgeInsetsValue(&prop); okEdges {
+				el.BorderWidths = edges
+			}
+		case krb.PropIDPadding:
+			if p, ok := EdgeInsetsValue(&prop); ok {
+				el.Padding = p
+			}
+		case krb.PropIDBorderRadius:
+			if radii, ok := BorderRadiusValue(&prop); ok {
+				el.BorderRadius = radii
+			}
+		case krb.PropIDMargin:
+			if m, ok := ByteValue(&prop); ok {
+				el.Margin = [4]uint8{m, m, m, m}
+			} else if edges, okEdges := EdgeInsetsValue(&prop); okEdges {
+				el.Margin = edges
+			}
+		case krb.PropIDTextAlignment:
+			if align, ok := ByteValue(&prop); ok {
+				el.TextAlignment = align
+			}
+		case krb.PropIDVisibility:
+			if vis, ok := ByteValue(&prop); ok {
+				el.IsVisible = (vis != 0)
+			}
+		case krb.PropIDFontSize:
+			if fsRaw, ok := ShortValue(&prop); ok && fsRaw > 0 {
+				el.ResolvedFontSize = float32(fsRaw)
+			}
+		case krb.PropIDFontFamily:
+			if resIdx, ok := FontResourceValue(&prop, doc); ok {
+				el.FontResourceIndex = resIdx
+			}
+		case krb.PropIDLineHeight:
+			applyLineHeight(el, &prop)
+		case krb.PropIDMaxLines:
+			if maxLines, ok := ByteValue(&prop); ok {
+				el.MaxLines = maxLines
+			}
+		case krb.PropIDZIndex:
+			if z, ok := zIndexValue(&prop); ok {
+				el.ZIndex = z
+			}
+		case krb.PropIDOverflow:
+			if overflow, ok := ByteValue(&prop); ok {
+				el.Overflow = overflow
+			}
+		}
+	}
+}
+
+func (t *Tree) applyDirectPropertiesToElement(
+	props []krb.Property,
+	doc *krb.Document,
+	el *RenderElement,
+) {
+	if doc == nil || el == nil {
+		return
+	}
+	for _, prop := range props {
+		switch prop.ID {
+		case krb.PropIDBgColor:
+			if c, ok := ColorValue(&prop, doc.Header.Flags); ok {
+				el.BgColor = c
+			}
+		case krb.PropIDFgColor:
+			if c, ok := ColorValue(&prop, doc.Header.Flags); ok {
+				el.FgColor = c
+			}
+		case krb.PropIDBorderColor:
+			if c, ok := ColorValue(&prop, doc.Header.Flags); ok {
+				el.BorderColor = c
+			}
+		case krb.PropIDBorderWidth:
+			if bw, ok := ByteValue(&prop); ok {
+				el.BorderWidths = [4]uint8{bw, bw, bw, bw}
+			} else if edges, okEdges := EdgeInsetsValue(&prop); okEdges {
+				el.BorderWidths = edges
+			}
+		case krb.PropIDPadding:
+			if p, ok := EdgeInsetsValue(&prop); ok {
+				el.Padding = p
+			}
+		case krb.PropIDBorderRadius:
+			if radii, ok := BorderRadiusValue(&prop); ok {
+				el.BorderRadius = radii
+			}
+		case krb.PropIDMargin:
+			if m, ok := ByteValue(&prop); ok {
+				el.Margin = [4]uint8{m, m, m, m}
+			} else if edges, okEdges := EdgeInsetsValue(&prop); okEdges {
+				el.Margin = edges
+			}
+		case krb.PropIDTextAlignment:
+			if align, ok := ByteValue(&prop); ok {
+				el.TextAlignment = align
+			}
+		case krb.PropIDVisibility:
+			if vis, ok := ByteValue(&prop); ok {
+				el.IsVisible = (vis != 0)
+			}
+		case krb.PropIDTextContent:
+			if strIdx, ok := ByteValue(&prop); ok {
+				if textVal, textOk := StringAt(doc, strIdx); textOk {
+					el.Text = textVal
+				}
+			}
+		case krb.PropIDImageSource:
+			if resIdx, ok := ByteValue(&prop); ok {
+				el.ResourceIndex = resIdx
+			}
+		case krb.PropIDFontSize:
+			if fsRaw, ok := ShortValue(&prop); ok && fsRaw > 0 {
+				el.ResolvedFontSize = float32(fsRaw)
+			}
+		case krb.PropIDFontFamily:
+			if resIdx, ok := FontResourceValue(&prop, doc); ok {
+				el.FontResourceIndex = resIdx
+			}
+		case krb.PropIDLineHeight:
+			applyLineHeight(el, &prop)
+		case krb.PropIDMaxLines:
+			if maxLines, ok := ByteValue(&prop); ok {
+				el.MaxLines = maxLines
+			}
+		case krb.PropIDZIndex:
+			if z, ok := zIndexValue(&prop); ok {
+				el.ZIndex = z
+			}
+		case krb.PropIDOverflow:
+			if overflow, ok := ByteValue(&prop); ok {
+				el.Overflow = overflow
+			}
+		default:
+			continue
+		}
+	}
+}
+
+func (t *Tree) applyDirectVisualPropertiesToAppElement(
+	props []krb.Property,
+	doc *krb.Document,
+	el *RenderElement,
+) {
+	if doc == nil || el == nil {
+		return
+	}
+	for _, prop := range props {
+		switch prop.ID {
+		case krb.PropIDBgColor:
+			if c, ok := ColorValue(&prop, doc.Header.Flags); ok {
+				el.BgColor = c
+			}
+		case krb.PropIDFgColor:
+			if c, ok := ColorValue(&prop, doc.Header.Flags); ok {
+				el.FgColor = c
+			}
+		case krb.PropIDBorderColor:
+			if c, ok := ColorValue(&prop, doc.Header.Flags); ok {
+				el.BorderColor = c
+			}
+		case krb.PropIDBorderWidth:
+			if bw, ok := ByteValue(&prop); ok {
+				el.BorderWidths = [4]uint8{bw, bw, bw, bw}
+			} else if edges, okEdges := EdgeInsetsValue(&prop); okEdges {
+				el.BorderWidths = edges
+			}
+		case krb.PropIDPadding:
+			if p, ok := EdgeInsetsValue(&prop); ok {
+				el.Padding = p
+			}
+		case krb.PropIDBorderRadius:
+			if radii, ok := BorderRadiusValue(&prop); ok {
+				el.BorderRadius = radii
+			}
+		case krb.PropIDMargin:
+			if m, ok := ByteValue(&prop); ok {
+				el.Margin = [4]uint8{m, m, m, m}
+			} else if edges, okEdges := EdgeInsetsValue(&prop); okEdges {
+				el.Margin = edges
+			}
+		case krb.PropIDVisibility:
+			if vis, ok := ByteValue(&prop); ok {
+				el.IsVisible = (vis != 0)
+			}
+		case krb.PropIDFontSize:
+			if fsRaw, ok := ShortValue(&prop); ok && fsRaw > 0 {
+				el.ResolvedFontSize = float32(fsRaw)
+			}
+		case krb.PropIDFontFamily:
+			if resIdx, ok := FontResourceValue(&prop, doc); ok {
+				el.FontResourceIndex = resIdx
+			}
+		}
+	}
+}
+
+// --- Methods for Resolving Content and Contextual Defaults ---
+
+func (t *Tree) resolveElementTextAndImage(
+	doc *krb.Document,
+	el *RenderElement,
+	style *krb.Style,
+	styleFound bool,
+) {
+	if doc == nil || el == nil {
+		return
+	}
+	if (el.Header.Type == krb.ElemTypeText || el.Header.Type == krb.ElemTypeButton || el.Header.Type == krb.ElemTypeInput) && el.Text == "" {
+		if styleFound && style != nil {
+			if styleProp, propInStyleOk := StyleProperty(style, krb.PropIDTextContent); propInStyleOk {
+				if strIdx, ok := ByteValue(styleProp); ok {
+					if s, textOk := StringAt(doc, strIdx); textOk {
+						el.Text = s
+					}
+				}
+			}
+		}
+	}
+	if (el.Header.Type == krb.ElemTypeImage || el.Header.Type == krb.ElemTypeButton) && el.ResourceIndex == InvalidResourceIndex {
+		if styleFound && style != nil {
+			if styleProp, propInStyleOk := StyleProperty(style, krb.PropIDImageSource); propInStyleOk {
+				if idx, ok := ByteValue(styleProp); ok {
+					el.ResourceIndex = idx
+				}
+			}
+		}
+	}
+}
+
+// resolveElementFont applies the FontFamilyKey custom property, which overrides
+// the style and direct font family.
+func (t *Tree) resolveElementFont(doc *krb.Document, el *RenderElement) {
+	if doc == nil || el == nil {
+		return
+	}
+	family, found := GetCustomPropertyValue(el, FontFamilyKey, doc)
+	if !found || family == "" {
+		return
+	}
+	if resIdx, ok := FindFontResource(doc, family); ok {
+		el.FontResourceIndex = resIdx
+	} else {
+		log.Printf("WARN resolveElementFont: No font resource found for family '%s' on '%s'.", family, el.SourceElementName)
+	}
+}
+
+// zIndexValue decodes a krb.PropIDZIndex value. Short values are signed.
+func zIndexValue(prop *krb.Property) (int, bool) {
+	if v, ok := ShortValue(prop); ok {
+		return int(int16(v)), true
+	}
+	if v, ok := ByteValue(prop); ok {
+		return int(v), true
+	}
+	return 0, false
+}
+
+// fontFamilyName returns the family named by a krb.PropIDFontFamily value: the
+// string itself, or the name of the referenced font resource.
+func fontFamilyName(prop *krb.Property, doc *krb.Document) (string, bool) {
+	idx, ok := ByteValue(prop)
+	if !ok {
+		return "", false
+	}
+	switch prop.ValueType {
+	case krb.ValTypeString:
+		return StringAt(doc, idx)
+	case krb.ValTypeResource:
+		if int(idx) < len(doc.Resources) {
+			return StringAt(doc, doc.Resources[idx].NameIndex)
+		}
+	}
+	return "", false
+}
+
+func (t *Tree) applyContextualDefaults(el *RenderElement) {
+	if el == nil {
+		return
+	}
+	hasBorderColor := el.BorderColor.A > 0
+	allBorderWidthsZero := true
+	for _, bw := range el.BorderWidths {
+		if bw > 0 {
+			allBorderWidthsZero = false
+			break
+		}
+	}
+	if hasBorderColor && allBorderWidthsZero {
+		el.BorderWidths = [4]uint8{1, 1, 1, 1}
+	} else if !allBorderWidthsZero && !hasBorderColor {
+		el.BorderColor = t.Config.DefaultBorderColor
+	}
+}
+
+// --- Methods for Property Inheritance ---
+
+const UnsetTextAlignmentSentinel = 0xFF // Define an "unset" marker for TextAlignment
+
+func (t *Tree) resolvePropertyInheritance() {
+	if len(t.Roots) == 0 || t.Doc == nil {
+		return
+	}
+	log.Println("BuildTree: Resolving property inheritance...")
+
+	initialFgColor := t.Config.DefaultFgColor
+	initialFontSize := t.Config.DefaultFontSize
+	initialTextAlignment := uint8(krb.LayoutAlignStart) // App-level default
+	initialFontResource := t.defaultFontResource()
+
+	for _, rootEl := range t.Roots {
+		isTextBearingRoot := (rootEl.Header.Type == krb.ElemTypeText || rootEl.Header.Type == krb.ElemTypeButton || rootEl.Header.Type == krb.ElemTypeInput)
+
+		// Resolve FgColor for root
+		if isTextBearingRoot && (rootEl.FgColor == (color.RGBA{}) || rootEl.FgColor.A == 0) {
+			rootEl.FgColor = initialFgColor
+		}
+		fgColorToPassToChildren := rootEl.FgColor
+		if fgColorToPassToChildren.A == 0 {
+			fgColorToPassToChildren = initialFgColor
+		}
+
+		// Resolve FontSize for root
+		resolvedRootFontSize := rootEl.ResolvedFontSize
+		if resolvedRootFontSize == 0.0 {
+			rootEl.ResolvedFontSize = initialFontSize
+			resolvedRootFontSize = initialFontSize
+		}
+
+		// Resolve TextAlignment for root
+		// If TextAlignment was initialized to UnsetTextAlignmentSentinel and not set by style/direct,
+		// it inherits the app-level default. Otherwise, it uses its value (which might be LayoutAlignStart by base init).
+		resolvedRootTextAlignment := rootEl.TextAlignment
+		if rootEl.TextAlignment == UnsetTextAlignmentSentinel { // Check if it's explicitly "unset" for inheritance
+			rootEl.TextAlignment = initialTextAlignment
+			resolvedRootTextAlignment = initialTextAlignment
+		}
+		// If not using a sentinel, TextAlignment would have been set to LayoutAlignStart during
+		// PrepareTree's element initialization if no style/direct prop set it.
+		// So, resolvedRootTextAlignment = rootEl.TextAlignment is usually correct.
+
+		// Resolve font for root
+		if rootEl.FontResourceIndex == InvalidResourceIndex {
+			rootEl.FontResourceIndex = initialFontResource
+		}
+
+		t.applyInheritanceRecursive(rootEl, fgColorToPassToChildren, resolvedRootFontSize, resolvedRootTextAlignment, rootEl.FontResourceIndex)
+	}
+}
+
+// defaultFontResource returns the font resource named by
+// WindowConfig.DefaultFontFamily, or InvalidResourceIndex.
+func (t *Tree) defaultFontResource() uint8 {
+	if t.Config.DefaultFontFamily == "" {
+		return InvalidResourceIndex
+	}
+	resIdx, ok := FindFontResource(t.Doc, t.Config.DefaultFontFamily)
+	if !ok {
+		log.Printf("WARN BuildTree: No font resource found for default font family '%s'.", t.Config.DefaultFontFamily)
+	}
+	return resIdx
+}
+
+func (t *Tree) applyInheritanceRecursive(
+	el *RenderElement,
+	inheritedFgColor color.RGBA,
+	inheritedFontSize float32,
+	inheritedTextAlignment uint8,
+	inheritedFontResource uint8,
+) {
+	if el == nil {
+		return
+	}
+
+	// 1. ForegroundColor
+	isTextBearing := (el.Header.Type == krb.ElemTypeText || el.Header.Type == krb.ElemTypeButton || el.Header.Type == krb.ElemTypeInput)
+	if isTextBearing && (el.FgColor == (color.RGBA{}) || el.FgColor.A == 0) {
+		if inheritedFgColor.A > 0 {
+			el.FgColor = inheritedFgColor
+		} else {
+			el.FgColor = t.Config.DefaultFgColor
+		}
+	}
+	fgColorForChildren := el.FgColor
+	if el.FgColor.A == 0 {
+		fgColorForChildren = inheritedFgColor
+	}
+
+	// 2. FontSize
+	if el.ResolvedFontSize == 0.0 {
+		el.ResolvedFontSize = inheritedFontSize
+	}
+	fontSizeForChildren := el.ResolvedFontSize
+
+	// 3. TextAlignment
+	// If TextAlignment was initialized to UnsetTextAlignmentSentinel and not set by style/direct for 'el', inherit.
+	if el.TextAlignment == UnsetTextAlignmentSentinel {
+		el.TextAlignment = inheritedTextAlignment
+	}
+	// Children inherit the now resolved el.TextAlignment
+	textAlignmentForChildren := el.TextAlignment
+
+	// 4. Font
+	if el.FontResourceIndex == InvalidResourceIndex {
+		el.FontResourceIndex = inheritedFontResource
+	}
+
+	for _, child := range el.Children {
+		t.applyInheritanceRecursive(child, fgColorForChildren, fontSizeForChildren, textAlignmentForChildren, el.FontResourceIndex)
+	}
+}
+
+// --- Method for Re-Resolving Visuals of a Single Element ---
+
+func (t *Tree) ReResolveElementVisuals(el *RenderElement) {
+	if el == nil || t.Doc == nil {
+		log.Printf("WARN ReResolveElementVisuals: Element or document reference is nil.")
+		return
+	}
+
+	log.Printf("INFO ReResolveElementVisuals: Re-resolving visuals for '%s' (StyleID: %d)", el.SourceElementName, el.Header.StyleID)
+
+	// 1. Reset visual properties.
+	el.BgColor = color.RGBA{}
+	el.FgColor = color.RGBA{}
+	el.BorderColor = color.RGBA{}
+	el.BorderWidths = [4]uint8{0, 0, 0, 0}
+	el.Padding = [4]uint8{0, 0, 0, 0}
+	el.Margin = [4]uint8{0, 0, 0, 0}
+	el.BorderRadius = [4]uint16{0, 0, 0, 0}
+	el.TextAlignment = UnsetTextAlignmentSentinel // Reset to sentinel to force re-evaluation of inheritance or default
+	el.ResolvedFontSize = 0.0
+	el.FontResourceIndex = InvalidResourceIndex
+	el.LineHeight = 0
+	el.LineHeightRatio = 0
+	el.MaxLines = 0
+	el.ZIndex = 0
+	el.Overflow = defaultOverflow(el.Header.Type)
+
+	// 2. Apply the element's current StyleID properties.
+	style, styleFound := FindStyle(t.Doc, el.Header.StyleID)
+	if styleFound {
+		t.applyStylePropertiesToElement(style.Properties, t.Doc, el)
+	} else if el.Header.StyleID != 0 {
+		log.Printf("WARN ReResolveElementVisuals: StyleID %d for element '%s' not found.", el.Header.StyleID, el.SourceElementName)
+	}
+
+	// 3. Re-apply direct KRB properties.
+	if el.OriginalIndex >= 0 && el.OriginalIndex < len(t.Doc.Properties) && len(t.Doc.Properties[el.OriginalIndex]) > 0 {
+		t.applyDirectPropertiesToElement(t.Doc.Properties[el.OriginalIndex], t.Doc, el)
+	}
+
+	// 4. Re-apply contextual defaults.
+	t.applyContextualDefaults(el)
+
+	// 5. Re-resolve text and image source, and the font custom property.
+	t.resolveElementTextAndImage(t.Doc, el, style, styleFound)
+	t.resolveElementFont(t.Doc, el)
+
+	// 6. Re-resolve inheritance for `el` and propagate to its children.
+	inheritedFgColor := t.Config.DefaultFgColor
+	inheritedFontSize := t.Config.DefaultFontSize
+	inheritedTextAlignment := uint8(krb.LayoutAlignStart) // App-level default
+	inheritedFontResource := t.defaultFontResource()
+
+	if el.Parent != nil {
+		inheritedFontResource = el.Parent.FontResourceIndex
+		inheritedFgColor = t.getEffectiveInheritedFgColor(el.Parent)
+
+		if el.Parent.ResolvedFontSize != 0.0 {
+			inheritedFontSize = el.Parent.ResolvedFontSize
+		} else { // Parent might also be unset, trace up for font size
+			ancestorFontSize := t.getEffectiveInheritedFontSize(el.Parent)
+			inheritedFontSize = ancestorFontSize
+		}
+		// For TextAlignment, parent's TextAlignment is its computed value.
+		// If parent's was UnsetTextAlignmentSentinel, it would have inherited.
+		inheritedTextAlignment = el.Parent.TextAlignment
+		if el.Parent.TextAlignment == UnsetTextAlignmentSentinel { // Should not happen if parent was resolved
+			log.Printf("WARN ReResolveVisuals: Parent '%s' TextAlignment is Unset. Using app default for inheritance.", el.Parent.SourceElementName)
+			inheritedTextAlignment = uint8(krb.LayoutAlignStart)
+		}
+
+	}
+
+	// Apply to 'el' if its own properties are "unset".
+	isTextBearing := (el.Header.Type == krb.ElemTypeText || el.Header.Type == krb.ElemTypeButton || el.Header.Type == krb.ElemTypeInput)
+	if isTextBearing && (el.FgColor == (color.RGBA{}) || el.FgColor.A == 0) {
+		el.FgColor = inheritedFgColor
+	}
+	if el.ResolvedFontSize == 0.0 {
+		el.ResolvedFontSize = inheritedFontSize
+	}
+	if el.TextAlignment == UnsetTextAlignmentSentinel { // If still unset after style/direct
+		el.TextAlignment = inheritedTextAlignment
+	}
+	// If TextAlignment is still sentinel (e.g. root, no style/direct, and inherited was also sentinel - unlikely)
+	// default it to LayoutAlignStart as per spec.
+	if el.TextAlignment == UnsetTextAlignmentSentinel {
+		el.TextAlignment = uint8(krb.LayoutAlignStart)
+	}
+	if el.FontResourceIndex == InvalidResourceIndex {
+		el.FontResourceIndex = inheritedFontResource
+	}
+
+	// Fallback for text-bearing elements if still unset.
+	if isTextBearing && el.FgColor.A == 0 {
+		el.FgColor = t.Config.DefaultFgColor
+	}
+	if el.ResolvedFontSize == 0.0 {
+		el.ResolvedFontSize = t.Config.DefaultFontSize
+	}
+
+	// Determine computed values `el` will pass to its children.
+	computedFgColorForChildren := el.FgColor
+	if el.FgColor.A == 0 {
+		computedFgColorForChildren = inheritedFgColor
+	}
+	computedFontSizeForChildren := el.ResolvedFontSize
+	if el.ResolvedFontSize == 0.0 {
+		computedFontSizeForChildren = inheritedFontSize
+	}
+	computedTextAlignmentForChildren := el.TextAlignment
+
+	for _, child := range el.Children {
+		t.applyInheritanceRecursive(child, computedFgColorForChildren, computedFontSizeForChildren, computedTextAlignmentForChildren, el.FontResourceIndex)
+	}
+	log.Printf("INFO: ReResolveElementVisuals completed for '%s'. Final FgColor: %v, FontSize: %.1f, TextAlignment: %d", el.SourceElementName, el.FgColor, el.ResolvedFontSize, el.TextAlignment)
+}
+
+func (t *Tree) getEffectiveInheritedFgColor(el *RenderElement) color.RGBA {
+	if el == nil {
+		return t.Config.DefaultFgColor
+	}
+	ancestor := el
+	for ancestor != nil {
+		if ancestor.FgColor.A > 0 {
+			return ancestor.FgColor
+		}
+		ancestor = ancestor.Parent
+	}
+	return t.Config.DefaultFgColor
+}
+
+// Helper to get the FontSize an element would inherit (traces up if needed).
+func (t *Tree) getEffectiveInheritedFontSize(el *RenderElement) float32 {
+	if el == nil {
+		return t.Config.DefaultFontSize
+	}
+	ancestor := el
+	for ancestor != nil {
+		if ancestor.ResolvedFontSize != 0.0 {
+			return ancestor.ResolvedFontSize
+		}
+		ancestor = ancestor.Parent
+	}
+	return t.Config.DefaultFontSize
+}
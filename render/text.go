@@ -0,0 +1,136 @@
+// render/text.go
+package render
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/kryonlabs/kryon-go-runtime/krb"
+)
+
+// Ellipsis marks text cut off by RenderElement.MaxLines. Fonts without the
+// glyph get ellipsisFallback instead.
+const (
+	Ellipsis         = "…"
+	ellipsisFallback = "..."
+)
+
+// applyLineHeight sets el's line height from a krb.PropIDLineHeight value:
+// Short values are pixels, Percentage values are relative to the font size.
+func applyLineHeight(el *RenderElement, prop *krb.Property) {
+	value, valueType, err := NumericValue(prop)
+	if err != nil || value <= 0 {
+		return
+	}
+	if valueType == krb.ValTypePercentage {
+		el.LineHeight = 0
+		el.LineHeightRatio = value / 256.0
+		return
+	}
+	el.LineHeight = value
+	el.LineHeightRatio = 0
+}
+
+// TextLineHeight returns the distance in pixels between the tops of two
+// consecutive lines of el's text, drawn in textFont at fontSize pixels.
+func TextLineHeight(el *RenderElement, textFont FontProvider, fontSize, scale float32) float32 {
+	switch {
+	case el != nil && el.LineHeight > 0:
+		return el.LineHeight * scale
+	case el != nil && el.LineHeightRatio > 0:
+		return el.LineHeightRatio * fontSize
+	case textFont != nil:
+		if lineHeight := textFont.LineHeight(fontSize); lineHeight > 0 {
+			return lineHeight
+		}
+	}
+	return fontSize
+}
+
+// WrapText splits text into the lines it is drawn as. Lines break at newlines
+// and, if maxWidth is positive, between words so that no line is wider than
+// maxWidth; words wider than maxWidth are broken between characters. If
+// maxLines is positive, lines beyond it are dropped and the last kept line
+// ends in an ellipsis.
+func WrapText(textFont FontProvider, text string, fontSize, maxWidth float32, maxLines int) []string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	var lines []string
+	truncated := false
+	for _, paragraph := range strings.Split(text, "\n") {
+		lines = append(lines, wrapParagraph(textFont, paragraph, fontSize, maxWidth)...)
+		if maxLines > 0 && len(lines) > maxLines {
+			truncated = true
+			break
+		}
+	}
+	if maxLines > 0 && len(lines) > maxLines {
+		truncated = true
+		lines = lines[:maxLines]
+	}
+	if truncated {
+		last := len(lines) - 1
+		lines[last] = ellipsize(textFont, lines[last], fontSize, maxWidth)
+	}
+	return lines
+}
+
+func wrapParagraph(textFont FontProvider, paragraph string, fontSize, maxWidth float32) []string {
+	if maxWidth <= 0 || textFont == nil || textFont.MeasureText(paragraph, fontSize) <= maxWidth {
+		return []string{paragraph}
+	}
+	var lines []string
+	current := ""
+	for _, word := range strings.Split(paragraph, " ") {
+		candidate := word
+		if current != "" {
+			candidate = current + " " + word
+		}
+		if textFont.MeasureText(candidate, fontSize) <= maxWidth {
+			current = candidate
+			continue
+		}
+		if current != "" {
+			lines = append(lines, current)
+		}
+		for textFont.MeasureText(word, fontSize) > maxWidth {
+			n := fittingPrefixLen(textFont, word, fontSize, maxWidth)
+			lines = append(lines, word[:n])
+			word = word[n:]
+		}
+		current = word
+	}
+	return append(lines, current)
+}
+
+// fittingPrefixLen returns the byte length of the longest prefix of s, at least
+// one rune, that is no wider than maxWidth.
+func fittingPrefixLen(textFont FontProvider, s string, fontSize, maxWidth float32) int {
+	_, n := utf8.DecodeRuneInString(s)
+	for n < len(s) {
+		_, size := utf8.DecodeRuneInString(s[n:])
+		if textFont.MeasureText(s[:n+size], fontSize) > maxWidth {
+			break
+		}
+		n += size
+	}
+	return n
+}
+
+// ellipsize appends an ellipsis to line, dropping trailing characters until the
+// result fits in maxWidth.
+func ellipsize(textFont FontProvider, line string, fontSize, maxWidth float32) string {
+	ellipsis := Ellipsis
+	if textFont != nil {
+		if _, ok := textFont.Glyph('…', fontSize); !ok {
+			ellipsis = ellipsisFallback
+		}
+	}
+	if maxWidth <= 0 || textFont == nil {
+		return line + ellipsis
+	}
+	for line != "" && textFont.MeasureText(line+ellipsis, fontSize) > maxWidth {
+		_, size := utf8.DecodeLastRuneInString(line)
+		line = line[:len(line)-size]
+	}
+	return strings.TrimRight(line, " ") + ellipsis
+}
@@ -0,0 +1,75 @@
+package render
+
+import (
+	"strings"
+	"testing"
+)
+
+const paragraph = "Hello world this is a long paragraph"
+
+func TestWrapTextBreaksBetweenWords(t *testing.T) {
+	f := NewBundledFontProvider()
+	defer f.Close()
+
+	lines := WrapText(f, paragraph+"\nsupercalifragilisticexpialidocious", 16, 80, 0)
+	if len(lines) < 4 {
+		t.Fatalf("got %d lines %q, want the text wrapped", len(lines), lines)
+	}
+	for _, line := range lines {
+		if w := f.MeasureText(line, 16); w > 80 {
+			t.Errorf("line %q is %v wide, more than 80", line, w)
+		}
+	}
+	if got := strings.Join(lines, ""); strings.ReplaceAll(got, " ", "") != strings.ReplaceAll(paragraph+"supercalifragilisticexpialidocious", " ", "") {
+		t.Errorf("wrapping lost or reordered text: %q", lines)
+	}
+	if !strings.HasPrefix(lines[0], "Hello") || strings.HasSuffix(lines[0], " ") {
+		t.Errorf("first line %q does not break between words", lines[0])
+	}
+}
+
+func TestWrapTextWithoutWidth(t *testing.T) {
+	f := NewBundledFontProvider()
+	defer f.Close()
+
+	if got := WrapText(f, "one\r\ntwo", 16, 0, 0); len(got) != 2 || got[0] != "one" || got[1] != "two" {
+		t.Errorf("WrapText split at newlines = %q, want [one two]", got)
+	}
+	if got := WrapText(f, paragraph, 16, 0, 0); len(got) != 1 || got[0] != paragraph {
+		t.Errorf("WrapText without a width = %q, want the text unchanged", got)
+	}
+}
+
+func TestWrapTextMaxLinesEllipsis(t *testing.T) {
+	f := NewBundledFontProvider()
+	defer f.Close()
+
+	lines := WrapText(f, paragraph, 16, 80, 2)
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines %q, want 2", len(lines), lines)
+	}
+	if !strings.HasSuffix(lines[1], Ellipsis) {
+		t.Errorf("last line %q has no ellipsis", lines[1])
+	}
+	if w := f.MeasureText(lines[1], 16); w > 80 {
+		t.Errorf("ellipsized line %q is %v wide, more than 80", lines[1], w)
+	}
+	if got := WrapText(f, "short", 16, 80, 2); len(got) != 1 || got[0] != "short" {
+		t.Errorf("text within maxLines = %q, want it unchanged", got)
+	}
+}
+
+func TestTextLineHeight(t *testing.T) {
+	f := NewBundledFontProvider()
+	defer f.Close()
+
+	if got, want := TextLineHeight(&RenderElement{}, f, 18, 2), f.LineHeight(18); got != want {
+		t.Errorf("default line height = %v, want the font's %v", got, want)
+	}
+	if got := TextLineHeight(&RenderElement{LineHeight: 30}, f, 18, 2); got != 60 {
+		t.Errorf("pixel line height at scale 2 = %v, want 60", got)
+	}
+	if got := TextLineHeight(&RenderElement{LineHeightRatio: 1.5}, f, 18, 2); got != 27 {
+		t.Errorf("relative line height = %v, want 27", got)
+	}
+}
@@ -0,0 +1,228 @@
+// render/textinput.go
+package render
+
+import (
+	"image/color"
+	"math"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Key is an editing or navigation key, mapped by each backend from its own
+// key codes.
+type Key int
+
+const (
+	KeyNone Key = iota
+	KeyBackspace
+	KeyDelete
+	KeyLeft
+	KeyRight
+	KeyHome
+	KeyEnd
+	KeyEnter
+	KeyTab
+	KeySpace
+)
+
+// CaretBlinkInterval is how long the caret stays visible, and then hidden,
+// while the user is idle. Every edit or caret move shows it again.
+const CaretBlinkInterval = 530 * time.Millisecond
+
+// TextInput is the editing state of a focused krb.ElemTypeInput. The edited
+// value is the element's Text; Caret and Anchor are rune offsets into it.
+type TextInput struct {
+	El      *RenderElement
+	Caret   int     // Position new text is inserted at
+	Anchor  int     // Fixed end of the selection; equal to Caret if nothing is selected
+	ScrollX float32 // Pixels the text is shifted left to keep the caret visible
+
+	lastActivity time.Time
+}
+
+// NewTextInput starts editing el with the caret after the last character.
+func NewTextInput(el *RenderElement, now time.Time) *TextInput {
+	end := utf8.RuneCountInString(el.Text)
+	return &TextInput{El: el, Caret: end, Anchor: end, lastActivity: now}
+}
+
+// Selection returns the selected rune range; start == end if nothing is selected.
+func (in *TextInput) Selection() (start, end int) {
+	if in.Anchor < in.Caret {
+		return in.Anchor, in.Caret
+	}
+	return in.Caret, in.Anchor
+}
+
+// SelectedText returns the selected part of the text.
+func (in *TextInput) SelectedText() string {
+	in.clamp()
+	start, end := in.Selection()
+	return string([]rune(in.El.Text)[start:end])
+}
+
+// InsertText replaces the selection with s, dropping control characters. It
+// reports whether the text changed.
+func (in *TextInput) InsertText(s string, now time.Time) bool {
+	var insert []rune
+	for _, r := range s {
+		if !unicode.IsControl(r) {
+			insert = append(insert, r)
+		}
+	}
+	if len(insert) == 0 {
+		return false
+	}
+	in.replaceSelection(insert)
+	in.lastActivity = now
+	return true
+}
+
+// HandleKey applies an editing key. With shift held, navigation keys extend
+// the selection instead of moving the caret. changed reports whether the text
+// changed and submitted whether the key was Enter.
+func (in *TextInput) HandleKey(key Key, shift bool, now time.Time) (changed, submitted bool) {
+	in.clamp()
+	text := []rune(in.El.Text)
+	start, end := in.Selection()
+	in.lastActivity = now
+
+	switch key {
+	case KeyBackspace, KeyDelete:
+		if start == end {
+			if key == KeyBackspace && start > 0 {
+				in.Anchor = start - 1
+			} else if key == KeyDelete && end < len(text) {
+				in.Anchor = end + 1
+			} else {
+				return false, false
+			}
+		}
+		in.replaceSelection(nil)
+		return true, false
+	case KeyLeft, KeyRight:
+		switch {
+		case shift && key == KeyLeft:
+			in.Caret = max(in.Caret-1, 0)
+		case shift:
+			in.Caret = min(in.Caret+1, len(text))
+		case start != end && key == KeyLeft:
+			in.Caret = start // Collapse the selection towards the key
+		case start != end:
+			in.Caret = end
+		case key == KeyLeft:
+			in.Caret = max(in.Caret-1, 0)
+		default:
+			in.Caret = min(in.Caret+1, len(text))
+		}
+	case KeyHome:
+		in.Caret = 0
+	case KeyEnd:
+		in.Caret = len(text)
+	case KeyEnter:
+		return false, true
+	default:
+		return false, false
+	}
+	if !shift {
+		in.Anchor = in.Caret
+	}
+	return false, false
+}
+
+// SetCaretFromPoint moves the caret to the character boundary nearest to the
+// window x coordinate x, e.g. where the input was clicked. With extend the
+// selection grows to that point instead.
+func (in *TextInput) SetCaretFromPoint(x float32, textFont FontProvider, fontSize, scale float32, extend bool, now time.Time) {
+	in.lastActivity = now
+	local := x - in.textOriginX(scale) + in.ScrollX
+	text := []rune(in.El.Text)
+	in.Caret = len(text)
+	prevX := float32(0)
+	for i := 1; i <= len(text); i++ {
+		nextX := measureText(textFont, string(text[:i]), fontSize)
+		if local < (prevX+nextX)/2 {
+			in.Caret = i - 1
+			break
+		}
+		prevX = nextX
+	}
+	if !extend {
+		in.Anchor = in.Caret
+	}
+}
+
+// CaretX returns the caret's x offset from the start of the text.
+func (in *TextInput) CaretX(textFont FontProvider, fontSize float32) float32 {
+	in.clamp()
+	return measureText(textFont, string([]rune(in.El.Text)[:in.Caret]), fontSize)
+}
+
+// SelectionX returns the x offsets of the selection's edges from the start of
+// the text.
+func (in *TextInput) SelectionX(textFont FontProvider, fontSize float32) (startX, endX float32) {
+	in.clamp()
+	text := []rune(in.El.Text)
+	start, end := in.Selection()
+	return measureText(textFont, string(text[:start]), fontSize), measureText(textFont, string(text[:end]), fontSize)
+}
+
+// ScrollToCaret adjusts ScrollX so the caret lies within a content box width
+// pixels wide, and no more text than necessary is scrolled out of view.
+func (in *TextInput) ScrollToCaret(textFont FontProvider, fontSize, width float32) {
+	caretX := in.CaretX(textFont, fontSize)
+	if caretX-in.ScrollX > width-1 {
+		in.ScrollX = caretX - width + 1
+	}
+	if caretX < in.ScrollX {
+		in.ScrollX = caretX
+	}
+	textWidth := measureText(textFont, in.El.Text, fontSize)
+	in.ScrollX = float32(math.Max(0, math.Min(float64(in.ScrollX), float64(textWidth-width+1))))
+}
+
+// CaretVisible reports whether the blinking caret is shown at now.
+func (in *TextInput) CaretVisible(now time.Time) bool {
+	return now.Sub(in.lastActivity)/CaretBlinkInterval%2 == 0
+}
+
+// SelectionColor returns the highlight drawn behind selected text: the text
+// color at a third of its opacity.
+func SelectionColor(fg color.RGBA) color.RGBA {
+	fg.A /= 3
+	return fg
+}
+
+func (in *TextInput) replaceSelection(insert []rune) {
+	in.clamp()
+	text := []rune(in.El.Text)
+	start, end := in.Selection()
+	edited := make([]rune, 0, len(text)-(end-start)+len(insert))
+	edited = append(edited, text[:start]...)
+	edited = append(edited, insert...)
+	edited = append(edited, text[end:]...)
+	in.El.Text = string(edited)
+	in.Caret = start + len(insert)
+	in.Anchor = in.Caret
+}
+
+// clamp keeps Caret and Anchor valid if Text was changed by application code.
+func (in *TextInput) clamp() {
+	n := utf8.RuneCountInString(in.El.Text)
+	in.Caret = min(max(in.Caret, 0), n)
+	in.Anchor = min(max(in.Anchor, 0), n)
+}
+
+// textOriginX returns the window x coordinate of the element's content box.
+func (in *TextInput) textOriginX(scale float32) float32 {
+	return in.El.RenderX + float32(math.Round(float64(in.El.BorderWidths[3])*float64(scale))) +
+		float32(math.Round(float64(in.El.Padding[3])*float64(scale)))
+}
+
+func measureText(textFont FontProvider, text string, fontSize float32) float32 {
+	if textFont == nil || text == "" {
+		return 0
+	}
+	return textFont.MeasureText(text, fontSize)
+}
@@ -0,0 +1,112 @@
+package render
+
+import (
+	"testing"
+	"time"
+)
+
+func newTestInput(text string) *TextInput {
+	return NewTextInput(&RenderElement{Text: text}, time.Unix(0, 0))
+}
+
+func TestTextInputEditing(t *testing.T) {
+	now := time.Unix(0, 0)
+	in := newTestInput("hello")
+	if in.Caret != 5 || in.Anchor != 5 {
+		t.Fatalf("new input caret %d anchor %d, want both at the end", in.Caret, in.Anchor)
+	}
+
+	if !in.InsertText(" wörld", now) || in.El.Text != "hello wörld" {
+		t.Errorf("after typing: %q", in.El.Text)
+	}
+	if in.InsertText("\n\t", now) {
+		t.Error("control characters were inserted")
+	}
+
+	in.HandleKey(KeyLeft, true, now)
+	in.HandleKey(KeyLeft, true, now)
+	if got := in.SelectedText(); got != "ld" {
+		t.Errorf("shift+left twice selected %q, want \"ld\"", got)
+	}
+	if changed, _ := in.HandleKey(KeyBackspace, false, now); !changed || in.El.Text != "hello wör" {
+		t.Errorf("backspace over the selection: changed %v, text %q", changed, in.El.Text)
+	}
+
+	in.HandleKey(KeyHome, false, now)
+	in.InsertText(">", now)
+	if in.El.Text != ">hello wör" || in.Caret != 1 {
+		t.Errorf("after Home and typing: %q caret %d", in.El.Text, in.Caret)
+	}
+	if changed, _ := in.HandleKey(KeyDelete, false, now); !changed || in.El.Text != ">ello wör" {
+		t.Errorf("delete: changed %v, text %q", changed, in.El.Text)
+	}
+	in.HandleKey(KeyEnd, false, now)
+	if changed, _ := in.HandleKey(KeyDelete, false, now); changed {
+		t.Error("delete at the end changed the text")
+	}
+	if _, submitted := in.HandleKey(KeyEnter, false, now); !submitted {
+		t.Error("enter did not submit")
+	}
+}
+
+func TestTextInputArrowCollapsesSelection(t *testing.T) {
+	now := time.Unix(0, 0)
+	in := newTestInput("abcdef")
+	in.HandleKey(KeyHome, true, now)
+	in.HandleKey(KeyRight, false, now)
+	if start, end := in.Selection(); start != 6 || end != 6 {
+		t.Errorf("right with a selection put the caret at %d-%d, want the selection end 6", start, end)
+	}
+}
+
+func TestTextInputClampsAfterExternalChange(t *testing.T) {
+	in := newTestInput("hello")
+	in.El.Text = "hi"
+	if got := in.SelectedText(); got != "" || in.Caret != 2 {
+		t.Errorf("after shortening the text: caret %d, selection %q", in.Caret, got)
+	}
+}
+
+func TestTextInputCaretPosition(t *testing.T) {
+	f := NewBundledFontProvider()
+	defer f.Close()
+	now := time.Unix(0, 0)
+	in := newTestInput("hello world")
+	in.El.RenderX = 10
+
+	x := 10 + f.MeasureText("hello", 16) + 1
+	in.SetCaretFromPoint(x, f, 16, 1, false, now)
+	if in.Caret != 5 {
+		t.Errorf("clicking after \"hello\" put the caret at %d, want 5", in.Caret)
+	}
+	in.SetCaretFromPoint(1000, f, 16, 1, true, now)
+	if got := in.SelectedText(); got != " world" {
+		t.Errorf("extending to the end selected %q", got)
+	}
+	if got, want := in.CaretX(f, 16), f.MeasureText("hello world", 16); got != want {
+		t.Errorf("CaretX = %v, want %v", got, want)
+	}
+
+	in.ScrollToCaret(f, 16, 30)
+	if caret := in.CaretX(f, 16) - in.ScrollX; caret < 0 || caret > 30 {
+		t.Errorf("caret is at %v after scrolling, outside the 30 pixel box", caret)
+	}
+	in.HandleKey(KeyHome, false, now)
+	in.ScrollToCaret(f, 16, 30)
+	if in.ScrollX != 0 {
+		t.Errorf("ScrollX = %v with the caret at the start, want 0", in.ScrollX)
+	}
+}
+
+func TestTextInputCaretBlink(t *testing.T) {
+	start := time.Unix(0, 0)
+	in := NewTextInput(&RenderElement{}, start)
+	if !in.CaretVisible(start) || in.CaretVisible(start.Add(CaretBlinkInterval)) || !in.CaretVisible(start.Add(2*CaretBlinkInterval)) {
+		t.Error("caret does not blink with CaretBlinkInterval")
+	}
+	typed := start.Add(CaretBlinkInterval)
+	in.InsertText("a", typed)
+	if !in.CaretVisible(typed) {
+		t.Error("caret is hidden right after typing")
+	}
+}
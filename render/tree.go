@@ -0,0 +1,892 @@
+// render/tree.go
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"image/color"
+	"io"
+	"log"
+	"math"
+	"path/filepath"
+
+	"github.com/kryonlabs/kryon-go-runtime/krb"
+)
+
+// Tree is the backend-independent result of preparing a KRB document for
+// rendering. Backends build one with BuildTree and then lay out and draw Roots.
+type Tree struct {
+	Doc         *krb.Document
+	Elements    []RenderElement // All elements, including those created by component expansion
+	Roots       []*RenderElement
+	Config      WindowConfig // Window configuration resolved from the App element
+	ScaleFactor float32      // Effective UI scale, never below 1.0
+	ResourceDir string       // Absolute directory of the KRB file; external resources are relative to it
+}
+
+// BuildTree turns a parsed KRB document into RenderElements: it resolves the
+// window configuration from the App element, applies styles and direct
+// properties, expands component instances and resolves inherited properties.
+// krbFilePath locates the directory external resources are loaded from.
+func BuildTree(doc *krb.Document, krbFilePath string) (*Tree, error) {
+
+	if doc == nil {
+		log.Println("BuildTree: KRB document is nil.")
+		return nil, fmt.Errorf("BuildTree: KRB document is nil")
+	}
+	t := &Tree{Doc: doc}
+
+	var err error
+	t.ResourceDir, err = filepath.Abs(filepath.Dir(krbFilePath))
+	if err != nil {
+		t.ResourceDir = filepath.Dir(krbFilePath)
+		log.Printf("WARN BuildTree: Failed to get abs path for KRB file dir '%s': %v. Using relative: %s", krbFilePath, err, t.ResourceDir)
+	}
+	log.Printf("BuildTree: Resource Base Directory set to: %s", t.ResourceDir)
+
+	// --- 1. Initialize WindowConfig with application defaults ---
+	windowConfig := DefaultWindowConfig() // Gets struct with hardcoded defaults
+
+	// --- 2. Apply App Element's Style and Direct Properties to WindowConfig ---
+	isAppElementPresent := (doc.Header.Flags&krb.FlagHasApp) != 0 &&
+		doc.Header.ElementCount > 0 &&
+		doc.Elements[0].Type == krb.ElemTypeApp
+
+	if isAppElementPresent {
+		appElementKrbHeader := &doc.Elements[0]
+		// Apply style from App element to windowConfig
+		if appStyle, styleFound := FindStyle(doc, appElementKrbHeader.StyleID); styleFound {
+			t.applyStylePropertiesToWindowConfig(appStyle.Properties, doc, &windowConfig)
+		} else if appElementKrbHeader.StyleID != 0 {
+			log.Printf("Warn BuildTree: App element has StyleID %d, but style was not found.", appElementKrbHeader.StyleID)
+		}
+		// Apply direct properties from App element to windowConfig
+		if len(doc.Properties) > 0 && len(doc.Properties[0]) > 0 {
+			t.applyDirectPropertiesToWindowConfig(doc.Properties[0], doc, &windowConfig)
+		}
+	} else {
+		log.Println("BuildTree: No App element found in KRB. Using default window configuration.")
+	}
+	// Finalize scale factor and store config in the tree
+	t.ScaleFactor = float32(math.Max(1.0, float64(windowConfig.ScaleFactor)))
+	t.Config = windowConfig
+	log.Printf("BuildTree: Final Window Config: W:%d, H:%d, Title:'%s', Scale:%.2f, Resizable:%t, DefBG:%v, DefFG:%v, DefBorder:%v",
+		t.Config.Width, t.Config.Height, t.Config.Title, t.ScaleFactor, t.Config.Resizable, t.Config.DefaultBg, t.Config.DefaultFgColor, t.Config.DefaultBorderColor)
+
+	// --- 3. Process KRB Elements into RenderElements ---
+	initialElementCount := int(doc.Header.ElementCount)
+	if initialElementCount == 0 {
+		log.Println("BuildTree: No elements in KRB document.")
+		return t, nil
+	}
+	t.Elements = make([]RenderElement, initialElementCount, initialElementCount*2)
+
+	// Initial properties that are not typically styled or inherited directly in the first pass
+	defaultTextAlignment := uint8(krb.LayoutAlignStart)
+	defaultIsVisible := true
+
+	for i := 0; i < initialElementCount; i++ {
+		renderEl := &t.Elements[i]
+		krbElHeader := doc.Elements[i]
+
+		// Basic Initialization
+		renderEl.Header = krbElHeader
+		renderEl.OriginalIndex = i
+		renderEl.DocRef = doc
+		renderEl.BgColor = color.RGBA{}     // Default: transparent
+		renderEl.FgColor = color.RGBA{}     // Default: "unset", to be filled by style, direct, or inheritance
+		renderEl.BorderColor = color.RGBA{} // Default: "unset"
+		renderEl.BorderWidths = [4]uint8{0, 0, 0, 0}
+		renderEl.Padding = [4]uint8{0, 0, 0, 0}
+		renderEl.Margin = [4]uint8{0, 0, 0, 0}
+		renderEl.BorderRadius = [4]uint16{0, 0, 0, 0}
+		renderEl.TextAlignment = defaultTextAlignment // Base default, can be overridden
+		renderEl.IsVisible = defaultIsVisible         // Base default, can be overridden
+		renderEl.IsInteractive = (krbElHeader.Type == krb.ElemTypeButton || krbElHeader.Type == krb.ElemTypeInput)
+		renderEl.ResourceIndex = InvalidResourceIndex
+		renderEl.FontResourceIndex = InvalidResourceIndex
+		renderEl.Opacity = 1.0
+		renderEl.Overflow = defaultOverflow(krbElHeader.Type)
+		if i < len(doc.AnimationRefs) {
+			renderEl.AnimationRefs = doc.AnimationRefs[i]
+		}
+
+		// Source Element Name for Debugging
+		elementIDString, _ := StringAt(doc, renderEl.Header.ID)
+		var componentName string
+		if doc.CustomProperties != nil && i < len(doc.CustomProperties) {
+			componentName, _ = GetCustomPropertyValue(renderEl, ComponentNameKey, doc)
+		}
+		if componentName != "" {
+			renderEl.SourceElementName = componentName
+		} else if elementIDString != "" {
+			renderEl.SourceElementName = elementIDString
+		} else {
+			renderEl.SourceElementName = fmt.Sprintf("Type0x%X_Idx%d", renderEl.Header.Type, renderEl.OriginalIndex)
+		}
+
+		// Styling Resolution Order (as per spec section 5)
+		// 5.1. Basic Init (done above)
+		// 5.2. Style Application
+		elementStyle, styleFound := FindStyle(doc, krbElHeader.StyleID)
+		if styleFound {
+			t.applyStylePropertiesToElement(elementStyle.Properties, doc, renderEl)
+		} else if krbElHeader.StyleID != 0 && !(i == 0 && isAppElementPresent) {
+			log.Printf("Warn BuildTree: Element %s (Idx %d) has StyleID %d, but style was not found.",
+				renderEl.SourceElementName, i, krbElHeader.StyleID)
+		}
+
+		// 5.3. Direct Property Application (overrides style)
+		if len(doc.Properties) > i && len(doc.Properties[i]) > 0 {
+			if i == 0 && isAppElementPresent { // App element has some visual props for its RenderElement
+				t.applyDirectVisualPropertiesToAppElement(doc.Properties[0], doc, renderEl)
+			} else {
+				t.applyDirectPropertiesToElement(doc.Properties[i], doc, renderEl)
+			}
+		}
+
+		// Resolve text and image source (might use values from style or direct props)
+		t.resolveElementTextAndImage(doc, renderEl, elementStyle, styleFound)
+		t.resolveElementFont(doc, renderEl)
+		resolveElementTabIndex(doc, renderEl)
+		resolveElementScrollbar(doc, renderEl)
+		resolveElementGrid(doc, renderEl)
+		resolveElementFlex(doc, renderEl)
+
+		// 5.4. Contextual Default Resolution (e.g., borders)
+		t.applyContextualDefaults(renderEl)
+
+		// Event handlers (not styling, but part of element setup)
+		resolveEventHandlers(doc, renderEl) // This can stay here or move to utils
+	}
+
+	// --- 4. Link Original KRB Children & Expand Components ---
+	kryUsageChildrenMap := make(map[int][]*RenderElement)
+	if err_link := t.linkOriginalKrbChildren(initialElementCount, kryUsageChildrenMap); err_link != nil {
+		return nil, fmt.Errorf("BuildTree: failed during initial child linking: %w", err_link)
+	}
+
+	nextMasterIndex := initialElementCount
+	for i := 0; i < initialElementCount; i++ {
+		instanceElement := &t.Elements[i]
+		componentName, _ := GetCustomPropertyValue(instanceElement, ComponentNameKey, doc)
+		if componentName != "" {
+			compDef := t.findComponentDefinition(componentName)
+			if compDef != nil {
+				instanceKryChildren := kryUsageChildrenMap[instanceElement.OriginalIndex]
+				err_expand := t.expandComponent(instanceElement, compDef, &t.Elements, &nextMasterIndex, instanceKryChildren)
+				if err_expand != nil {
+					log.Printf("ERROR BuildTree: Failed to expand component '%s' for instance '%s': %v", componentName, instanceElement.SourceElementName, err_expand)
+				}
+			} else {
+				log.Printf("Warn BuildTree: Component definition for '%s' (instance '%s') not found.", componentName, instanceElement.SourceElementName)
+			}
+		}
+	}
+
+	// Finalize tree structure (Parent pointers and finding roots) *after* expansion
+	t.Roots = nil
+	if err_build := t.finalizeTreeStructureAndRoots(); err_build != nil {
+		return nil, fmt.Errorf("failed to finalize full element tree: %w", err_build)
+	}
+
+	// --- 5. Resolve Property Inheritance ---
+	// This must happen *after* the full tree is linked and components are expanded,
+	// so parent properties are fully resolved before children try to inherit.
+	t.resolvePropertyInheritance()
+
+	// --- Done with Tree Preparation ---
+	log.Printf("BuildTree: Tree built. Roots: %d. Total elements (incl. expanded): %d.", len(t.Roots), len(t.Elements))
+	for rootIdx, rootNode := range t.Roots {
+		logElementTree(rootNode, 0, fmt.Sprintf("Root[%d]", rootIdx))
+	}
+
+	return t, nil
+}
+
+func (t *Tree) linkOriginalKrbChildren(
+	initialElementCount int,
+	kryUsageChildrenMap map[int][]*RenderElement,
+) error {
+
+	if t.Doc == nil || t.Doc.ElementStartOffsets == nil {
+		return fmt.Errorf("linkOriginalKrbChildren: docRef or ElementStartOffsets is nil")
+	}
+
+	// Map KRB element file offsets to their index in the initial t.Elements slice
+	offsetToInitialElementIndex := make(map[uint32]int)
+
+	for i := 0; i < initialElementCount && i < len(t.Doc.ElementStartOffsets); i++ {
+		offsetToInitialElementIndex[t.Doc.ElementStartOffsets[i]] = i
+	}
+
+	for i := 0; i < initialElementCount; i++ {
+		currentEl := &t.Elements[i]
+		originalKrbHeader := &t.Doc.Elements[i] // This is element from doc.Elements
+		componentName, _ := GetCustomPropertyValue(currentEl, ComponentNameKey, t.Doc)
+		isPlaceholder := (componentName != "") // Is this element an instance of a component?
+
+		if originalKrbHeader.ChildCount > 0 {
+			// Ensure ChildRefs exist for this element in the KRB document
+
+			if i >= len(t.Doc.ChildRefs) || t.Doc.ChildRefs[i] == nil {
+				log.Printf(
+					"Warn linkOriginalKrbChildren: Elem %s (OrigIdx %d) has KRB ChildCount %d but no ChildRefs in doc.",
+					currentEl.SourceElementName, i, originalKrbHeader.ChildCount,
+				)
+				continue // Skip if child references are missing
+			}
+
+			krbChildRefs := t.Doc.ChildRefs[i]
+			actualChildren := make([]*RenderElement, 0, len(krbChildRefs))
+
+			parentStartOffset := uint32(0)
+
+			if i < len(t.Doc.ElementStartOffsets) {
+				parentStartOffset = t.Doc.ElementStartOffsets[i]
+			} else {
+				log.Printf(
+					"Error linkOriginalKrbChildren: Elem %s (OrigIdx %d) missing from ElementStartOffsets.",
+					currentEl.SourceElementName, i,
+				)
+				continue // Should not happen if initialElementCount is consistent
+			}
+
+			for _, childRef := range krbChildRefs {
+				// ChildOffset in KRB is relative to the parent element's start in the file
+				childAbsoluteFileOffset := parentStartOffset + uint32(childRef.ChildOffset)
+				childIndexInInitialElements, found := offsetToInitialElementIndex[childAbsoluteFileOffset]
+
+				if !found {
+					log.Printf(
+						"Error linkOriginalKrbChildren: Elem %s (OrigIdx %d) ChildRef offset %d (abs %d) does not map to known initial element.",
+						currentEl.SourceElementName, i, childRef.ChildOffset, childAbsoluteFileOffset,
+					)
+					continue
+				}
+				childEl := &t.Elements[childIndexInInitialElements]
+				actualChildren = append(actualChildren, childEl)
+			}
+
+			if isPlaceholder {
+				// For component instances, store these children temporarily. They will be slotted later.
+				kryUsageChildrenMap[i] = actualChildren
+			} else {
+				// For regular elements, directly link children and set parent pointers
+				currentEl.Children = actualChildren
+				for _, child := range actualChildren {
+					child.Parent = currentEl
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (t *Tree) finalizeTreeStructureAndRoots() error {
+
+	if len(t.Elements) == 0 {
+		t.Roots = nil
+		return nil
+	}
+	t.Roots = nil // Clear any existing roots
+
+	for i := range t.Elements {
+		el := &t.Elements[i] // Get pointer to the element
+
+		if el.Parent == nil {
+			t.Roots = append(t.Roots, el)
+		}
+	}
+
+	if len(t.Roots) == 0 && len(t.Elements) > 0 {
+		log.Printf(
+			"Warn finalizeTreeStructureAndRoots: No root elements identified, but %d elements exist. This might indicate a problem in parent linking or an unusual KRB structure.",
+			len(t.Elements),
+		)
+	}
+	return nil
+}
+
+// ElementByID returns the first element, in document order, whose KRY id is
+// id, or nil.
+func (t *Tree) ElementByID(id string) *RenderElement {
+	for i := range t.Elements {
+		if t.Elements[i].Header.ID != 0 && ElementID(&t.Elements[i]) == id {
+			return &t.Elements[i]
+		}
+	}
+	return nil
+}
+
+func (t *Tree) findComponentDefinition(name string) *krb.KrbComponentDefinition {
+
+	if t.Doc == nil || len(t.Doc.ComponentDefinitions) == 0 || len(t.Doc.Strings) == 0 {
+		return nil
+	}
+
+	for i := range t.Doc.ComponentDefinitions {
+		compDef := &t.Doc.ComponentDefinitions[i]
+
+		if int(compDef.NameIndex) < len(t.Doc.Strings) && t.Doc.Strings[compDef.NameIndex] == name {
+			return compDef
+		}
+	}
+	return nil
+}
+
+func (t *Tree) expandComponent(
+	instanceElement *RenderElement, // The placeholder element being replaced
+	compDef *krb.KrbComponentDefinition, // The definition of the component to expand
+	allElements *[]RenderElement, // Pointer to the global slice of all elements
+	nextMasterIndex *int, // Pointer to the next available global index for new elements
+	kryUsageChildren []*RenderElement, // Children passed to the component instance in KRY
+) error {
+	doc := t.Doc
+	compDefNameStr := stringAtOrDefault(doc, compDef.NameIndex, "UnnamedComponentDef")
+
+	log.Printf("Debug expandComponent: Expanding instance '%s' (OrigIdx %d, NameIdx %d) with definition '%s'. Initial nextMasterIndex: %d",
+		instanceElement.SourceElementName, instanceElement.OriginalIndex, instanceElement.Header.ID, compDefNameStr, *nextMasterIndex)
+
+	if compDef.RootElementTemplateData == nil || len(compDef.RootElementTemplateData) == 0 {
+		log.Printf("Warn expandComponent: CompDef '%s' for instance '%s' has no RootElementTemplateData.", compDefNameStr, instanceElement.SourceElementName)
+		// Slot KRY children directly to instance if no template and instance is a container
+		if len(kryUsageChildren) > 0 && instanceElement.Header.Type == krb.ElemTypeContainer {
+			log.Printf("Debug expandComponent: No template for '%s', appending %d KRY-usage children directly to instance.", instanceElement.SourceElementName, len(kryUsageChildren))
+			if instanceElement.Children == nil {
+				instanceElement.Children = make([]*RenderElement, 0, len(kryUsageChildren))
+			}
+			instanceElement.Children = append(instanceElement.Children, kryUsageChildren...)
+			for _, kryChild := range kryUsageChildren {
+				kryChild.Parent = instanceElement
+			}
+		}
+		return nil
+	}
+
+	templateReader := bytes.NewReader(compDef.RootElementTemplateData)
+
+	// Stores elements created *from this specific template expansion pass*.
+	// Key: offset within template data stream, Value: global index in allElements
+	localTemplateOffsetToGlobalIndex := make(map[uint32]int)
+
+	// Stores child linking information for elements *within this template*.
+	// parentGlobalIndex refers to an element created in this pass from this template.
+	var localTemplateChildInfos []struct {
+		parentGlobalIndex            int
+		childRefs                    []krb.ChildRef
+		parentHeaderOffsetInTemplate uint32 // Offset of parent's header in template data stream
+	}
+
+	var currentTemplateRootGlobalIndex = -1 // Global index of the root element of THIS template expansion
+	templateDataStreamOffset := uint32(0)
+	elementsCreatedInThisExpansionPass := 0
+
+	// --- PASS 1: Create RenderElements from this template's data. Handle nested expansions. ---
+	for templateReader.Len() > 0 {
+		currentElementHeaderOffsetInTemplate := templateDataStreamOffset
+		headerBuf := make([]byte, krb.ElementHeaderSize)
+		n, err := templateReader.Read(headerBuf)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("expandComponent '%s' for instance '%s': failed to read template element header: %w (read %d bytes)", compDefNameStr, instanceElement.SourceElementName, err, n)
+		}
+		if n < krb.ElementHeaderSize {
+			return fmt.Errorf("expandComponent '%s' for instance '%s': template element header is truncated (read %d of %d bytes)", compDefNameStr, instanceElement.SourceElementName, n, krb.ElementHeaderSize)
+		}
+		templateDataStreamOffset += uint32(n)
+		elementsCreatedInThisExpansionPass++
+
+		templateKrbHeader := krb.ElementHeader{
+			Type:            krb.ElementType(headerBuf[0]),
+			ID:              headerBuf[1],
+			PosX:            krb.ReadU16LE(headerBuf[2:4]),
+			PosY:            krb.ReadU16LE(headerBuf[4:6]),
+			Width:           krb.ReadU16LE(headerBuf[6:8]),
+			Height:          krb.ReadU16LE(headerBuf[8:10]),
+			Layout:          headerBuf[10],
+			StyleID:         headerBuf[11],
+			PropertyCount:   headerBuf[12],
+			ChildCount:      headerBuf[13],
+			EventCount:      headerBuf[14],
+			AnimationCount:  headerBuf[15],
+			CustomPropCount: headerBuf[16],
+		}
+
+		newElGlobalIndex := *nextMasterIndex
+		(*nextMasterIndex)++
+
+		// Grow allElements slice if needed
+		if newElGlobalIndex >= cap(*allElements) {
+			newCap := cap(*allElements)*2 + 20 // Or other growth strategy
+			tempSlice := make([]RenderElement, len(*allElements), newCap)
+			copy(tempSlice, *allElements)
+			*allElements = tempSlice
+		}
+		// Ensure slice has enough length
+		if newElGlobalIndex >= len(*allElements) {
+			*allElements = (*allElements)[:newElGlobalIndex+1]
+		}
+
+		newEl := &(*allElements)[newElGlobalIndex] // This is the RenderElement created from the template
+		newEl.OriginalIndex = newElGlobalIndex
+		newEl.Header = templateKrbHeader
+		newEl.DocRef = doc
+		newEl.BgColor = color.RGBA{}
+		newEl.FgColor = color.RGBA{}
+		newEl.BorderColor = color.RGBA{}
+		newEl.BorderWidths = [4]uint8{}
+		newEl.Padding = [4]uint8{}
+		newEl.Margin = [4]uint8{}
+		newEl.BorderRadius = [4]uint16{}
+		newEl.TextAlignment = UnsetTextAlignmentSentinel // Use sentinel for inheritance check
+		newEl.IsVisible = true
+		newEl.ResourceIndex = InvalidResourceIndex
+		newEl.FontResourceIndex = InvalidResourceIndex
+		newEl.Opacity = 1.0
+		newEl.IsInteractive = (templateKrbHeader.Type == krb.ElemTypeButton || templateKrbHeader.Type == krb.ElemTypeInput)
+		newEl.Focusable = newEl.IsInteractive
+		newEl.Overflow = defaultOverflow(templateKrbHeader.Type)
+		resolveElementScrollbar(nil, newEl) // Defaults only: OriginalIndex does not index the document here
+		resolveElementGrid(nil, newEl)
+		resolveElementFlex(nil, newEl)
+
+		localTemplateOffsetToGlobalIndex[currentElementHeaderOffsetInTemplate] = newElGlobalIndex
+
+		templateElIdStr, _ := StringAt(doc, templateKrbHeader.ID)
+		newEl.SourceElementName = templateElIdStr
+		if newEl.SourceElementName == "" {
+			newEl.SourceElementName = fmt.Sprintf("TplElem_Type0x%X_Idx%d_In_%s", templateKrbHeader.Type, newEl.OriginalIndex, compDefNameStr)
+		}
+
+		var templateDirectProps []krb.Property
+		if templateKrbHeader.PropertyCount > 0 {
+			templateDirectProps = make([]krb.Property, templateKrbHeader.PropertyCount)
+			propHeaderBuf := make([]byte, 3) // ID(1), ValueType(1), Size(1)
+			for j := uint8(0); j < templateKrbHeader.PropertyCount; j++ {
+				nProp, errProp := templateReader.Read(propHeaderBuf)
+				if errProp != nil || nProp < 3 {
+					return fmt.Errorf("expandComp '%s': read prop header for '%s': %w", compDefNameStr, newEl.SourceElementName, errProp)
+				}
+				templateDataStreamOffset += uint32(nProp)
+				prop := &templateDirectProps[j]
+				prop.ID = krb.PropertyID(propHeaderBuf[0])
+				prop.ValueType = krb.ValueType(propHeaderBuf[1])
+				prop.Size = propHeaderBuf[2]
+				if prop.Size > 0 {
+					prop.Value = make([]byte, prop.Size)
+					nVal, errVal := templateReader.Read(prop.Value)
+					if errVal != nil || nVal < int(prop.Size) {
+						return fmt.Errorf("expandComp '%s': read prop value for '%s': %w", compDefNameStr, newEl.SourceElementName, errVal)
+					}
+					templateDataStreamOffset += uint32(nVal)
+				}
+			}
+		}
+
+		var nestedComponentNameForThisNewEl string
+		fontResourceForThisNewEl := uint8(InvalidResourceIndex)
+		if templateKrbHeader.CustomPropCount > 0 {
+			customPropHeaderBuf := make([]byte, 3) // KeyIndex(1), ValueType(1), Size(1)
+			for j := uint8(0); j < templateKrbHeader.CustomPropCount; j++ {
+				nCustomProp, errCustomProp := templateReader.Read(customPropHeaderBuf)
+				if errCustomProp != nil || nCustomProp < 3 {
+					return fmt.Errorf("expandComp '%s': read custom prop header for '%s': %w", compDefNameStr, newEl.SourceElementName, errCustomProp)
+				}
+				templateDataStreamOffset += uint32(nCustomProp)
+
+				cpropKeyIndex := customPropHeaderBuf[0]
+				cpropValueType := krb.ValueType(customPropHeaderBuf[1])
+				cpropSize := customPropHeaderBuf[2]
+				var cpropValue []byte
+				if cpropSize > 0 {
+					cpropValue = make([]byte, cpropSize)
+					nVal, errVal := templateReader.Read(cpropValue)
+					if errVal != nil || nVal < int(cpropSize) {
+						return fmt.Errorf("expandComp '%s': read custom prop value for '%s': %w", compDefNameStr, newEl.SourceElementName, errVal)
+					}
+					templateDataStreamOffset += uint32(nVal)
+				}
+
+				keyName, keyOk := StringAt(doc, cpropKeyIndex)
+				if keyOk && keyName == FontFamilyKey && cpropSize == 1 && len(cpropValue) == 1 {
+					if family, strOk := StringAt(doc, cpropValue[0]); strOk {
+						if resIdx, found := FindFontResource(doc, family); found {
+							fontResourceForThisNewEl = resIdx
+						}
+					}
+				}
+				if keyOk && keyName == ComponentNameKey {
+					if (cpropValueType == krb.ValTypeString || cpropValueType == krb.ValTypeResource) && cpropSize == 1 && len(cpropValue) == 1 {
+						valueIndex := cpropValue[0]
+						if strVal, strOk := StringAt(doc, valueIndex); strOk {
+							nestedComponentNameForThisNewEl = strVal
+						}
+					}
+				}
+			}
+		}
+
+		// Apply styling and properties based on whether it's template root or child
+		if currentTemplateRootGlobalIndex == -1 { // This is the first element from template data stream
+			currentTemplateRootGlobalIndex = newElGlobalIndex
+			newEl.Parent = instanceElement // Its parent is the instance element being expanded
+			log.Printf("Debug expandComponent [%s for %s]: Template root '%s' (GlobalIdx %d) created. Parent set to instance '%s' (GlobalIdx %d).",
+				compDefNameStr, instanceElement.SourceElementName, newEl.SourceElementName, newEl.OriginalIndex, instanceElement.SourceElementName, instanceElement.OriginalIndex)
+
+			// Apply instance's (placeholder) properties TO this template root
+			newEl.Header.ID = instanceElement.Header.ID // ID from <Comp id="X">
+			newEl.Header.PosX = instanceElement.Header.PosX
+			newEl.Header.PosY = instanceElement.Header.PosY
+			newEl.Header.Width = instanceElement.Header.Width   // from <Comp width=X>
+			newEl.Header.Height = instanceElement.Header.Height // from <Comp height=Y>
+			newEl.Header.Layout = instanceElement.Header.Layout // from <Comp layout=...> or its style
+
+			if instanceElement.SourceElementName != "" { // Instance's name (often from its ID) takes precedence
+				newEl.SourceElementName = instanceElement.SourceElementName
+			}
+
+			// StyleID for template root: Precedence is instance's style, then template's default.
+			resolvedStyleIDForTplRoot := instanceElement.Header.StyleID // Style from <Comp style="X">
+			if resolvedStyleIDForTplRoot == 0 && templateKrbHeader.StyleID != 0 {
+				resolvedStyleIDForTplRoot = templateKrbHeader.StyleID // Style from Define Comp { RootElement { style: "Y" } }
+			}
+			newEl.Header.StyleID = resolvedStyleIDForTplRoot
+
+			// Apply the resolved style to newEl (the template root)
+			if style, found := FindStyle(doc, newEl.Header.StyleID); found {
+				t.applyStylePropertiesToElement(style.Properties, doc, newEl)
+			}
+			// Apply direct KRB properties from instanceElement's original KRB entry
+			// These are standard KRB properties, not "Custom Properties" for component logic.
+			if instanceElement.OriginalIndex >= 0 && instanceElement.OriginalIndex < len(doc.Properties) && len(doc.Properties[instanceElement.OriginalIndex]) > 0 {
+				t.applyDirectPropertiesToElement(doc.Properties[instanceElement.OriginalIndex], doc, newEl)
+			}
+		} else { // This `newEl` is a non-root element *within* the template structure
+			// Apply style from template's own definition (templateKrbHeader.StyleID)
+			if templateChildStyle, templateChildStyleFound := FindStyle(doc, templateKrbHeader.StyleID); templateChildStyleFound {
+				t.applyStylePropertiesToElement(templateChildStyle.Properties, doc, newEl)
+			}
+			// Apply direct properties defined on this element *within the template itself*
+			t.applyDirectPropertiesToElement(templateDirectProps, doc, newEl)
+		}
+		if fontResourceForThisNewEl != InvalidResourceIndex { // fontFamily custom property overrides style and direct props
+			newEl.FontResourceIndex = fontResourceForThisNewEl
+		}
+
+		// Common post-style/direct-prop steps
+		t.applyContextualDefaults(newEl)
+		styleForNewEl, styleFoundForNewEl := FindStyle(doc, newEl.Header.StyleID)
+		t.resolveElementTextAndImage(doc, newEl, styleForNewEl, styleFoundForNewEl)
+
+		// Event Handlers defined IN THE TEMPLATE (e.g. a static button inside a component)
+		if templateKrbHeader.EventCount > 0 {
+			eventDataSize := int(templateKrbHeader.EventCount) * krb.EventFileEntrySize
+			eventBuf := make([]byte, eventDataSize)
+			nEvent, errEvent := templateReader.Read(eventBuf)
+			if errEvent != nil || nEvent < eventDataSize {
+				return fmt.Errorf("expandComp '%s': read template event block for '%s': %w", compDefNameStr, newEl.SourceElementName, errEvent)
+			}
+			templateDataStreamOffset += uint32(nEvent)
+			newEl.EventHandlers = make([]EventCallbackInfo, templateKrbHeader.EventCount)
+			for k := uint8(0); k < templateKrbHeader.EventCount; k++ {
+				offset := int(k) * krb.EventFileEntrySize
+				eventType := krb.EventType(eventBuf[offset])
+				callbackID := eventBuf[offset+1]
+				if handlerName, ok := StringAt(doc, callbackID); ok {
+					newEl.EventHandlers[k] = EventCallbackInfo{EventType: eventType, HandlerName: handlerName}
+				} else {
+					log.Printf("Warn expandComponent: Template element '%s' (GlobalIdx %d) has invalid event callback string index %d.", newEl.SourceElementName, newEl.OriginalIndex, callbackID)
+				}
+			}
+		}
+
+		// Animation Refs defined IN THE TEMPLATE
+		if templateKrbHeader.AnimationCount > 0 {
+			animRefDataSize := int(templateKrbHeader.AnimationCount) * krb.AnimationRefSize
+			animRefBuf := make([]byte, animRefDataSize)
+			nAnim, errAnim := io.ReadFull(templateReader, animRefBuf)
+			if errAnim != nil || nAnim < animRefDataSize {
+				return fmt.Errorf("expandComp '%s': read template anim refs for '%s': %w", compDefNameStr, newEl.SourceElementName, errAnim)
+			}
+			templateDataStreamOffset += uint32(nAnim)
+			newEl.AnimationRefs = make([]krb.AnimationRef, templateKrbHeader.AnimationCount)
+			for k := range newEl.AnimationRefs {
+				offset := k * krb.AnimationRefSize
+				newEl.AnimationRefs[k] = krb.AnimationRef{
+					AnimationIndex: animRefBuf[offset],
+					Trigger:        krb.AnimationTrigger(animRefBuf[offset+1]),
+				}
+			}
+		}
+
+		// Read and store Child Ref info for Pass 2 (linking children within this template)
+		if templateKrbHeader.ChildCount > 0 {
+			tplChildRefs := make([]krb.ChildRef, templateKrbHeader.ChildCount)
+			childRefDataSize := int(templateKrbHeader.ChildCount) * krb.ChildRefSize
+			childRefBuf := make([]byte, childRefDataSize)
+			nChildRef, errChildRef := templateReader.Read(childRefBuf)
+			if errChildRef != nil || nChildRef < childRefDataSize {
+				return fmt.Errorf("expandComp '%s': read template child refs for '%s': %w", compDefNameStr, newEl.SourceElementName, errChildRef)
+			}
+			templateDataStreamOffset += uint32(nChildRef)
+			for k := uint8(0); k < templateKrbHeader.ChildCount; k++ {
+				offset := int(k) * krb.ChildRefSize
+				tplChildRefs[k] = krb.ChildRef{ChildOffset: krb.ReadU16LE(childRefBuf[offset : offset+krb.ChildRefSize])}
+			}
+			localTemplateChildInfos = append(localTemplateChildInfos, struct {
+				parentGlobalIndex            int
+				childRefs                    []krb.ChildRef
+				parentHeaderOffsetInTemplate uint32
+			}{
+				parentGlobalIndex:            newElGlobalIndex,
+				childRefs:                    tplChildRefs,
+				parentHeaderOffsetInTemplate: currentElementHeaderOffsetInTemplate,
+			})
+		}
+
+		// Recursive expansion for nested components defined within this template
+		if nestedComponentNameForThisNewEl != "" {
+			nestedCompDef := t.findComponentDefinition(nestedComponentNameForThisNewEl)
+			if nestedCompDef != nil {
+				log.Printf("Debug expandComponent: Recursively expanding NESTED component '%s' (placeholder is '%s', GlobalIdx %d) within outer component '%s'",
+					nestedComponentNameForThisNewEl, newEl.SourceElementName, newEl.OriginalIndex, compDefNameStr)
+
+				newEl.IsExpandedAsNestedComponent = true
+				// `newEl` is the placeholder *within the current component's template* for the nested component.
+				// KRY-usage children for a component defined *inside another component's template* are typically nil,
+				// unless the KRY `Define` syntax allows passing children to such nested template components (advanced feature).
+				err_nested := t.expandComponent(newEl, nestedCompDef, allElements, nextMasterIndex, nil /* No KRY-usage children for this nested instance */)
+				if err_nested != nil {
+					return fmt.Errorf("expandComponent '%s': failed during nested expansion of '%s' (for '%s'): %w", compDefNameStr, nestedComponentNameForThisNewEl, newEl.SourceElementName, err_nested)
+				}
+			} else {
+				log.Printf("Warn expandComponent: Nested CompDef '%s' not found (for placeholder '%s', GlobalIdx %d in '%s').",
+					nestedComponentNameForThisNewEl, newEl.SourceElementName, newEl.OriginalIndex, compDefNameStr)
+			}
+		}
+	} // End PASS 1 (creating RenderElements from this template's data stream)
+
+	log.Printf("Debug expandComponent [%s for %s]: Pass 1 created %d elements from template. nextMasterIndex is now: %d",
+		compDefNameStr, instanceElement.SourceElementName, elementsCreatedInThisExpansionPass, *nextMasterIndex)
+
+	// --- PASS 2: Link children *within* the structure just created from THIS template. ---
+	// This loop iterates over the `localTemplateChildInfos` which were collected for elements defined *in this current template*.
+	for _, info := range localTemplateChildInfos {
+		// `parentElFromThisTemplate` is an element that was created from `compDef.RootElementTemplateData` in Pass 1.
+		parentElFromThisTemplate := &(*allElements)[info.parentGlobalIndex]
+
+		// If `parentElFromThisTemplate` was itself a placeholder for a *nested component* (e.g., `HabitTabBar` used inside `HabitHeader`),
+		// its `Children` array would have been populated by the recursive call to `expandComponent` for that nested component.
+		// In that case, we should NOT overwrite its children here with `childRefs` from its usage site *within the outer template*.
+		// The `childRefs` for `parentElFromThisTemplate` (if it were a simple container in the template) define ITS children,
+		// not children to be passed INTO it if it's a component instance.
+		if parentElFromThisTemplate.IsExpandedAsNestedComponent { // Need a flag like this
+			log.Printf("Debug expandComponent [%s for %s]: Template element '%s' (GlobalIdx %d) was expanded as a nested component. Skipping its direct child ref linking from this pass, its children are from its own template.",
+				compDefNameStr, instanceElement.SourceElementName, parentElFromThisTemplate.SourceElementName, parentElFromThisTemplate.OriginalIndex)
+			continue
+		}
+		// If newEl.IsExpandedAsNestedComponent is not available, use a heuristic:
+		// if parentElFromThisTemplate.Children is already non-nil and non-empty,
+		// and those children correctly point back to it, assume it was handled by a nested expansion.
+		if parentElFromThisTemplate.Children != nil && len(parentElFromThisTemplate.Children) > 0 {
+			isLikelyNestedExpansion := true
+			for _, child := range parentElFromThisTemplate.Children {
+				if child.Parent != parentElFromThisTemplate {
+					isLikelyNestedExpansion = false
+					break
+				}
+			}
+			if isLikelyNestedExpansion {
+				log.Printf("Debug expandComponent [%s for %s]: Template element '%s' (GlobalIdx %d) children already set (heuristic: likely nested expansion). Skipping its direct child ref linking from this pass.",
+					compDefNameStr, instanceElement.SourceElementName, parentElFromThisTemplate.SourceElementName, parentElFromThisTemplate.OriginalIndex)
+				continue
+			}
+		}
+
+		// Initialize/clear children for `parentElFromThisTemplate` before linking its template-defined children.
+		parentElFromThisTemplate.Children = make([]*RenderElement, 0, len(info.childRefs))
+
+		for _, childRef := range info.childRefs {
+			// `childRef.ChildOffset` is relative to `parentHeaderOffsetInTemplate` within the template data stream.
+			childAbsoluteOffsetInTemplateStream := info.parentHeaderOffsetInTemplate + uint32(childRef.ChildOffset)
+			childGlobalIndex, found := localTemplateOffsetToGlobalIndex[childAbsoluteOffsetInTemplateStream]
+			if !found {
+				log.Printf("Error expandComponent '%s': Child for template element '%s' (GlobalIdx %d) at template offset %d (abs %d) not found in local map.",
+					compDefNameStr, parentElFromThisTemplate.SourceElementName, parentElFromThisTemplate.OriginalIndex, childRef.ChildOffset, childAbsoluteOffsetInTemplateStream)
+				continue
+			}
+			childElFromThisTemplate := &(*allElements)[childGlobalIndex]
+
+			// Link this child (from the template) to its parent (also from the template)
+			childElFromThisTemplate.Parent = parentElFromThisTemplate
+			parentElFromThisTemplate.Children = append(parentElFromThisTemplate.Children, childElFromThisTemplate)
+		}
+		log.Printf("Debug expandComponent [%s for %s]: Pass 2 linked %d children to template element '%s' (GlobalIdx %d).",
+			compDefNameStr, instanceElement.SourceElementName, len(parentElFromThisTemplate.Children), parentElFromThisTemplate.SourceElementName, parentElFromThisTemplate.OriginalIndex)
+	}
+
+	// --- Finalize `instanceElement` (the original placeholder) ---
+	// The `instanceElement` is "replaced" by the root of the expanded template.
+	// Its `Children` should now point to the root element(s) created from this template expansion.
+	if instanceElement != nil {
+		if currentTemplateRootGlobalIndex != -1 { // Check if a template root was actually identified/created
+			rootOfExpandedTemplate := &(*allElements)[currentTemplateRootGlobalIndex]
+
+			// In Pass 1, rootOfExpandedTemplate.Parent was set to instanceElement.
+			// Now, set instanceElement.Children to be this single root.
+			instanceElement.Children = []*RenderElement{rootOfExpandedTemplate}
+			log.Printf("Debug expandComponent [%s for %s]: Instance '%s' (GlobalIdx %d) children array now points to template root '%s' (GlobalIdx %d).",
+				compDefNameStr, instanceElement.SourceElementName, instanceElement.SourceElementName, instanceElement.OriginalIndex, rootOfExpandedTemplate.SourceElementName, rootOfExpandedTemplate.OriginalIndex)
+		} else {
+			// This case (no template root from a non-empty template data) should be rare if parsing is correct.
+			// If KRY-usage children were directly added earlier due to empty template, instanceElement.Children might already be set.
+			log.Printf("Debug expandComponent [%s for %s]: No template root was identified for instance '%s'. Its children might be from KRY-usage or nil.",
+				compDefNameStr, instanceElement.SourceElementName, instanceElement.SourceElementName)
+			if instanceElement.Children == nil { // Ensure it's at least an empty slice
+				instanceElement.Children = make([]*RenderElement, 0)
+			}
+		}
+	}
+
+	// --- Slot KRY-usage children into the expanded template structure ---
+	if len(kryUsageChildren) > 0 {
+		slotFound := false
+		var slotElement *RenderElement
+
+		// Search for the slot within the structure rooted at `instanceElement.Children[0]` (which is the template's root)
+		if instanceElement != nil && len(instanceElement.Children) > 0 {
+			searchStartNode := instanceElement.Children[0] // This is the root of the expanded template
+			queue := []*RenderElement{searchStartNode}
+			visitedInSearch := make(map[*RenderElement]bool)
+
+			for len(queue) > 0 {
+				currentNodeToSearch := queue[0]
+				queue = queue[1:]
+				if visitedInSearch[currentNodeToSearch] {
+					continue
+				}
+				visitedInSearch[currentNodeToSearch] = true
+
+				idNameFromTemplate, _ := StringAt(doc, currentNodeToSearch.Header.ID) // ID is from template element
+				if idNameFromTemplate == ChildrenSlotID {                             // ChildrenSlotID is "children_host"
+					slotElement = currentNodeToSearch
+					slotFound = true
+					break
+				}
+				if currentNodeToSearch.Children != nil {
+					for _, childOfSearchNode := range currentNodeToSearch.Children {
+						if !visitedInSearch[childOfSearchNode] {
+							queue = append(queue, childOfSearchNode)
+						}
+					}
+				}
+			}
+		}
+
+		if slotFound && slotElement != nil {
+			log.Printf("Debug expandComponent [%s for %s]: Found slot '%s' (GlobalIdx %d) in expanded template. Attaching %d KRY-usage children.",
+				compDefNameStr, instanceElement.SourceElementName, ChildrenSlotID, slotElement.OriginalIndex, len(kryUsageChildren))
+			if slotElement.Children == nil {
+				slotElement.Children = make([]*RenderElement, 0, len(kryUsageChildren))
+			}
+			slotElement.Children = append(slotElement.Children, kryUsageChildren...)
+			for _, kryChild := range kryUsageChildren {
+				kryChild.Parent = slotElement // Re-parent KRY children to the slot
+			}
+		} else {
+			log.Printf("Warn expandComponent [%s for %s]: No slot '%s' found in expanded template. Attempting to append %d KRY-usage children to first template root (if any and is container).",
+				compDefNameStr, instanceElement.SourceElementName, ChildrenSlotID, len(kryUsageChildren))
+			if instanceElement != nil && len(instanceElement.Children) > 0 {
+				firstRootInTemplate := instanceElement.Children[0]
+				// Only append if the template root is a type that can host children (e.g., Container)
+				if firstRootInTemplate.Header.Type == krb.ElemTypeContainer { // Or other valid container types
+					if firstRootInTemplate.Children == nil {
+						firstRootInTemplate.Children = make([]*RenderElement, 0, len(kryUsageChildren))
+					}
+					firstRootInTemplate.Children = append(firstRootInTemplate.Children, kryUsageChildren...)
+					for _, kryChild := range kryUsageChildren {
+						kryChild.Parent = firstRootInTemplate
+					}
+				} else {
+					log.Printf("Error expandComponent [%s for %s]: First template root '%s' (Type %X) is not a Container. Cannot append KRY-usage children when slot is missing.",
+						compDefNameStr, instanceElement.SourceElementName, firstRootInTemplate.SourceElementName, firstRootInTemplate.Header.Type)
+				}
+			} else {
+				log.Printf("Error expandComponent [%s for %s]: No template root to append KRY-usage children to (and no slot '%s' found). KRY children remain unparented from this instance.",
+					instanceElement.SourceElementName, compDefNameStr, ChildrenSlotID)
+			}
+		}
+	}
+
+	log.Printf("Debug expandComponent: Finished expanding '%s' for instance '%s' (GlobalIdx %d). Final nextMasterIndex: %d",
+		compDefNameStr, instanceElement.SourceElementName, instanceElement.OriginalIndex, *nextMasterIndex)
+	return nil
+}
+
+func stringAtOrDefault(doc *krb.Document, idx uint8, fallback string) string {
+	s, ok := StringAt(doc, idx)
+
+	if !ok {
+		return fallback
+	}
+	return s
+}
+
+func resolveEventHandlers(doc *krb.Document, el *RenderElement) {
+	el.EventHandlers = nil // Clear/initialize
+
+	if doc != nil && doc.Events != nil &&
+		el.OriginalIndex < len(doc.Events) && doc.Events[el.OriginalIndex] != nil {
+		krbEvents := doc.Events[el.OriginalIndex]
+
+		if len(krbEvents) > 0 {
+			el.EventHandlers = make([]EventCallbackInfo, 0, len(krbEvents))
+
+			for _, krbEvent := range krbEvents {
+
+				if handlerName, ok := StringAt(doc, krbEvent.CallbackID); ok {
+					el.EventHandlers = append(el.EventHandlers, EventCallbackInfo{
+						EventType:   krbEvent.EventType,
+						HandlerName: handlerName,
+					})
+				} else {
+					log.Printf(
+						"Warn resolveEventHandlers: Elem %s (OrigIdx %d) invalid event callback string index %d.",
+						el.SourceElementName, el.OriginalIndex, krbEvent.CallbackID,
+					)
+				}
+			}
+		}
+	}
+}
+
+func logElementTree(el *RenderElement, depth int, prefix string) {
+
+	if el == nil {
+		return
+	}
+	indent := make([]byte, depth*2)
+
+	for i := range indent {
+		indent[i] = ' '
+	}
+
+	parentId := -1
+
+	if el.Parent != nil {
+		parentId = el.Parent.OriginalIndex
+	}
+
+	log.Printf(
+		"%s%s ElemDX_Global[%d] Name='%s' Type=0x%02X Children:%d ParentDX_Global:%d Render=(%.0f,%.0f %.0fwX%.0fh) Vis:%t StyleID:%d Layout:0x%02X",
+		string(indent), prefix, el.OriginalIndex, el.SourceElementName, el.Header.Type, len(el.Children), parentId,
+		el.RenderX, el.RenderY, el.RenderW, el.RenderH, el.IsVisible, el.Header.StyleID, el.Header.Layout,
+	)
+
+	for i, child := range el.Children {
+		logElementTree(child, depth+1, fmt.Sprintf("Child[%d]", i))
+	}
+}